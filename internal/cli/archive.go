@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Move a stopped or failed session into the history",
+	Long: `Archive a stopped or failed session.
+
+Archived sessions are left out of 'sandctl list' and provider syncs, but their
+records are kept. Use 'sandctl list --archived' to see them, or
+'sandctl inspect' to view one.
+
+A session is only archived once the provider confirms its VM no longer
+exists, so a stopped VM that still costs money isn't hidden.`,
+	Example: `  # Archive a destroyed session
+  sandctl archive alice
+
+  # Show archived sessions
+  sandctl list --archived`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runArchive,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	// Normalize the session name (case-insensitive)
+	sessionName := session.NormalizeName(args[0])
+
+	// Validate session name format
+	if !session.ValidateID(sessionName) {
+		return fmt.Errorf("invalid session name format: %s", args[0])
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	sess, err := store.Get(sessionName)
+	if err != nil {
+		var notFound *session.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.PrintError(os.Stderr, "session '%s' not found", sessionName)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, "Run 'sandctl list --all' to see available sessions.")
+			return nil
+		}
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	if sess.IsArchived() {
+		fmt.Printf("Session '%s' is already archived.\n", sessionName)
+		return nil
+	}
+	if !sess.Status.IsTerminal() {
+		return fmt.Errorf("session '%s' is %s; only stopped or failed sessions can be archived", sessionName, sess.Status)
+	}
+
+	if !sess.IsLegacySession() {
+		gone, err := sessionVMGone(sess)
+		if err != nil {
+			return err
+		}
+		if !gone {
+			return fmt.Errorf("session '%s' still has a VM; run 'sandctl destroy %s' first", sessionName, sessionName)
+		}
+	}
+
+	if err := store.Archive(sessionName); err != nil {
+		return fmt.Errorf("failed to archive session: %w", err)
+	}
+
+	fmt.Printf("Archived '%s'.\n", sessionName)
+	return nil
+}
@@ -1,11 +1,34 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sandctl/sandctl/internal/config"
 	"github.com/sandctl/sandctl/internal/provider"
 	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/sshexec"
+	"github.com/sandctl/sandctl/internal/ui"
 )
 
 // TestMapVMStatusToSession_GivenRunning_ThenReturnsRunningStatus tests running state mapping.
@@ -147,6 +170,58 @@ func TestFormatCreatedTime_GivenTime_ThenReturnsFormattedString(t *testing.T) {
 	}
 }
 
+// TestFormatProvisioning_GivenSessions_ThenShowsDurationOrState tests the provisioning column.
+func TestFormatProvisioning_GivenSessions_ThenShowsDurationOrState(t *testing.T) {
+	started := time.Date(2024, 6, 15, 14, 30, 0, 0, time.UTC)
+	completed := started.Add(83*time.Second + 400*time.Millisecond)
+
+	tests := []struct {
+		name string
+		sess session.Session
+		want string
+	}{
+		{"not recorded", session.Session{Status: session.StatusRunning}, "-"},
+		{"in progress", session.Session{Status: session.StatusProvisioning, ProvisioningStartedAt: &started}, "in progress"},
+		{"failed", session.Session{Status: session.StatusFailed, ProvisioningStartedAt: &started}, "incomplete"},
+		{"completed", session.Session{Status: session.StatusRunning, ProvisioningStartedAt: &started, ProvisioningCompletedAt: &completed}, "1m23s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatProvisioning(&tt.sess); got != tt.want {
+			t.Errorf("%s: formatProvisioning() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestOutputTable_GivenLong_ThenAddsProvisioningColumn tests list --long.
+func TestOutputTable_GivenLong_ThenAddsProvisioningColumn(t *testing.T) {
+	started := time.Date(2024, 6, 15, 14, 30, 0, 0, time.UTC)
+	completed := started.Add(2 * time.Minute)
+	sessions := []session.Session{{
+		ID:                      "alice",
+		Provider:                "hetzner",
+		Status:                  session.StatusRunning,
+		CreatedAt:               started,
+		ProvisioningStartedAt:   &started,
+		ProvisioningCompletedAt: &completed,
+	}}
+
+	var short, long bytes.Buffer
+	if err := outputTable(&short, sessions, false); err != nil {
+		t.Fatalf("outputTable() error = %v", err)
+	}
+	if err := outputTable(&long, sessions, true); err != nil {
+		t.Fatalf("outputTable() error = %v", err)
+	}
+
+	if strings.Contains(short.String(), "PROVISIONING") {
+		t.Errorf("table without --long has a provisioning column:\n%s", short.String())
+	}
+	if !strings.Contains(long.String(), "PROVISIONING") || !strings.Contains(long.String(), "2m0s") {
+		t.Errorf("table with --long lacks the provisioning time:\n%s", long.String())
+	}
+}
+
 // TestSetVersionInfo_GivenValues_ThenSetsGlobals tests version info setting.
 func TestSetVersionInfo_GivenValues_ThenSetsGlobals(t *testing.T) {
 	// Save original values
@@ -230,3 +305,1100 @@ func TestIsVerbose_GivenTrue_ThenReturnsTrue(t *testing.T) {
 		t.Error("expected isVerbose() to return true")
 	}
 }
+
+// TestParseLogLevel_GivenValidLevels_ThenReturnsSlogLevel tests log level parsing.
+func TestParseLogLevel_GivenValidLevels_ThenReturnsSlogLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"info", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"WARNING", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := parseLogLevel(tt.input)
+			if err != nil {
+				t.Fatalf("parseLogLevel(%q) error = %v", tt.input, err)
+			}
+			if level != tt.expected {
+				t.Errorf("parseLogLevel(%q) = %v, want %v", tt.input, level, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseLogLevel_GivenInvalidLevel_ThenReturnsError tests invalid log level.
+func TestParseLogLevel_GivenInvalidLevel_ThenReturnsError(t *testing.T) {
+	if _, err := parseLogLevel("verbose"); err == nil {
+		t.Error("expected error for invalid log level")
+	}
+}
+
+// TestLogsCommand_GivenFollow_ThenTailsWithFollow tests the follow flag.
+func TestLogsCommand_GivenFollow_ThenTailsWithFollow(t *testing.T) {
+	cmd := logsCommand(true)
+
+	if !strings.Contains(cmd, "-F") {
+		t.Errorf("logsCommand(true) = %q, want tail follow flag", cmd)
+	}
+	if !strings.Contains(cmd, cloudInitLogPath) || !strings.Contains(cmd, initScriptLogPath) {
+		t.Errorf("logsCommand(true) = %q, want both log paths", cmd)
+	}
+}
+
+// TestLogsCommand_GivenNoFollow_ThenPrintsOnce tests one-shot output.
+func TestLogsCommand_GivenNoFollow_ThenPrintsOnce(t *testing.T) {
+	cmd := logsCommand(false)
+
+	if strings.Contains(cmd, "-F") {
+		t.Errorf("logsCommand(false) = %q, should not follow", cmd)
+	}
+}
+
+// TestParseEnvFlags_GivenValidValues_ThenReturnsMap tests env flag parsing.
+func TestParseEnvFlags_GivenValidValues_ThenReturnsMap(t *testing.T) {
+	env, err := parseEnvFlags([]string{"FOO=bar", "EMPTY=", "QUOTE=it's=here", "FOO=baz"})
+	if err != nil {
+		t.Fatalf("parseEnvFlags() error = %v", err)
+	}
+
+	expected := map[string]string{"FOO": "baz", "EMPTY": "", "QUOTE": "it's=here"}
+	if len(env) != len(expected) {
+		t.Fatalf("parseEnvFlags() = %v, want %v", env, expected)
+	}
+	for k, v := range expected {
+		if env[k] != v {
+			t.Errorf("env[%q] = %q, want %q", k, env[k], v)
+		}
+	}
+}
+
+// TestParseEnvFlags_GivenInvalidValues_ThenReturnsError tests env flag validation.
+func TestParseEnvFlags_GivenInvalidValues_ThenReturnsError(t *testing.T) {
+	invalid := []string{"NOVALUE", "=value", "BAD-NAME=x", "1ST=x"}
+
+	for _, v := range invalid {
+		t.Run(v, func(t *testing.T) {
+			if _, err := parseEnvFlags([]string{v}); err == nil {
+				t.Errorf("expected error for %q", v)
+			}
+		})
+	}
+}
+
+// TestResolveExecEnv_GivenEnvFileAndFlags_ThenFlagsOverrideFile tests --env-file precedence.
+func TestResolveExecEnv_GivenEnvFileAndFlags_ThenFlagsOverrideFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("# app\nNODE_ENV=production\nPORT=3000\n"), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+
+	env, err := resolveExecEnv(path, []string{"NODE_ENV=test"})
+	if err != nil {
+		t.Fatalf("resolveExecEnv() error = %v", err)
+	}
+	if env["NODE_ENV"] != "test" || env["PORT"] != "3000" || len(env) != 2 {
+		t.Errorf("resolveExecEnv() = %v, want NODE_ENV=test PORT=3000", env)
+	}
+
+	if err := os.WriteFile(path, []byte("PORT=3000\nbroken\n"), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	if _, err := resolveExecEnv(path, nil); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("resolveExecEnv() error = %v, want the malformed line number", err)
+	}
+}
+
+// TestCheckConfigFile_GivenPermissions_ThenValidatesMode tests doctor's config permission check.
+func TestCheckConfigFile_GivenPermissions_ThenValidatesMode(t *testing.T) {
+	tests := []struct {
+		mode    os.FileMode
+		wantErr bool
+	}{
+		{0600, false},
+		{0400, false},
+		{0644, true},
+		{0660, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte("default_provider: hetzner\n"), tt.mode); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatalf("failed to chmod config: %v", err)
+			}
+
+			err := checkConfigFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkConfigFile(%04o) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCheckConfigFile_GivenMissingFile_ThenReturnsError tests missing config detection.
+func TestCheckConfigFile_GivenMissingFile_ThenReturnsError(t *testing.T) {
+	err := checkConfigFile(filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Fatal("expected error for missing config file")
+	}
+	if !strings.Contains(err.Error(), "sandctl init") {
+		t.Errorf("error = %q, want it to suggest 'sandctl init'", err.Error())
+	}
+}
+
+// TestFindSessionByProviderID_GivenSessions_ThenMatchesProviderAndID tests import duplicate detection.
+func TestFindSessionByProviderID_GivenSessions_ThenMatchesProviderAndID(t *testing.T) {
+	sessions := []session.Session{
+		{ID: "alice", Provider: "hetzner", ProviderID: "100"},
+		{ID: "bob", Provider: "other", ProviderID: "200"},
+	}
+
+	if got := findSessionByProviderID(sessions, "hetzner", "100"); got == nil || got.ID != "alice" {
+		t.Errorf("findSessionByProviderID(hetzner, 100) = %v, want alice", got)
+	}
+	if got := findSessionByProviderID(sessions, "hetzner", "200"); got != nil {
+		t.Errorf("findSessionByProviderID(hetzner, 200) = %v, want nil", got)
+	}
+}
+
+// TestGetSessionStore_GivenUnknownBackend_ThenReturnsError tests that a
+// misconfigured store is an error rather than a silent JSON fallback.
+func TestGetSessionStore_GivenUnknownBackend_ThenReturnsError(t *testing.T) {
+	oldStore := sessionStore
+	sessionStore = nil
+	t.Cleanup(func() { sessionStore = oldStore })
+	t.Setenv("SANDCTL_STORE", "bogus")
+
+	if _, err := getSessionStore(); err == nil {
+		t.Error("expected error for unknown session store backend")
+	}
+	if sessionStore != nil {
+		t.Errorf("sessionStore = %T, want nil", sessionStore)
+	}
+}
+
+// TestAnyProvisioning_GivenStatuses_ThenDetectsPendingSessions tests the list --watch exit condition.
+func TestAnyProvisioning_GivenStatuses_ThenDetectsPendingSessions(t *testing.T) {
+	settled := []session.Session{
+		{ID: "alice", Status: session.StatusRunning},
+		{ID: "bob", Status: session.StatusFailed},
+	}
+	if anyProvisioning(settled) {
+		t.Error("anyProvisioning() = true for settled sessions")
+	}
+	if anyProvisioning(nil) {
+		t.Error("anyProvisioning(nil) = true")
+	}
+
+	pending := append(settled, session.Session{ID: "carol", Status: session.StatusProvisioning})
+	if !anyProvisioning(pending) {
+		t.Error("anyProvisioning() = false with a provisioning session")
+	}
+}
+
+// TestPrefixLines_GivenOutput_ThenPrefixesEachLine tests exec --all output grouping.
+func TestPrefixLines_GivenOutput_ThenPrefixesEachLine(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"", ""},
+		{"one\n", "[alice] one\n"},
+		{"one\ntwo", "[alice] one\n[alice] two\n"},
+	}
+
+	for _, tt := range tests {
+		if got := prefixLines("alice", tt.output); got != tt.expected {
+			t.Errorf("prefixLines(%q) = %q, want %q", tt.output, got, tt.expected)
+		}
+	}
+}
+
+// TestReadScript_GivenFileOrStdin_ThenReturnsContents tests exec --file input.
+func TestReadScript_GivenFileOrStdin_ThenReturnsContents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "setup.sh")
+	if err := os.WriteFile(path, []byte("echo from-file\n"), 0600); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	got, err := readScript(path, strings.NewReader("unused"))
+	if err != nil {
+		t.Fatalf("readScript(file) error = %v", err)
+	}
+	if string(got) != "echo from-file\n" {
+		t.Errorf("readScript(file) = %q", got)
+	}
+
+	got, err = readScript("-", strings.NewReader("echo from-stdin\n"))
+	if err != nil {
+		t.Fatalf("readScript(-) error = %v", err)
+	}
+	if string(got) != "echo from-stdin\n" {
+		t.Errorf("readScript(-) = %q", got)
+	}
+
+	if _, err := readScript("-", strings.NewReader("  \n")); err == nil {
+		t.Error("expected error for empty script")
+	}
+	if _, err := readScript(filepath.Join(t.TempDir(), "missing.sh"), nil); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+// TestJoinCommandArgs_GivenSpecialCharacters_ThenShellSeesArgsUnchanged tests exec -- quoting.
+func TestJoinCommandArgs_GivenSpecialCharacters_ThenShellSeesArgsUnchanged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	args := []string{
+		"printf", "%s\\n",
+		"Alice Smith",
+		"it's",
+		`"double"`,
+		"$HOME",
+		"`id`",
+		"a;b && c | d",
+		"*",
+		"back\\slash",
+		"",
+	}
+
+	// printf reuses its format for each remaining argument, one per line
+	out, err := exec.Command("sh", "-c", joinCommandArgs(args)).Output()
+	if err != nil {
+		t.Fatalf("sh -c failed: %v", err)
+	}
+
+	want := strings.Join(args[2:], "\n") + "\n"
+	if string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+// TestWithWorkDir_GivenDirs_ThenPrefixesCd tests exec --cd.
+func TestWithWorkDir_GivenDirs_ThenPrefixesCd(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"", "make"},
+		{"/srv/my app", "cd '/srv/my app' && make"},
+		{"~", `cd "$HOME" && make`},
+		{"~/it's", `cd "$HOME"/'it'\''s' && make`},
+	}
+
+	for _, tt := range tests {
+		if got := withWorkDir("make", tt.dir); got != tt.want {
+			t.Errorf("withWorkDir(make, %q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+// TestBackgroundCommand_GivenCommand_ThenReturnsPIDAndLogsOutput tests exec --background.
+func TestBackgroundCommand_GivenCommand_ThenReturnsPIDAndLogsOutput(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("requires setsid")
+	}
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "bg.log")
+	command := withWorkDir(backgroundCommand(`echo "$PWD $GREETING"`, logPath), dir)
+
+	out, err := exec.Command("sh", "-c", "export GREETING='hi there'; "+command).Output()
+	if err != nil {
+		t.Fatalf("sh -c failed: %v", err)
+	}
+	if _, err := strconv.Atoi(strings.TrimSpace(string(out))); err != nil {
+		t.Errorf("output = %q, want a PID", out)
+	}
+
+	want := dir + " hi there\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if string(data) == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("log = %q, want %q", data, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestRunExec_GivenBackgroundWithoutCommand_ThenReturnsError tests --background validation.
+func TestRunExec_GivenBackgroundWithoutCommand_ThenReturnsError(t *testing.T) {
+	oldCommand, oldBg := execCommand, execBg
+	t.Cleanup(func() { execCommand, execBg = oldCommand, oldBg })
+	execCommand, execBg = "", true
+
+	err := runExec(execCmd, []string{"alice"})
+	if err == nil || !strings.Contains(err.Error(), "--background requires") {
+		t.Errorf("runExec() error = %v, want --background requires a command", err)
+	}
+}
+
+// TestResolveExecCommand_GivenArgsAfterDash_ThenUsesThemAsCommand tests exec -- parsing.
+func TestResolveExecCommand_GivenArgsAfterDash_ThenUsesThemAsCommand(t *testing.T) {
+	oldCommand, oldFile := execCommand, execFile
+	t.Cleanup(func() { execCommand, execFile = oldCommand, oldFile })
+	execCommand, execFile = "", ""
+
+	names, command, err := resolveExecCommand([]string{"alice", "git", "commit", "-m", "fix it"}, 1)
+	if err != nil {
+		t.Fatalf("resolveExecCommand() error = %v", err)
+	}
+	if len(names) != 1 || names[0] != "alice" {
+		t.Errorf("names = %v, want [alice]", names)
+	}
+	if want := `'git' 'commit' '-m' 'fix it'`; command != want {
+		t.Errorf("command = %q, want %q", command, want)
+	}
+
+	execCommand = "ls -la"
+	names, command, err = resolveExecCommand([]string{"alice"}, -1)
+	if err != nil || len(names) != 1 || command != "ls -la" {
+		t.Errorf("resolveExecCommand() = %v, %q, %v; want [alice], %q", names, command, err, "ls -la")
+	}
+
+	if _, _, err := resolveExecCommand([]string{"alice", "ls"}, 1); err == nil {
+		t.Error("expected error for --command with a command after --")
+	}
+
+	execCommand, execFile = "", "setup.sh"
+	if _, _, err := resolveExecCommand([]string{"alice", "ls"}, 1); err == nil {
+		t.Error("expected error for --file with a command after --")
+	}
+}
+
+// TestRunExec_GivenPTYWithoutCommand_ThenReturnsError tests --pty validation.
+func TestRunExec_GivenPTYWithoutCommand_ThenReturnsError(t *testing.T) {
+	oldCommand, oldPTY := execCommand, execPTY
+	t.Cleanup(func() { execCommand, execPTY = oldCommand, oldPTY })
+	execCommand, execPTY = "", true
+
+	err := runExec(execCmd, []string{"alice"})
+	if err == nil || !strings.Contains(err.Error(), "--pty requires") {
+		t.Errorf("runExec() error = %v, want --pty requires a command", err)
+	}
+}
+
+// TestRunExec_GivenJSONWithoutCommand_ThenReturnsError tests --json validation.
+func TestRunExec_GivenJSONWithoutCommand_ThenReturnsError(t *testing.T) {
+	oldCommand, oldJSON := execCommand, execJSON
+	t.Cleanup(func() { execCommand, execJSON = oldCommand, oldJSON })
+	execCommand, execJSON = "", true
+
+	err := runExec(execCmd, []string{"alice"})
+	if err == nil || !strings.Contains(err.Error(), "--json requires") {
+		t.Errorf("runExec() error = %v, want --json requires a command", err)
+	}
+}
+
+// TestWriteExecResult_GivenResult_ThenWritesSeparateStreams tests the exec --json output.
+func TestWriteExecResult_GivenResult_ThenWritesSeparateStreams(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExecResult(&buf, &sshexec.ExecResult{Stdout: "ok\n", Stderr: "warn\n", ExitCode: 2}); err != nil {
+		t.Fatalf("writeExecResult() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]any{"stdout": "ok\n", "stderr": "warn\n", "exit_code": float64(2)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeExecResult() = %v, want %v", got, want)
+	}
+}
+
+// TestStaleSSHKeys_GivenProviderKeys_ThenReturnsUnmatchedSandctlKeys tests keys prune selection.
+func TestStaleSSHKeys_GivenProviderKeys_ThenReturnsUnmatchedSandctlKeys(t *testing.T) {
+	localKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIlocal alice@laptop"
+	keys := []provider.SSHKey{
+		{ID: "1", Name: sshKeyName(localKey), PublicKey: localKey},
+		{ID: "2", Name: "sandctl-0badc0de", PublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIold"},
+		{ID: "3", Name: "sandctl-renamed", PublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIlocal other-comment"},
+		{ID: "4", Name: "work-laptop", PublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIwork"},
+	}
+
+	stale := staleSSHKeys(keys, localKey)
+
+	if len(stale) != 1 || stale[0].ID != "2" {
+		t.Errorf("staleSSHKeys() = %v, want only key 2", stale)
+	}
+}
+
+// TestFormatLabels_GivenLabels_ThenReturnsSortedPairs tests inspect label formatting.
+func TestFormatLabels_GivenLabels_ThenReturnsSortedPairs(t *testing.T) {
+	if got := formatLabels(nil); got != "-" {
+		t.Errorf("formatLabels(nil) = %q, want %q", got, "-")
+	}
+
+	got := formatLabels(map[string]string{"project": "ghost", "env": "dev"})
+	if want := "env=dev, project=ghost"; got != want {
+		t.Errorf("formatLabels() = %q, want %q", got, want)
+	}
+}
+
+// TestOutputInspectJSON_GivenVM_ThenIncludesVMDetails tests inspect --json output.
+func TestOutputInspectJSON_GivenVM_ThenIncludesVMDetails(t *testing.T) {
+	sess := &session.Session{ID: "alice", Status: session.StatusRunning, Provider: "hetzner", ProviderID: "42"}
+	vm := &provider.VM{ID: "42", Datacenter: "ash-dc1", IPv6: "2001:db8::1", ServerType: "cpx31"}
+
+	var buf strings.Builder
+	if err := outputInspect(&buf, ui.FormatJSON, sess, vm); err != nil {
+		t.Fatalf("outputInspect() error = %v", err)
+	}
+
+	for _, want := range []string{`"id": "alice"`, `"datacenter": "ash-dc1"`, `"ipv6": "2001:db8::1"`, `"server_type": "cpx31"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %s:\n%s", want, buf.String())
+		}
+	}
+}
+
+// TestOutputInspect_GivenFailedSession_ThenShowsFailureReason tests the failure reason in inspect.
+func TestOutputInspect_GivenFailedSession_ThenShowsFailureReason(t *testing.T) {
+	sess := &session.Session{ID: "alice", Status: session.StatusFailed, Provider: "hetzner", FailureReason: session.FailureWaitReadyTimeout}
+
+	var text, jsonOut strings.Builder
+	if err := outputInspect(&text, ui.FormatTable, sess, nil); err != nil {
+		t.Fatalf("outputInspect(text) error = %v", err)
+	}
+	if err := outputInspect(&jsonOut, ui.FormatJSON, sess, nil); err != nil {
+		t.Fatalf("outputInspect(json) error = %v", err)
+	}
+
+	if !strings.Contains(text.String(), "Failure:      wait_ready_timeout\n") {
+		t.Errorf("text output missing failure reason:\n%s", text.String())
+	}
+	if !strings.Contains(jsonOut.String(), `"failure_reason": "wait_ready_timeout"`) {
+		t.Errorf("JSON output missing failure_reason:\n%s", jsonOut.String())
+	}
+}
+
+// TestOutputInspect_GivenYAMLFormat_ThenUsesJSONFieldNames tests inspect -o yaml output.
+func TestOutputInspect_GivenYAMLFormat_ThenUsesJSONFieldNames(t *testing.T) {
+	sess := &session.Session{ID: "alice", Status: session.StatusRunning, Provider: "hetzner", ProviderID: "42"}
+	vm := &provider.VM{ID: "42", Datacenter: "ash-dc1", ServerType: "cpx31"}
+
+	var buf strings.Builder
+	if err := outputInspect(&buf, ui.FormatYAML, sess, vm); err != nil {
+		t.Fatalf("outputInspect() error = %v", err)
+	}
+
+	for _, want := range []string{"session:\n  id: alice\n", "provider_id: \"42\"", "datacenter: ash-dc1", "server_type: cpx31"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+// TestProviderSSHUser_GivenConfig_ThenReturnsConfiguredUser tests the ssh_user lookup.
+func TestProviderSSHUser_GivenConfig_ThenReturnsConfiguredUser(t *testing.T) {
+	cfg := &config.Config{
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "token", SSHUser: "ubuntu"},
+		},
+	}
+
+	if got := providerSSHUser(cfg, "hetzner"); got != "ubuntu" {
+		t.Errorf("providerSSHUser(hetzner) = %q, want %q", got, "ubuntu")
+	}
+	if got := providerSSHUser(cfg, "other"); got != "" {
+		t.Errorf("providerSSHUser(other) = %q, want empty", got)
+	}
+
+	// Flag > provider config > top-level config > built-in default
+	cfg.SSHUser = "admin"
+	if got := firstNonEmpty("root", providerSSHUser(cfg, "hetzner")); got != "root" {
+		t.Errorf("ssh user with --ssh-user = %q, want %q", got, "root")
+	}
+	if got := providerSSHUser(cfg, "hetzner"); got != "ubuntu" {
+		t.Errorf("providerSSHUser(hetzner) with top-level default = %q, want %q", got, "ubuntu")
+	}
+	cfg.Providers["plain"] = config.ProviderConfig{Token: "token"}
+	if got := providerSSHUser(cfg, "plain"); got != "admin" {
+		t.Errorf("providerSSHUser(plain) = %q, want top-level %q", got, "admin")
+	}
+	if opts := sshUserOptions(""); len(opts) != 0 {
+		t.Errorf("sshUserOptions(\"\") returned %d options, want none", len(opts))
+	}
+}
+
+// TestDetectPublicIP_GivenServerResponse_ThenReturnsIP tests public IP detection.
+func TestDetectPublicIP_GivenServerResponse_ThenReturnsIP(t *testing.T) {
+	body := "203.0.113.7\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	oldURL := publicIPURL
+	publicIPURL = server.URL
+	t.Cleanup(func() { publicIPURL = oldURL })
+
+	ip, err := detectPublicIP(context.Background())
+	if err != nil {
+		t.Fatalf("detectPublicIP() error = %v", err)
+	}
+	if ip != "203.0.113.7" {
+		t.Errorf("detectPublicIP() = %q, want %q", ip, "203.0.113.7")
+	}
+
+	body = "<html>rate limited</html>"
+	if _, err := detectPublicIP(context.Background()); err == nil {
+		t.Error("expected error for non-IP response")
+	}
+}
+
+// TestValidatePorts_GivenPorts_ThenRejectsOutOfRange tests --open-port validation.
+func TestValidatePorts_GivenPorts_ThenRejectsOutOfRange(t *testing.T) {
+	if err := validatePorts([]int{22, 3000, 65535}); err != nil {
+		t.Errorf("validatePorts() error = %v", err)
+	}
+	for _, port := range []int{0, -1, 65536} {
+		if err := validatePorts([]int{port}); err == nil {
+			t.Errorf("validatePorts(%d) expected error", port)
+		}
+	}
+}
+
+// TestCurrentVersionInfo_GivenBuildInfo_ThenIncludesRuntimeMetadata tests version --json fields.
+func TestCurrentVersionInfo_GivenBuildInfo_ThenIncludesRuntimeMetadata(t *testing.T) {
+	origVersion := version
+	defer func() { version = origVersion }()
+	version = "1.2.3"
+
+	info := currentVersionInfo()
+
+	if info.Version != "1.2.3" {
+		t.Errorf("Version = %q, want %q", info.Version, "1.2.3")
+	}
+	if info.GoVersion != runtime.Version() || info.OS != runtime.GOOS || info.Arch != runtime.GOARCH {
+		t.Errorf("runtime fields = %+v, want current runtime", info)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, key := range []string{"version", "commit", "build_time", "go_version", "os", "arch"} {
+		if !strings.Contains(string(data), `"`+key+`"`) {
+			t.Errorf("JSON missing key %q: %s", key, data)
+		}
+	}
+}
+
+// fakeImageProvider is a provider that only supports listing images.
+type fakeImageProvider struct {
+	provider.Provider
+	images []provider.Image
+}
+
+func (f *fakeImageProvider) Name() string { return "fake" }
+
+func (f *fakeImageProvider) ListImages(ctx context.Context) ([]provider.Image, error) {
+	return f.images, nil
+}
+
+// TestResolveImage_GivenAliasOrName_ThenResolvesAliasesOnly tests image alias resolution.
+func TestResolveImage_GivenAliasOrName_ThenResolvesAliasesOnly(t *testing.T) {
+	prov := &fakeImageProvider{images: []provider.Image{
+		{Name: "ubuntu-22.04", OSFlavor: "ubuntu", OSVersion: "22.04"},
+		{Name: "ubuntu-24.04", OSFlavor: "ubuntu", OSVersion: "24.04"},
+	}}
+
+	got, err := resolveImage(context.Background(), prov, "ubuntu")
+	if err != nil || got != "ubuntu-24.04" {
+		t.Errorf("resolveImage(ubuntu) = %q, %v; want ubuntu-24.04", got, err)
+	}
+
+	got, err = resolveImage(context.Background(), prov, "ubuntu-22.04")
+	if err != nil || got != "ubuntu-22.04" {
+		t.Errorf("resolveImage(ubuntu-22.04) = %q, %v; want unchanged", got, err)
+	}
+
+	if _, err := resolveImage(context.Background(), prov, "debian"); err == nil {
+		t.Error("expected error for alias with no matching image")
+	}
+}
+
+// fakeRegionProvider is a provider that only supports listing regions.
+type fakeRegionProvider struct {
+	provider.Provider
+	regions []provider.Region
+}
+
+func (f *fakeRegionProvider) Name() string { return "fake" }
+
+func (f *fakeRegionProvider) ListRegions(ctx context.Context) ([]provider.Region, error) {
+	return f.regions, nil
+}
+
+// TestRegionWarning_GivenAvailability_ThenWarnsOnlyWhenUnavailable tests the new region check.
+func TestRegionWarning_GivenAvailability_ThenWarnsOnlyWhenUnavailable(t *testing.T) {
+	prov := &fakeRegionProvider{regions: []provider.Region{
+		{Name: "fsn1", ServerTypes: []string{"cpx11", "cpx31"}},
+		{Name: "nbg1", ServerTypes: []string{"cpx11"}},
+	}}
+
+	tests := []struct {
+		name   string
+		opts   provider.CreateOpts
+		expect string
+	}{
+		{"available", provider.CreateOpts{Region: "fsn1", ServerType: "cpx31"}, ""},
+		{"type unavailable", provider.CreateOpts{Region: "nbg1", ServerType: "cpx31"}, "server type cpx31 is currently unavailable in nbg1"},
+		{"unknown region", provider.CreateOpts{Region: "ash", ServerType: "cpx31"}, "region ash is not offered by fake"},
+		{"unresolved defaults", provider.CreateOpts{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := regionWarning(context.Background(), prov, tt.opts)
+			if tt.expect == "" && got != "" {
+				t.Errorf("regionWarning() = %q, want none", got)
+			}
+			if !strings.Contains(got, tt.expect) {
+				t.Errorf("regionWarning() = %q, want it to contain %q", got, tt.expect)
+			}
+		})
+	}
+}
+
+// fakeGetProvider is a provider whose Get returns a fixed VM or error.
+type fakeGetProvider struct {
+	provider.Provider
+	vm  *provider.VM
+	err error
+}
+
+func (f *fakeGetProvider) Get(ctx context.Context, id string) (*provider.VM, error) {
+	return f.vm, f.err
+}
+
+// TestCheckSessionVM_GivenDeletedVM_ThenFailsAndMarksStopped tests the pre-connect provider check.
+func TestCheckSessionVM_GivenDeletedVM_ThenFailsAndMarksStopped(t *testing.T) {
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	sess := session.Session{ID: "alice", Status: session.StatusRunning, Provider: "hetzner", ProviderID: "1", IPAddress: "192.0.2.1"}
+	if err := store.Add(sess); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	err := checkSessionVM(context.Background(), &fakeGetProvider{err: provider.ErrNotFound}, store, &sess)
+	if err == nil || !strings.Contains(err.Error(), "no longer exists on provider") {
+		t.Fatalf("checkSessionVM() error = %v, want not-on-provider error", err)
+	}
+
+	got, err := store.Get("alice")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != session.StatusStopped {
+		t.Errorf("Status = %s, want %s", got.Status, session.StatusStopped)
+	}
+}
+
+// TestCheckSessionVM_GivenChangedIP_ThenUpdatesSession tests address refresh.
+func TestCheckSessionVM_GivenChangedIP_ThenUpdatesSession(t *testing.T) {
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	sess := session.Session{ID: "alice", Status: session.StatusRunning, Provider: "hetzner", ProviderID: "1", IPAddress: "192.0.2.1"}
+	if err := store.Add(sess); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	prov := &fakeGetProvider{vm: &provider.VM{ID: "1", Status: provider.StatusRunning, IPAddress: "192.0.2.2"}}
+	if err := checkSessionVM(context.Background(), prov, store, &sess); err != nil {
+		t.Fatalf("checkSessionVM() error = %v", err)
+	}
+
+	if sess.IPAddress != "192.0.2.2" {
+		t.Errorf("IPAddress = %q, want 192.0.2.2", sess.IPAddress)
+	}
+	got, _ := store.Get("alice")
+	if got == nil || got.IPAddress != "192.0.2.2" {
+		t.Errorf("stored session = %+v, want updated IP", got)
+	}
+}
+
+// TestCheckSessionVM_GivenProviderError_ThenProceeds tests that API failures don't block access.
+func TestCheckSessionVM_GivenProviderError_ThenProceeds(t *testing.T) {
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	sess := session.Session{ID: "alice", Status: session.StatusRunning, Provider: "hetzner", ProviderID: "1"}
+
+	if err := checkSessionVM(context.Background(), &fakeGetProvider{err: fmt.Errorf("rate limited")}, store, &sess); err != nil {
+		t.Errorf("checkSessionVM() error = %v, want nil", err)
+	}
+}
+
+// TestResolveSSHKeyOverride_GivenKeyFile_ThenReturnsFingerprintAndPrivateKey tests --ssh-key file handling.
+func TestResolveSSHKeyOverride_GivenKeyFile_ThenReturnsFingerprintAndPrivateKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("NewPublicKey() error = %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey() error = %v", err)
+	}
+
+	privPath := filepath.Join(t.TempDir(), "team_ed25519")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(privPath+".pub", ssh.MarshalAuthorizedKey(sshPub), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, arg := range []string{privPath, privPath + ".pub"} {
+		key, err := resolveSSHKeyOverride(arg)
+		if err != nil {
+			t.Fatalf("resolveSSHKeyOverride(%q) error = %v", arg, err)
+		}
+		if key.Fingerprint != ssh.FingerprintSHA256(sshPub) {
+			t.Errorf("Fingerprint = %q, want %q", key.Fingerprint, ssh.FingerprintSHA256(sshPub))
+		}
+		if key.PrivateKeyPath != privPath {
+			t.Errorf("PrivateKeyPath = %q, want %q", key.PrivateKeyPath, privPath)
+		}
+		if !samePublicKey(key.PublicKey, string(ssh.MarshalAuthorizedKey(sshPub))) {
+			t.Errorf("PublicKey = %q, want generated key", key.PublicKey)
+		}
+	}
+}
+
+// TestResolveSSHKeyOverride_GivenInvalidKeyFile_ThenReturnsError tests --ssh-key validation.
+func TestResolveSSHKeyOverride_GivenInvalidKeyFile_ThenReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	pubPath := filepath.Join(dir, "bad.pub")
+	if err := os.WriteFile(pubPath, []byte("not a key"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := resolveSSHKeyOverride(pubPath); err == nil {
+		t.Error("expected error for invalid public key")
+	}
+	if _, err := resolveSSHKeyOverride(filepath.Join(dir, "missing.pub")); err == nil {
+		t.Error("expected error for missing key file")
+	}
+}
+
+// TestMatchSessionNames_GivenPrefix_ThenReturnsActiveMatches tests session name completion.
+func TestMatchSessionNames_GivenPrefix_ThenReturnsActiveMatches(t *testing.T) {
+	sessions := []session.Session{
+		{ID: "alice", Status: session.StatusRunning},
+		{ID: "alex", Status: session.StatusProvisioning},
+		{ID: "albert", Status: session.StatusStopped},
+		{ID: "bob", Status: session.StatusRunning},
+	}
+
+	got := matchSessionNames(sessions, "Al")
+	want := []string{"alice", "alex"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("matchSessionNames(Al) = %v, want %v", got, want)
+	}
+
+	if got := matchSessionNames(sessions, ""); len(got) != 3 {
+		t.Errorf("matchSessionNames(\"\") = %v, want 3 active sessions", got)
+	}
+}
+
+// TestTruncateNote_GivenLongOrMultilineNote_ThenShortensToOneLine tests inspect note formatting.
+func TestTruncateNote_GivenLongOrMultilineNote_ThenShortensToOneLine(t *testing.T) {
+	tests := []struct {
+		note  string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"line one\nline two", 40, "line one line two"},
+		{"reproducing the checkout bug", 15, "reproducing ..."},
+	}
+
+	for _, tt := range tests {
+		if got := truncateNote(tt.note, tt.width); got != tt.want {
+			t.Errorf("truncateNote(%q, %d) = %q, want %q", tt.note, tt.width, got, tt.want)
+		}
+	}
+}
+
+// TestParseIdleProbe_GivenOutput_ThenParsesUsersAndLoad tests watchdog probe parsing.
+func TestParseIdleProbe_GivenOutput_ThenParsesUsersAndLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		wantUsers int
+		wantLoad  float64
+		wantErr   bool
+	}{
+		{"idle", "0\n0.03\n", 0, 0.03, false},
+		{"logged in", "2\n1.50\n", 2, 1.5, false},
+		{"padded count", "      1\n0.00\n", 1, 0, false},
+		{"missing load", "0\n", 0, 0, true},
+		{"garbage", "who: not found\n", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, load, err := parseIdleProbe(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIdleProbe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if users != tt.wantUsers || load != tt.wantLoad {
+				t.Errorf("parseIdleProbe() = %d, %v; want %d, %v", users, load, tt.wantUsers, tt.wantLoad)
+			}
+		})
+	}
+}
+
+// TestVMGone_GivenProviderResponses_ThenOnlyConfirmsNotFound tests the prune check.
+func TestVMGone_GivenProviderResponses_ThenOnlyConfirmsNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		prov     *fakeGetProvider
+		wantGone bool
+		wantErr  bool
+	}{
+		{"not found", &fakeGetProvider{err: fmt.Errorf("wrapped: %w", provider.ErrNotFound)}, true, false},
+		{"exists", &fakeGetProvider{vm: &provider.VM{ID: "1", Status: provider.StatusStopped}}, false, false},
+		{"api error", &fakeGetProvider{err: provider.ErrAuthFailed}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gone, err := vmGone(context.Background(), tt.prov, "1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("vmGone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gone != tt.wantGone {
+				t.Errorf("vmGone() = %v, want %v", gone, tt.wantGone)
+			}
+		})
+	}
+}
+
+// fakeListProvider is a provider whose List returns fixed VMs and counts calls.
+type fakeListProvider struct {
+	provider.Provider
+	vms   []*provider.VM
+	calls int
+}
+
+func (f *fakeListProvider) Name() string {
+	return "fakelist"
+}
+
+func (f *fakeListProvider) List(ctx context.Context) ([]*provider.VM, error) {
+	f.calls++
+	return f.vms, nil
+}
+
+// TestSyncWithProviderAPI_GivenRecentSync_ThenUsesCachedList tests the VM list cache.
+func TestSyncWithProviderAPI_GivenRecentSync_ThenUsesCachedList(t *testing.T) {
+	prov := &fakeListProvider{vms: []*provider.VM{{ID: "1", Status: provider.StatusRunning}}}
+	provider.Register("fakelist", func(*config.Config) (provider.Provider, error) {
+		return prov, nil
+	})
+
+	oldCfg, oldCache, oldRefresh := cfg, vmCache, refresh
+	cfg = &config.Config{}
+	vmCache = provider.NewVMCache(t.TempDir(), time.Minute)
+	t.Cleanup(func() { cfg, vmCache, refresh = oldCfg, oldCache, oldRefresh })
+
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	sess := session.Session{ID: "alice", Status: session.StatusRunning, Provider: "fakelist", ProviderID: "1"}
+	if err := store.Add(sess); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	syncWithProviderAPI(context.Background(), []session.Session{sess}, store)
+	syncWithProviderAPI(context.Background(), []session.Session{sess}, store)
+	if prov.calls != 1 {
+		t.Errorf("List called %d times, want 1", prov.calls)
+	}
+
+	// A session whose VM isn't in the cached listing forces a fresh one
+	bob := session.Session{ID: "bob", Status: session.StatusRunning, Provider: "fakelist", ProviderID: "2"}
+	syncWithProviderAPI(context.Background(), []session.Session{sess, bob}, store)
+	if prov.calls != 2 {
+		t.Errorf("List called %d times after a new VM, want 2", prov.calls)
+	}
+
+	refresh = true
+	syncWithProviderAPI(context.Background(), []session.Session{sess}, store)
+	if prov.calls != 3 {
+		t.Errorf("List called %d times with --refresh, want 3", prov.calls)
+	}
+}
+
+// TestSyncWithProviderAPI_GivenArchivedSession_ThenSkipsProvider tests that archived sessions aren't synced.
+func TestSyncWithProviderAPI_GivenArchivedSession_ThenSkipsProvider(t *testing.T) {
+	prov := &fakeListProvider{}
+	provider.Register("fakelist", func(*config.Config) (provider.Provider, error) {
+		return prov, nil
+	})
+
+	oldCfg, oldCache := cfg, vmCache
+	cfg = &config.Config{}
+	vmCache = provider.NewVMCache(t.TempDir(), time.Minute)
+	t.Cleanup(func() { cfg, vmCache = oldCfg, oldCache })
+
+	archivedAt := time.Now()
+	sess := session.Session{ID: "alice", Status: session.StatusStopped, Provider: "fakelist", ProviderID: "1", ArchivedAt: &archivedAt}
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+
+	got := syncWithProviderAPI(context.Background(), []session.Session{sess}, store)
+	if prov.calls != 0 {
+		t.Errorf("List called %d times, want 0", prov.calls)
+	}
+	if len(got) != 1 || got[0].Status != session.StatusStopped {
+		t.Errorf("syncWithProviderAPI() = %v, want the archived session unchanged", got)
+	}
+}
+
+// TestSyncWithProviderAPI_GivenSessionProfiles_ThenListsEachProject tests that sessions are synced with their profile's provider.
+func TestSyncWithProviderAPI_GivenSessionProfiles_ThenListsEachProject(t *testing.T) {
+	projects := map[string]*fakeListProvider{
+		"personal": {vms: []*provider.VM{{ID: "1", Status: provider.StatusRunning}}},
+		"work":     {vms: []*provider.VM{{ID: "2", Status: provider.StatusRunning}}},
+	}
+	provider.Register("fakelist", func(c *config.Config) (provider.Provider, error) {
+		return projects[c.Providers["fakelist"].Token], nil
+	})
+
+	oldBase, oldCfg, oldCache := baseCfg, cfg, vmCache
+	baseCfg = &config.Config{
+		Providers: map[string]config.ProviderConfig{"fakelist": {Token: "personal"}},
+		Profiles: map[string]config.Profile{
+			"work": {Providers: map[string]config.ProviderConfig{"fakelist": {Token: "work"}}},
+		},
+	}
+	cfg = baseCfg
+	vmCache = provider.NewVMCache(t.TempDir(), time.Minute)
+	t.Cleanup(func() { baseCfg, cfg, vmCache = oldBase, oldCfg, oldCache })
+
+	sessions := []session.Session{
+		{ID: "alice", Status: session.StatusRunning, Provider: "fakelist", ProviderID: "1"},
+		{ID: "bob", Status: session.StatusRunning, Provider: "fakelist", Profile: "work", ProviderID: "2"},
+	}
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+
+	got := syncWithProviderAPI(context.Background(), sessions, store)
+	for _, sess := range got {
+		if sess.Status != session.StatusRunning {
+			t.Errorf("session %s status = %s, want running", sess.ID, sess.Status)
+		}
+	}
+	for name, prov := range projects {
+		if prov.calls != 1 {
+			t.Errorf("%s project listed %d times, want 1", name, prov.calls)
+		}
+	}
+}
+
+// TestParseCreatedRange_GivenBounds_ThenParsesDurationsAndTimes tests list --since/--before parsing.
+func TestParseCreatedRange_GivenBounds_ThenParsesDurationsAndTimes(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	r, err := parseCreatedRange("24h", "2025-06-02T06:00:00Z", now)
+	if err != nil {
+		t.Fatalf("parseCreatedRange() error = %v", err)
+	}
+	if want := now.Add(-24 * time.Hour); !r.since.Equal(want) {
+		t.Errorf("since = %v, want %v", r.since, want)
+	}
+	if want := time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC); !r.before.Equal(want) {
+		t.Errorf("before = %v, want %v", r.before, want)
+	}
+
+	for _, tc := range []struct{ since, before string }{
+		{"yesterday", ""},
+		{"", "-1h"},
+		{"1h", "2h"},
+	} {
+		if _, err := parseCreatedRange(tc.since, tc.before, now); err == nil {
+			t.Errorf("parseCreatedRange(%q, %q) expected error", tc.since, tc.before)
+		}
+	}
+}
+
+// TestFilterByCreated_GivenRange_ThenKeepsSessionsInside tests the list creation time filter.
+func TestFilterByCreated_GivenRange_ThenKeepsSessionsInside(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	sessions := []session.Session{
+		{ID: "old", CreatedAt: now.Add(-72 * time.Hour)},
+		{ID: "mid", CreatedAt: now.Add(-36 * time.Hour)},
+		{ID: "new", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	got := filterByCreated(sessions, createdRange{since: now.Add(-48 * time.Hour), before: now.Add(-24 * time.Hour)})
+	if len(got) != 1 || got[0].ID != "mid" {
+		t.Errorf("filterByCreated() = %v, want only mid", got)
+	}
+
+	got = filterByCreated(sessions, createdRange{since: now.Add(-2 * time.Hour)})
+	if len(got) != 1 || got[0].ID != "new" {
+		t.Errorf("filterByCreated() = %v, want only new", got)
+	}
+}
+
+// TestExecContext_GivenTimeout_ThenSetsDeadline tests exec --timeout handling.
+func TestExecContext_GivenTimeout_ThenSetsDeadline(t *testing.T) {
+	oldTimeout := execTimeout
+	t.Cleanup(func() { execTimeout = oldTimeout })
+
+	execTimeout = 0
+	ctx, cancel := execContext()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("execContext() without --timeout has a deadline")
+	}
+	cancel()
+
+	execTimeout = time.Minute
+	ctx, cancel = execContext()
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("execContext() deadline = %v, %v; want within a minute", deadline, ok)
+	}
+
+	var exitErr *exitError
+	if err := commandTimedOut(); !errors.As(err, &exitErr) || exitErr.code != execTimeoutExitCode {
+		t.Errorf("commandTimedOut() = %v, want exit code %d", err, execTimeoutExitCode)
+	}
+}
+
+// TestConfigFileForWrite_GivenStdin_ThenReturnsError tests that --config - can't be written.
+func TestConfigFileForWrite_GivenStdin_ThenReturnsError(t *testing.T) {
+	oldCfgFile := cfgFile
+	t.Cleanup(func() { cfgFile = oldCfgFile })
+
+	cfgFile = config.StdinPath
+	if _, err := configFileForWrite(); err == nil {
+		t.Error("expected error writing the config to stdin")
+	}
+
+	cfgFile = "/tmp/sandctl-config"
+	if path, err := configFileForWrite(); err != nil || path != cfgFile {
+		t.Errorf("configFileForWrite() = %q, %v; want %q", path, err, cfgFile)
+	}
+}
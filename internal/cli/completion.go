@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/session"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a shell completion script for sandctl.
+
+Completions include the names of active sessions for commands that take a
+session, such as console, exec, and destroy.`,
+	Example: `  # Load completions in the current bash shell
+  source <(sandctl completion bash)
+
+  # Install zsh completions
+  sandctl completion zsh > "${fpath[1]}/_sandctl"
+
+  # Install fish completions
+  sandctl completion fish > ~/.config/fish/completions/sandctl.fish`,
+	ValidArgs:             []string{"bash", "zsh", "fish"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	// Replace cobra's default completion command with ours
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		return rootCmd.GenFishCompletion(os.Stdout, true)
+	default:
+		return fmt.Errorf("unsupported shell: %s", args[0])
+	}
+}
+
+// completeSessionNames completes the first argument with active session names.
+// Errors, including a missing config or store, produce no suggestions.
+func completeSessionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sessions, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return matchSessionNames(sessions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// matchSessionNames returns the IDs of active sessions starting with prefix.
+func matchSessionNames(sessions []session.Session, prefix string) []string {
+	prefix = session.NormalizeName(prefix)
+
+	var names []string
+	for _, sess := range sessions {
+		if sess.Status.IsActive() && strings.HasPrefix(sess.ID, prefix) {
+			names = append(names, sess.ID)
+		}
+	}
+	return names
+}
@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config parent command.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage sandctl configuration",
+	Long: `Manage the sandctl configuration file.
+
+Use 'sandctl init' to create or update the configuration interactively.
+
+Subcommands:
+  migrate  Convert a legacy Sprites configuration to the provider format`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
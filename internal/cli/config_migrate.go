@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Convert a legacy Sprites configuration",
+	Long: `Convert a configuration from the old Sprites format to the provider-based
+format.
+
+Only the Hetzner API token and SSH key are prompted for; the Opencode Zen
+key and git settings are carried over, and the region and server type use
+the defaults unless --region or --server-type is given. The old file is
+backed up to config.bak next to it.
+
+For non-interactive use, pass the same flags as 'sandctl init'.`,
+	Example: `  # Migrate interactively
+  sandctl config migrate
+
+  # Migrate from a script
+  sandctl config migrate --hetzner-token TOKEN --ssh-agent`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+func init() {
+	addInitFlags(configMigrateCmd)
+
+	configCmd.AddCommand(configMigrateCmd)
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	configPath, err := configFileForWrite()
+	if err != nil {
+		return err
+	}
+
+	if err := validateInitFlags(); err != nil {
+		return err
+	}
+
+	legacyCfg := loadExistingConfig(configPath)
+	if legacyCfg == nil {
+		return fmt.Errorf("no configuration found at %s; run 'sandctl init' instead", configPath)
+	}
+	if !legacyCfg.IsLegacyConfig() {
+		fmt.Printf("Configuration at %s is already up to date.\n", configPath)
+		return nil
+	}
+
+	hasFlags := initHetznerToken != "" || initSSHPublicKey != "" || initSSHAgent
+	if !hasFlags && !ui.IsTerminal() {
+		return errors.New("config migrate requires a terminal for interactive mode, or use --hetzner-token with --ssh-agent or --ssh-public-key flags")
+	}
+
+	backupPath, err := backupConfig(configPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up old configuration to %s\n", backupPath)
+
+	if hasFlags {
+		return runNonInteractiveMigrate(configPath, legacyCfg)
+	}
+
+	return runMigrateFlow(configPath, legacyCfg, os.Stdin, os.Stdout)
+}
+
+// runNonInteractiveMigrate migrates a legacy configuration using the init
+// flags for the settings it lacks.
+func runNonInteractiveMigrate(configPath string, legacyCfg *config.Config) error {
+	if err := requireInitFlags(); err != nil {
+		return err
+	}
+
+	sshCfg, err := sshKeyConfigFromFlags()
+	if err != nil {
+		return err
+	}
+
+	cfg := migrateConfig(legacyCfg, initHetznerToken, sshCfg)
+	applyOptionalInitFlags(cfg)
+	return saveInitConfig(configPath, nil, cfg)
+}
+
+// runMigrateFlow prompts for the settings a legacy configuration lacks and
+// saves the migrated configuration.
+func runMigrateFlow(configPath string, legacyCfg *config.Config, input io.Reader, output io.Writer) error {
+	prompter := ui.NewPrompter(input, output)
+
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Migrating from Sprites to pluggable providers...")
+	fmt.Fprintln(output)
+
+	hetznerToken, err := promptHetznerToken(prompter, legacyCfg)
+	if err != nil {
+		return err
+	}
+
+	sshCfg, err := promptSSHKeyConfig(prompter, output, legacyCfg)
+	if err != nil {
+		return err
+	}
+
+	cfg := migrateConfig(legacyCfg, hetznerToken, sshCfg)
+	if err := config.Save(configPath, cfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	fmt.Fprintln(output)
+	fmt.Fprintf(output, "Configuration migrated to %s\n", configPath)
+	if cfg.OpencodeZenKey != "" {
+		fmt.Fprintln(output, "Your opencode_zen_key was preserved.")
+	}
+	return nil
+}
+
+// migrateConfig builds a provider-based configuration from a legacy one,
+// keeping its Opencode Zen key and git settings.
+func migrateConfig(legacyCfg *config.Config, hetznerToken string, sshCfg *sshKeyConfig) *config.Config {
+	cfg := &config.Config{
+		DefaultProvider: "hetzner",
+		OpencodeZenKey:  legacyCfg.OpencodeZenKey,
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {
+				Token:      hetznerToken,
+				Region:     firstNonEmpty(initRegion, "ash"),
+				ServerType: firstNonEmpty(initServerType, "cpx31"),
+				Image:      "ubuntu-24.04",
+			},
+		},
+		GitConfigPath: legacyCfg.GitConfigPath,
+		GitUserName:   legacyCfg.GitUserName,
+		GitUserEmail:  legacyCfg.GitUserEmail,
+		GitHubToken:   legacyCfg.GitHubToken,
+	}
+	setSSHKeyConfig(cfg, sshCfg)
+	return cfg
+}
+
+// backupConfig copies the configuration file to config.bak in the same
+// directory and returns the backup path.
+func backupConfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	backupPath := path + ".bak"
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up configuration: %w", err)
+	}
+	return backupPath, nil
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/sandctl/sandctl/internal/ui"
 )
 
+var consoleCommand string
+
 var consoleCmd = &cobra.Command{
 	Use:   "console <name>",
 	Short: "Open an interactive console to a running session",
@@ -21,18 +23,25 @@ var consoleCmd = &cobra.Command{
 This command provides a direct terminal connection similar to SSH, with full
 support for colors, terminal dimensions, and TUI applications.
 
-For running single commands, use 'sandctl exec -c <command>' instead.`,
+Use --command to run a single interactive command (with a PTY) instead of a
+login shell. For non-interactive commands, use 'sandctl exec -c <command>'.`,
 	Example: `  # Connect to a session (case-insensitive)
   sandctl console alice
   sandctl console Alice
 
-  # For single commands, use exec instead:
+  # Run an interactive command with a PTY
+  sandctl console alice -c "sudo docker logs -f app"
+
+  # For non-interactive commands, use exec instead:
   sandctl exec alice -c "ls -la"`,
-	Args: cobra.ExactArgs(1),
-	RunE: runConsole,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runConsole,
 }
 
 func init() {
+	consoleCmd.Flags().StringVarP(&consoleCommand, "command", "c", "", "run this command with a PTY instead of a login shell")
+
 	rootCmd.AddCommand(consoleCmd)
 }
 
@@ -54,7 +63,10 @@ func runConsole(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get session store
-	store := getSessionStore()
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
 
 	// Check if session exists in local store
 	sess, err := store.Get(sessionName)
@@ -83,19 +95,27 @@ func runConsole(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Fail fast if the VM was deleted outside sandctl
+	if err := ensureSessionVM(store, sess); err != nil {
+		return err
+	}
+
+	markSessionActive(store, sess)
+
 	// Check if we have IP address
-	if sess.IPAddress == "" {
+	host := sessionAddress(sess)
+	if host == "" {
 		return fmt.Errorf("session '%s' has no IP address", sessionName)
 	}
 
-	fmt.Printf("Connecting to %s (%s)...\n", sessionName, sess.IPAddress)
+	fmt.Printf("Connecting to %s (%s)...\n", sessionName, host)
 
 	// Create SSH client and open console
-	client, err := createSSHClient(sess.IPAddress)
+	client, err := createSessionSSHClient(sess, host)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 	defer client.Close()
 
-	return client.Console(sshexec.ConsoleOptions{})
+	return client.Console(sshexec.ConsoleOptions{Command: consoleCommand})
 }
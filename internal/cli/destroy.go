@@ -12,7 +12,12 @@ import (
 	"github.com/sandctl/sandctl/internal/ui"
 )
 
-var destroyForce bool
+var (
+	destroyForce      bool
+	destroyAll        bool
+	destroyKeepVolume bool
+	destroyKeepRecord bool
+)
 
 var destroyCmd = &cobra.Command{
 	Use:   "destroy <name>",
@@ -20,19 +25,49 @@ var destroyCmd = &cobra.Command{
 	Long: `Terminate and remove a sandboxed VM.
 
 By default, prompts for confirmation before destroying. Use --force
-to skip the confirmation prompt.`,
+to skip the confirmation prompt.
+
+Use --all to destroy every session in the local store. Failures are
+reported and skipped; the remaining sessions are still destroyed.
+
+A data volume created with 'sandctl new --attach-volume' is deleted along
+with the session unless --keep-volume is passed. A kept volume can be
+attached to a new session with 'sandctl new --volume <id>'.
+
+Use --keep-record to delete the VM but keep the session in the local store,
+marked stopped, with its labels, notes and creation parameters. It can then
+be recreated with 'sandctl new --from'.`,
 	Example: `  # Destroy with confirmation
   sandctl destroy alice
 
   # Destroy without confirmation (case-insensitive)
-  sandctl destroy Alice --force`,
+  sandctl destroy Alice --force
+
+  # Destroy a session but keep its data volume
+  sandctl destroy alice --keep-volume
+
+  # Delete the VM but keep the session to recreate it later
+  sandctl destroy alice --keep-record
+  sandctl new --from alice
+
+  # Destroy every session without confirmation
+  sandctl destroy --all --force`,
 	Aliases: []string{"rm", "delete"},
-	Args:    cobra.ExactArgs(1),
-	RunE:    runDestroy,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if destroyAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runDestroy,
 }
 
 func init() {
 	destroyCmd.Flags().BoolVarP(&destroyForce, "force", "f", false, "skip confirmation prompt")
+	destroyCmd.Flags().BoolVar(&destroyAll, "all", false, "destroy all sessions")
+	destroyCmd.Flags().BoolVar(&destroyKeepVolume, "keep-volume", false, "keep the session's data volume")
+	destroyCmd.Flags().BoolVar(&destroyKeepRecord, "keep-record", false, "delete the VM but keep the session record, marked stopped")
 
 	rootCmd.AddCommand(destroyCmd)
 }
@@ -40,6 +75,10 @@ func init() {
 func runDestroy(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	if destroyAll {
+		return runDestroyAll(ctx)
+	}
+
 	// Normalize the session name (case-insensitive)
 	sessionName := session.NormalizeName(args[0])
 
@@ -49,7 +88,10 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get session from store
-	store := getSessionStore()
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
 	sess, err := store.Get(sessionName)
 	if err != nil {
 		var notFound *session.NotFoundError
@@ -96,7 +138,7 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 	prov, err := getProviderFromSession(sess)
 	if err != nil {
 		// If provider lookup fails but we have provider_id, still try to remove from store
-		verboseLog("Warning: could not get provider: %v", err)
+		logger.Warn("could not get provider", "session", sessionName, "error", err)
 	}
 
 	// Show progress
@@ -107,16 +149,145 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 	if prov != nil && sess.ProviderID != "" {
 		if err := prov.Delete(ctx, sess.ProviderID); err != nil {
 			// Log the error but continue with local cleanup
-			verboseLog("Warning: failed to delete VM from provider: %v", err)
+			logger.Warn("failed to delete VM from provider", "session", sessionName, "error", err)
 		}
 	}
+	if prov != nil {
+		if err := deleteSessionFirewall(ctx, prov, sess.FirewallID); err != nil {
+			logger.Warn("failed to delete firewall from provider", "session", sessionName, "firewall", sess.FirewallID, "error", err)
+		}
+		if !destroyKeepVolume {
+			if err := deleteSessionVolume(ctx, prov, sess.VolumeID); err != nil {
+				logger.Warn("failed to delete volume from provider", "session", sessionName, "volume", sess.VolumeID, "error", err)
+			}
+		}
+	}
+
+	forgetHostKey(sess.IPAddress)
+	forgetHostKey(sess.IPv6)
+	volumeID := sess.VolumeID
 
 	// Remove from local store
-	if err := store.Remove(sessionName); err != nil {
-		verboseLog("Warning: failed to remove session from local store: %v", err)
+	if err := removeSessionRecord(store, sess); err != nil {
+		logger.Warn("failed to remove session from local store", "session", sessionName, "error", err)
 	}
 
 	spin.Success(fmt.Sprintf("Session '%s' destroyed.", sessionName))
+	if destroyKeepVolume && volumeID != "" {
+		fmt.Printf("Kept volume %s; use 'sandctl new --volume %s' to reattach it.\n", volumeID, volumeID)
+	}
+	if destroyKeepRecord {
+		fmt.Printf("Kept session record; use 'sandctl new --from %s' to recreate it.\n", sessionName)
+	}
 
 	return nil
 }
+
+// runDestroyAll destroys every session in the local store, continuing past
+// individual failures and reporting a summary at the end.
+func runDestroyAll(ctx context.Context) error {
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No sessions to destroy.")
+		return nil
+	}
+
+	// Confirm once for the whole batch unless --force
+	if !destroyForce {
+		confirmed, confirmErr := ui.Confirm(os.Stdin, os.Stdout,
+			fmt.Sprintf("Destroy all %d sessions? This cannot be undone.", len(sessions)))
+		if confirmErr != nil {
+			return fmt.Errorf("failed to read confirmation: %w", confirmErr)
+		}
+		if !confirmed {
+			fmt.Println("Canceled.")
+			return nil
+		}
+	}
+
+	succeeded, failed := 0, 0
+	for i := range sessions {
+		sess := &sessions[i]
+		volumeID := sess.VolumeID
+		if err := destroySession(ctx, store, sess); err != nil {
+			failed++
+			ui.PrintError(os.Stderr, "failed to destroy '%s': %v", sess.ID, err)
+			continue
+		}
+		succeeded++
+		ui.PrintSuccess(os.Stdout, "Session '%s' destroyed.", sess.ID)
+		if destroyKeepVolume && volumeID != "" {
+			fmt.Printf("Kept volume %s; use 'sandctl new --volume %s' to reattach it.\n", volumeID, volumeID)
+		}
+	}
+
+	fmt.Printf("\nDestroyed %d of %d sessions", succeeded, len(sessions))
+	if failed > 0 {
+		fmt.Printf(" (%d failed)", failed)
+	}
+	fmt.Println(".")
+
+	if failed > 0 {
+		return fmt.Errorf("failed to destroy %d sessions", failed)
+	}
+	return nil
+}
+
+// destroySession deletes a session's VM and removes its local record, or
+// with --keep-record marks it stopped. The record is kept unchanged if the
+// VM could not be deleted so the destroy can be retried.
+// Legacy sessions have no provider info and are only removed from the store.
+func destroySession(ctx context.Context, store session.Store, sess *session.Session) error {
+	if !sess.IsLegacySession() && sess.ProviderID != "" {
+		prov, err := getProviderFromSession(sess)
+		if err != nil {
+			return err
+		}
+		if err := prov.Delete(ctx, sess.ProviderID); err != nil {
+			return fmt.Errorf("failed to delete VM: %w", err)
+		}
+		if err := deleteSessionFirewall(ctx, prov, sess.FirewallID); err != nil {
+			logger.Warn("failed to delete firewall", "session", sess.ID, "firewall", sess.FirewallID, "error", err)
+		}
+		if !destroyKeepVolume {
+			if err := deleteSessionVolume(ctx, prov, sess.VolumeID); err != nil {
+				logger.Warn("failed to delete volume", "session", sess.ID, "volume", sess.VolumeID, "error", err)
+			}
+		}
+	}
+
+	forgetHostKey(sess.IPAddress)
+	forgetHostKey(sess.IPv6)
+	if err := removeSessionRecord(store, sess); err != nil {
+		return fmt.Errorf("failed to remove from local store: %w", err)
+	}
+	return nil
+}
+
+// removeSessionRecord removes a destroyed session from the store. With
+// --keep-record the session is instead marked stopped and its VM details are
+// cleared, keeping labels, notes and creation parameters for 'new --from'.
+func removeSessionRecord(store session.Store, sess *session.Session) error {
+	if !destroyKeepRecord {
+		return store.Remove(sess.ID)
+	}
+
+	sess.Status = session.StatusStopped
+	sess.ProviderID = ""
+	sess.IPAddress = ""
+	sess.IPv6 = ""
+	sess.FirewallID = ""
+	if !destroyKeepVolume {
+		sess.VolumeID = ""
+		sess.VolumeSizeGB = 0
+	}
+	return store.UpdateSession(*sess)
+}
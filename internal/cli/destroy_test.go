@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/session"
+)
+
+// useTestSessionStore points the shared session store at a temp file and
+// restores the previous store when the test ends.
+func useTestSessionStore(t *testing.T) session.Store {
+	t.Helper()
+
+	oldStore := sessionStore
+	sessionStore = session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	t.Cleanup(func() { sessionStore = oldStore })
+
+	return sessionStore
+}
+
+// TestRunDestroyAll_GivenMixedResults_ThenContinuesPastFailures tests bulk destroy.
+func TestRunDestroyAll_GivenMixedResults_ThenContinuesPastFailures(t *testing.T) {
+	store := useTestSessionStore(t)
+
+	oldCfg, oldForce := cfg, destroyForce
+	cfg = &config.Config{DefaultProvider: "hetzner"}
+	destroyForce = true
+	t.Cleanup(func() { cfg, destroyForce = oldCfg, oldForce })
+
+	sessions := []session.Session{
+		// Legacy session: no provider, only removed locally
+		{ID: "alice", Status: session.StatusRunning, CreatedAt: time.Now()},
+		// Unknown provider: VM deletion fails, record is kept
+		{ID: "bob", Status: session.StatusRunning, CreatedAt: time.Now(), Provider: "bogus", ProviderID: "1"},
+		// No VM yet: only removed locally
+		{ID: "carol", Status: session.StatusFailed, CreatedAt: time.Now(), Provider: "bogus"},
+	}
+	for _, sess := range sessions {
+		if err := store.Add(sess); err != nil {
+			t.Fatalf("failed to add session: %v", err)
+		}
+	}
+
+	err := runDestroyAll(context.Background())
+	if err == nil {
+		t.Fatal("expected error summarizing failures")
+	}
+
+	remaining, err := store.List()
+	if err != nil {
+		t.Fatalf("failed to list sessions: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != "bob" {
+		t.Errorf("remaining sessions = %v, want only bob", remaining)
+	}
+}
+
+// TestRunDestroyAll_GivenNoSessions_ThenSucceeds tests the empty case.
+func TestRunDestroyAll_GivenNoSessions_ThenSucceeds(t *testing.T) {
+	useTestSessionStore(t)
+
+	if err := runDestroyAll(context.Background()); err != nil {
+		t.Errorf("runDestroyAll() error = %v", err)
+	}
+}
+
+// TestRunDestroyAll_GivenKeepRecord_ThenMarksSessionsStopped tests destroy --keep-record.
+func TestRunDestroyAll_GivenKeepRecord_ThenMarksSessionsStopped(t *testing.T) {
+	store := useTestSessionStore(t)
+
+	oldCfg, oldForce, oldKeep := cfg, destroyForce, destroyKeepRecord
+	cfg = &config.Config{DefaultProvider: "hetzner"}
+	destroyForce, destroyKeepRecord = true, true
+	t.Cleanup(func() { cfg, destroyForce, destroyKeepRecord = oldCfg, oldForce, oldKeep })
+
+	// No VM yet, so nothing is deleted at the provider
+	sess := session.Session{
+		ID:         "alice",
+		Status:     session.StatusFailed,
+		CreatedAt:  time.Now(),
+		Provider:   "bogus",
+		IPAddress:  "203.0.113.10",
+		ServerType: "cpx21",
+		Labels:     map[string]string{"project": "ghost"},
+		Notes:      "checkout bug",
+	}
+	if err := store.Add(sess); err != nil {
+		t.Fatalf("failed to add session: %v", err)
+	}
+
+	if err := runDestroyAll(context.Background()); err != nil {
+		t.Fatalf("runDestroyAll() error = %v", err)
+	}
+
+	got, err := store.Get("alice")
+	if err != nil {
+		t.Fatalf("session record was not kept: %v", err)
+	}
+	if got.Status != session.StatusStopped || got.IPAddress != "" {
+		t.Errorf("status = %s, ip = %q, want stopped with no IP", got.Status, got.IPAddress)
+	}
+	if got.ServerType != "cpx21" || got.Labels["project"] != "ghost" || got.Notes != "checkout bug" {
+		t.Errorf("kept record lost its metadata: %+v", got)
+	}
+}
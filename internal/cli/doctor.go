@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check sandctl configuration and connectivity",
+	Long: `Run a series of checks against your sandctl setup and print the results.
+
+Checks:
+  - Config file exists and has 0600 permissions
+  - Provider API token is accepted
+  - SSH key is reachable (agent key, or public key file)
+  - Private key for the configured public key is in the agent or on disk (file mode)
+
+Exits with an error if any check fails.`,
+	Example: `  # Check your setup
+  sandctl doctor`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorCheck is a single named check run by the doctor command.
+type doctorCheck struct {
+	name string
+	run  func() error
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = config.DefaultConfigPath()
+	}
+
+	// A config read from stdin has no file permissions to check
+	var checks []doctorCheck
+	if configPath != config.StdinPath {
+		checks = append(checks, doctorCheck{name: "Config file permissions", run: func() error { return checkConfigFile(configPath) }})
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		checks = append(checks, doctorCheck{name: "Config file is valid", run: func() error { return err }})
+	} else {
+		checks = append(checks,
+			doctorCheck{name: "Provider token", run: func() error { return verifyProviderCredentials(cfg) }},
+			doctorCheck{name: "SSH key reachable", run: func() error { return checkSSHKey(cfg) }},
+		)
+		if !cfg.IsAgentMode() {
+			checks = append(checks, doctorCheck{name: "SSH private key available", run: func() error { return checkSSHPrivateKey(cfg) }})
+		}
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if err := check.run(); err != nil {
+			failed++
+			fmt.Printf("%s %s: %v\n", ui.FailMark(), check.name, err)
+			continue
+		}
+		fmt.Printf("%s %s\n", ui.SuccessMark(), check.name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkConfigFile verifies the config file exists and is only accessible by its owner.
+func checkConfigFile(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found. Run 'sandctl init' to create it", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		return fmt.Errorf("%s has permissions %04o, expected 0600", path, mode)
+	}
+	return nil
+}
+
+// checkSSHKey verifies the configured SSH key can be used.
+func checkSSHKey(cfg *config.Config) error {
+	if cfg.IsAgentMode() {
+		return checkAgentKey(cfg)
+	}
+
+	_, err := cfg.GetSSHPublicKey()
+	return err
+}
+
+// checkSSHPrivateKey verifies the private key for the configured public key is
+// available, either loaded in the SSH agent or next to the public key file.
+func checkSSHPrivateKey(cfg *config.Config) error {
+	if _, err := agentSignerForPublicKey(cfg); err == nil {
+		return nil
+	}
+
+	privateKeyPath := strings.TrimSuffix(cfg.ExpandSSHPublicKeyPath(), ".pub")
+	if _, err := os.Stat(privateKeyPath); err != nil {
+		return fmt.Errorf("private key not found in SSH agent or at %s", privateKeyPath)
+	}
+	return nil
+}
@@ -1,46 +1,232 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/envfile"
 	"github.com/sandctl/sandctl/internal/session"
 	"github.com/sandctl/sandctl/internal/sshexec"
 	"github.com/sandctl/sandctl/internal/ui"
 )
 
-var execCommand string
+// defaultExecParallel is the default number of sessions exec --all runs on at once.
+const defaultExecParallel = 5
+
+// uploadProgressThreshold is the size above which uploads show a progress bar.
+const uploadProgressThreshold = 1 << 20
+
+// execTimeoutExitCode is the exit code when a command runs past --timeout,
+// matching timeout(1).
+const execTimeoutExitCode = 124
+
+var (
+	execCommand  string
+	execFile     string
+	execEnv      []string
+	execEnvFile  string
+	execAll      bool
+	execFilters  []string
+	execParallel int
+	execTimeout  time.Duration
+	execStdin    bool
+	execPTY      bool
+	execJSON     bool
+	execDir      string
+	execBg       bool
+)
 
 var execCmd = &cobra.Command{
-	Use:   "exec <name>",
+	Use:   "exec <name> [-- command [args...]]",
 	Short: "Execute a command in a running session",
 	Long: `Execute a command in a running VM via SSH.
 
-Use --command to run a single command and return the output.
-Without --command, opens an interactive shell session.`,
+Use --command to run a single command and return the output. Alternatively,
+pass the command and its arguments after --; each argument is quoted so it
+reaches the command exactly as given.
+Use --file to upload a local script and run it with bash; pass - to read
+the script from stdin. The script's exit code is preserved.
+Without --command or --file, opens an interactive shell session.
+
+Use --all with --command to run the command on every running session
+concurrently (optionally narrowed with --filter). Output lines are prefixed
+with the session name, and the command exits non-zero if any session failed.
+
+Use --interactive with --command to connect your stdin to the command, so
+data can be piped in. Unlike the interactive shell, no terminal is
+allocated; the command's stdin is closed when yours reaches EOF.
+
+Use --pty with a command that needs a terminal, like a progress bar, prompt,
+or full-screen program. The command runs with a pseudo-terminal, your
+terminal is restored when it ends, and its exit code is preserved.
+
+Use --json with a command to print its stdout, stderr, and exit code as a
+JSON object instead of streaming the output, for scripts. sandctl still exits
+with the command's exit code.
+
+Use --background to start a long-running command, like a dev server, detached
+from the SSH session. Its output goes to a log file on the VM; the log path
+and process ID are printed and sandctl returns immediately.
+
+Use --cd to run the command or script in a directory on the VM.
+
+Use --timeout to stop the command if it runs too long. The remote command is
+aborted and sandctl exits with code 124.`,
 	Example: `  # Run a single command
   sandctl exec alice -c "ls -la"
 
   # Check docker status
   sandctl exec alice -c "docker ps"
 
+  # Pass the command as arguments, without shell quoting
+  sandctl exec alice -- git config --global user.name "Alice Smith"
+
+  # Run a local script
+  sandctl exec alice -f setup.sh
+
+  # Run a script from stdin
+  cat setup.sh | sandctl exec alice -f -
+
+  # Pipe data into a command
+  echo hi | sandctl exec alice --interactive -c cat
+
+  # Run a full-screen program once
+  sandctl exec alice --pty -c htop
+
+  # Capture the output and exit code as JSON
+  sandctl exec alice --json -c "npm test"
+
+  # Pass environment variables to the command
+  sandctl exec alice --env NODE_ENV=test -c "npm test"
+
+  # Load variables from a .env file, overriding one of them
+  sandctl exec alice --env-file .env --env NODE_ENV=test -c "npm test"
+
+  # Start a dev server that keeps running after sandctl exits
+  sandctl exec alice --background --cd ~/project -c "npm run dev"
+
+  # Give up if the install takes more than 10 minutes
+  sandctl exec alice --timeout 10m -c "npm install"
+
+  # Run on every running session with a label
+  sandctl exec --all --filter project=ghost -c "npm test"
+
   # Interactive shell (case-insensitive)
   sandctl exec alice
   sandctl exec Alice`,
-	Args: cobra.ExactArgs(1),
-	RunE: runExec,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// Arguments after -- are the command, not session names
+		names, _ := splitExecArgs(args, cmd.ArgsLenAtDash())
+		if execAll {
+			return cobra.NoArgs(cmd, names)
+		}
+		return cobra.ExactArgs(1)(cmd, names)
+	},
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runExec,
 }
 
 func init() {
 	execCmd.Flags().StringVarP(&execCommand, "command", "c", "", "run a single command instead of interactive shell")
+	execCmd.Flags().StringVarP(&execFile, "file", "f", "", "run a local script file with bash (- for stdin)")
+	execCmd.Flags().StringArrayVar(&execEnv, "env", nil, "set an environment variable for the command (KEY=VALUE, repeatable)")
+	execCmd.Flags().StringVar(&execEnvFile, "env-file", "", "read environment variables for the command from a .env file")
+	execCmd.Flags().BoolVar(&execAll, "all", false, "run the command on all running sessions")
+	execCmd.Flags().StringArrayVar(&execFilters, "filter", nil, "with --all, only sessions with this label (key=value, repeatable)")
+	execCmd.Flags().IntVar(&execParallel, "parallel", defaultExecParallel, "with --all, maximum sessions to run on at once")
+	execCmd.Flags().BoolVarP(&execStdin, "interactive", "i", false, "with --command, forward local stdin to the command")
+	execCmd.Flags().BoolVar(&execPTY, "pty", false, "run the command with a pseudo-terminal")
+	execCmd.Flags().BoolVar(&execJSON, "json", false, "print the command's stdout, stderr, and exit code as JSON")
+	execCmd.Flags().StringVar(&execDir, "cd", "", "run the command or script in this directory on the VM")
+	execCmd.Flags().BoolVar(&execBg, "background", false, "start the command detached, logging its output to a file on the VM")
+	execCmd.Flags().DurationVar(&execTimeout, "timeout", 0, "abort the command if it runs longer than this (e.g., 30s, 10m)")
+
+	execCmd.MarkFlagsMutuallyExclusive("command", "file")
+	execCmd.MarkFlagsMutuallyExclusive("interactive", "file")
+	execCmd.MarkFlagsMutuallyExclusive("interactive", "all")
+	execCmd.MarkFlagsMutuallyExclusive("pty", "interactive", "file", "all")
+	execCmd.MarkFlagsMutuallyExclusive("json", "pty", "interactive", "file", "all")
+	execCmd.MarkFlagsMutuallyExclusive("background", "json", "pty", "interactive", "file", "all")
 
 	rootCmd.AddCommand(execCmd)
 }
 
 func runExec(cmd *cobra.Command, args []string) error {
+	args, command, err := resolveExecCommand(args, cmd.ArgsLenAtDash())
+	if err != nil {
+		return err
+	}
+
+	if (len(execEnv) > 0 || execEnvFile != "") && command == "" && execFile == "" {
+		return fmt.Errorf("--env and --env-file require --command or --file")
+	}
+	env, err := resolveExecEnv(execEnvFile, execEnv)
+	if err != nil {
+		return err
+	}
+	if execTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	if execTimeout > 0 && command == "" && execFile == "" {
+		return fmt.Errorf("--timeout requires --command or --file")
+	}
+	if execStdin && command == "" {
+		return fmt.Errorf("--interactive requires --command")
+	}
+	if execStdin && cfgFile == config.StdinPath {
+		return fmt.Errorf("--interactive and --config - cannot both read from stdin")
+	}
+	if execPTY && command == "" {
+		return fmt.Errorf("--pty requires --command or a command after --")
+	}
+	if execPTY && cfgFile == config.StdinPath {
+		return fmt.Errorf("--pty and --config - cannot both read from stdin")
+	}
+	if execJSON && command == "" {
+		return fmt.Errorf("--json requires --command or a command after --")
+	}
+	if execBg && command == "" {
+		return fmt.Errorf("--background requires --command or a command after --")
+	}
+	if execBg && execTimeout > 0 {
+		return fmt.Errorf("--timeout cannot be used with --background")
+	}
+	if execDir != "" && command == "" && execFile == "" {
+		return fmt.Errorf("--cd requires --command or --file")
+	}
+
+	if execAll {
+		if execFile != "" {
+			return fmt.Errorf("--file cannot be used with --all")
+		}
+		return runExecAll(withWorkDir(command, execDir), env)
+	}
+
+	// Read the script up front so a bad path fails before connecting
+	var script []byte
+	if execFile == "-" && cfgFile == config.StdinPath {
+		return fmt.Errorf("--file - and --config - cannot both read from stdin")
+	}
+	if execFile != "" {
+		script, err = readScript(execFile, os.Stdin)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Normalize the session name (case-insensitive)
 	sessionName := session.NormalizeName(args[0])
 
@@ -50,7 +236,10 @@ func runExec(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get session store
-	store := getSessionStore()
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
 
 	// Check if session exists in local store
 	sess, err := store.Get(sessionName)
@@ -79,23 +268,62 @@ func runExec(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Fail fast if the VM was deleted outside sandctl
+	if err := ensureSessionVM(store, sess); err != nil {
+		return err
+	}
+
+	markSessionActive(store, sess)
+
 	// Check if we have IP address
-	if sess.IPAddress == "" {
+	host := sessionAddress(sess)
+	if host == "" {
 		return fmt.Errorf("session '%s' has no IP address", sessionName)
 	}
 
 	// Create SSH client
-	client, err := createSSHClient(sess.IPAddress)
+	client, err := createSessionSSHClient(sess, host)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 	defer client.Close()
 
+	ctx, cancel := execContext()
+	defer cancel()
+
+	// Script mode
+	if execFile != "" {
+		return execScript(ctx, client, script, env, execDir)
+	}
+
 	// Single command mode
-	if execCommand != "" {
-		verboseLog("Executing command: %s", execCommand)
+	if command != "" {
+		verboseLog("Executing command: %s", command)
 
-		output, err := client.Exec(execCommand)
+		exports, err := sshexec.ExportEnv(env)
+		if err != nil {
+			return err
+		}
+		if execBg {
+			return execBackground(ctx, client, sessionName, command, exports)
+		}
+		command = strings.TrimSpace(exports + " " + withWorkDir(command, execDir))
+
+		if execStdin {
+			return execStreaming(ctx, client, command, os.Stdin, "command execution failed")
+		}
+		if execPTY {
+			return execResultError(client.ExecPTY(ctx, command, os.Stdin, os.Stdout), "command execution failed")
+		}
+		if execJSON {
+			return execJSONResult(ctx, client, command)
+		}
+
+		output, err := client.ExecContext(ctx, command)
+		if errors.Is(err, context.DeadlineExceeded) {
+			fmt.Print(output)
+			return commandTimedOut()
+		}
 		if err != nil {
 			return fmt.Errorf("command execution failed: %w", err)
 		}
@@ -105,6 +333,396 @@ func runExec(cmd *cobra.Command, args []string) error {
 	}
 
 	// Interactive mode
-	fmt.Printf("Connecting to %s (%s)...\n", sessionName, sess.IPAddress)
+	fmt.Printf("Connecting to %s (%s)...\n", sessionName, host)
 	return client.Console(sshexec.ConsoleOptions{})
 }
+
+// resolveExecCommand returns the session name arguments and the command to
+// run, from --command or from the arguments after -- (dash is their index,
+// or -1 if there is no --). It is an error to give both.
+func resolveExecCommand(args []string, dash int) ([]string, string, error) {
+	names, commandArgs := splitExecArgs(args, dash)
+	if len(commandArgs) == 0 {
+		return names, execCommand, nil
+	}
+	if execCommand != "" {
+		return nil, "", fmt.Errorf("--command and a command after -- cannot be used together")
+	}
+	if execFile != "" {
+		return nil, "", fmt.Errorf("--file and a command after -- cannot be used together")
+	}
+	return names, joinCommandArgs(commandArgs), nil
+}
+
+// splitExecArgs splits args at dash into the arguments before -- and after it.
+func splitExecArgs(args []string, dash int) ([]string, []string) {
+	if dash < 0 {
+		return args, nil
+	}
+	return args[:dash], args[dash:]
+}
+
+// joinCommandArgs builds a shell command from args, quoting each argument so
+// the remote shell passes it to the command unchanged.
+func joinCommandArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = sshexec.ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// execContext returns the context to run the command in, with a deadline if
+// --timeout is set.
+func execContext() (context.Context, context.CancelFunc) {
+	if execTimeout > 0 {
+		return context.WithTimeout(context.Background(), execTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// commandTimedOut reports that the command ran past --timeout and returns
+// the error that exits with execTimeoutExitCode.
+func commandTimedOut() error {
+	ui.PrintError(os.Stderr, "command timed out after %s", execTimeout)
+	return &exitError{code: execTimeoutExitCode}
+}
+
+// readScript reads a script from path, or from stdin if path is "-".
+func readScript(path string, stdin io.Reader) ([]byte, error) {
+	var script []byte
+	var err error
+	if path == "-" {
+		script, err = io.ReadAll(stdin)
+	} else {
+		script, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	if len(bytes.TrimSpace(script)) == 0 {
+		return nil, fmt.Errorf("script is empty")
+	}
+	return script, nil
+}
+
+// execScript uploads script to a temp file on the VM, runs it with bash in
+// dir (the login directory if empty) while streaming its output, and removes
+// it afterward. A non-zero exit status from the script becomes sandctl's exit
+// status. The script is aborted if ctx is done first.
+func execScript(ctx context.Context, client *sshexec.Client, script []byte, env map[string]string, dir string) error {
+	remotePath := fmt.Sprintf("/tmp/sandctl-exec-%d.sh", time.Now().UnixNano())
+
+	verboseLog("Uploading script to %s", remotePath)
+	if err := uploadFile(client, script, remotePath, 0700, "Uploading script"); err != nil {
+		return fmt.Errorf("failed to upload script: %w", err)
+	}
+	defer func() {
+		if _, err := client.Exec("rm -f " + sshexec.ShellQuote(remotePath)); err != nil {
+			logger.Warn("failed to remove script", "path", remotePath, "error", err)
+		}
+	}()
+
+	exports, err := sshexec.ExportEnv(env)
+	if err != nil {
+		return err
+	}
+
+	command := withWorkDir("bash "+sshexec.ShellQuote(remotePath), dir)
+	return execStreaming(ctx, client, strings.TrimSpace(exports+" "+command), nil, "script execution failed")
+}
+
+// withWorkDir returns command prefixed to run in dir on the VM. A leading ~
+// in dir is expanded there. An empty dir returns command unchanged.
+func withWorkDir(command, dir string) string {
+	switch {
+	case dir == "":
+		return command
+	case dir == "~":
+		return `cd "$HOME" && ` + command
+	case strings.HasPrefix(dir, "~/"):
+		return `cd "$HOME"/` + sshexec.ShellQuote(dir[2:]) + " && " + command
+	default:
+		return "cd " + sshexec.ShellQuote(dir) + " && " + command
+	}
+}
+
+// backgroundCommand wraps command to run with bash in a new session, immune
+// to the SSH session hanging up, with its output appended to logPath. The
+// wrapper prints the process ID and returns at once.
+func backgroundCommand(command, logPath string) string {
+	return fmt.Sprintf("{ setsid nohup bash -c %s >> %s 2>&1 < /dev/null & echo \"$!\"; }",
+		sshexec.ShellQuote(command), sshexec.ShellQuote(logPath))
+}
+
+// execBackground starts command detached after exports, in the --cd
+// directory, and reports its PID and log file.
+func execBackground(ctx context.Context, client *sshexec.Client, sessionName, command, exports string) error {
+	logPath := fmt.Sprintf("/tmp/sandctl-bg-%d.log", time.Now().UnixNano())
+	command = strings.TrimSpace(exports + " " + withWorkDir(backgroundCommand(command, logPath), execDir))
+
+	output, err := client.ExecContext(ctx, command)
+	if err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	ui.PrintSuccess(os.Stdout, "Started in the background (PID %s)", strings.TrimSpace(output))
+	fmt.Printf("Log: %s\n", logPath)
+	fmt.Printf("Follow it with: sandctl exec %s -- tail -f %s\n", sessionName, logPath)
+	return nil
+}
+
+// execStreaming runs command with stdin and the local stdout and stderr
+// connected to it. The command is aborted if ctx is done first.
+func execStreaming(ctx context.Context, client *sshexec.Client, command string, stdin io.Reader, failure string) error {
+	return execResultError(client.ExecWithStreamsContext(ctx, command, stdin, os.Stdout, os.Stderr), failure)
+}
+
+// execJSONResult runs command and prints its output and exit code as JSON.
+// The command's exit code becomes sandctl's exit status; a command aborted
+// by --timeout is reported with execTimeoutExitCode.
+func execJSONResult(ctx context.Context, client *sshexec.Client, command string) error {
+	result, err := client.ExecWithResultContext(ctx, command)
+	if errors.Is(err, context.DeadlineExceeded) {
+		if result != nil {
+			result.ExitCode = execTimeoutExitCode
+			_ = writeExecResult(os.Stdout, result)
+		}
+		return commandTimedOut()
+	}
+	if err != nil {
+		return fmt.Errorf("command execution failed: %w", err)
+	}
+
+	if err := writeExecResult(os.Stdout, result); err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return &exitError{code: result.ExitCode}
+	}
+	return nil
+}
+
+// writeExecResult writes result to w as indented JSON.
+func writeExecResult(w io.Writer, result *sshexec.ExecResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// execResultError converts the error from running a command into sandctl's
+// result: a non-zero exit status becomes sandctl's exit status, a deadline
+// reports the --timeout, and other failures are wrapped with failure.
+func execResultError(runErr error, failure string) error {
+	var exitErr *ssh.ExitError
+	switch {
+	case runErr == nil:
+		return nil
+	case errors.Is(runErr, context.DeadlineExceeded):
+		return commandTimedOut()
+	case errors.As(runErr, &exitErr):
+		return &exitError{code: exitErr.ExitStatus()}
+	default:
+		return fmt.Errorf("%s: %w", failure, runErr)
+	}
+}
+
+// uploadFile transfers content to remotePath, showing a progress bar labeled
+// label on stderr if content is larger than uploadProgressThreshold.
+func uploadFile(client *sshexec.Client, content []byte, remotePath string, mode os.FileMode, label string) error {
+	if len(content) <= uploadProgressThreshold {
+		return client.TransferFile(content, remotePath, mode)
+	}
+
+	bar := ui.NewProgressBar(os.Stderr, label, int64(len(content)))
+	defer bar.Finish()
+	return client.TransferFile(content, remotePath, mode, sshexec.WithTransferProgress(bar))
+}
+
+// resolveExecEnv returns the variables to export for the command: those read
+// from envFile, if set, overridden by the --env flags in values.
+func resolveExecEnv(envFile string, values []string) (map[string]string, error) {
+	env, err := parseEnvFlags(values)
+	if err != nil {
+		return nil, err
+	}
+	if envFile == "" {
+		return env, nil
+	}
+
+	fromFile, err := envfile.Read(envFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --env-file: %w", err)
+	}
+	maps.Copy(fromFile, env)
+	return fromFile, nil
+}
+
+// parseEnvFlags parses repeatable --env KEY=VALUE flags into a map.
+func parseEnvFlags(values []string) (map[string]string, error) {
+	env, err := parseKeyValueFlags("env", values)
+	if err != nil {
+		return nil, err
+	}
+	for key := range env {
+		if !sshexec.ValidEnvName(key) {
+			return nil, fmt.Errorf("invalid --env value: %q is not a valid variable name", key)
+		}
+	}
+	return env, nil
+}
+
+// execResult is the outcome of running a command on one session with exec --all.
+type execResult struct {
+	sessionID string
+	exitCode  int
+	err       error
+}
+
+// runExecAll runs the command on every matching running session using a
+// bounded worker pool, then prints a summary of exit codes.
+func runExecAll(command string, env map[string]string) error {
+	if command == "" {
+		return fmt.Errorf("--all requires --command or a command after --")
+	}
+	if execParallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	filter, err := parseKeyValueFlags("filter", execFilters)
+	if err != nil {
+		return err
+	}
+
+	exports, err := sshexec.ExportEnv(env)
+	if err != nil {
+		return err
+	}
+	command = strings.TrimSpace(exports + " " + command)
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	// Drop sessions whose VMs were deleted outside sandctl
+	ctx, cancel := context.WithTimeout(context.Background(), vmCheckTimeout)
+	sessions = syncWithProviderAPI(ctx, sessions, store)
+	cancel()
+
+	var targets []session.Session
+	for _, sess := range sessions {
+		if sess.IsLegacySession() || sess.Status != session.StatusRunning || sessionAddress(&sess) == "" {
+			continue
+		}
+		if sess.MatchesLabels(filter) {
+			targets = append(targets, sess)
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No running sessions match.")
+		return nil
+	}
+
+	for i := range targets {
+		markSessionActive(store, &targets[i])
+	}
+
+	execCtx, cancelExec := execContext()
+	defer cancelExec()
+
+	results := make([]execResult, len(targets))
+	jobs := make(chan int)
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+
+	workers := min(execParallel, len(targets))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = execOnSession(execCtx, &targets[i], command, &outputMu)
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Print summary
+	failed := 0
+	fmt.Println()
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			failed++
+			fmt.Printf("%-18s error: %v\n", r.sessionID, r.err)
+		case r.exitCode != 0:
+			failed++
+			fmt.Printf("%-18s exit %d\n", r.sessionID, r.exitCode)
+		default:
+			fmt.Printf("%-18s exit 0\n", r.sessionID)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d sessions", failed, len(results))
+	}
+	return nil
+}
+
+// execOnSession runs command on a single session and prints its combined
+// output, prefixed with the session name, once the command finishes or ctx
+// is done.
+func execOnSession(ctx context.Context, sess *session.Session, command string, outputMu *sync.Mutex) execResult {
+	result := execResult{sessionID: sess.ID}
+
+	client, err := createSessionSSHClient(sess, sessionAddress(sess))
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer client.Close()
+
+	var output bytes.Buffer
+	runErr := client.ExecWithStreamsContext(ctx, command, nil, &output, &output)
+
+	var exitErr *ssh.ExitError
+	switch {
+	case runErr == nil:
+	case errors.Is(runErr, context.DeadlineExceeded):
+		result.err = fmt.Errorf("command timed out after %s", execTimeout)
+	case errors.As(runErr, &exitErr):
+		result.exitCode = exitErr.ExitStatus()
+	default:
+		result.err = runErr
+	}
+
+	outputMu.Lock()
+	fmt.Print(prefixLines(sess.ID, output.String()))
+	outputMu.Unlock()
+
+	return result
+}
+
+// prefixLines prefixes every line of output with "[name] ".
+func prefixLines(name, output string) string {
+	if output == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		fmt.Fprintf(&b, "[%s] %s\n", name, line)
+	}
+	return b.String()
+}
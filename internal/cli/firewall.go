@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// publicIPURL returns the caller's public IP address as plain text.
+var publicIPURL = "https://api.ipify.org"
+
+// publicIPTimeout bounds the public IP lookup for --open-port-my-ip.
+const publicIPTimeout = 10 * time.Second
+
+// detectPublicIP returns this machine's public IP address as seen from the internet.
+func detectPublicIP(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, publicIPTimeout)
+	defer cancel()
+
+	resp, err := httpGet(ctx, publicIPURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect public IP: %w", err)
+	}
+	defer resp.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp, 64))
+	if err != nil {
+		return "", fmt.Errorf("failed to detect public IP: %w", err)
+	}
+
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("failed to detect public IP: unexpected response %q", ip)
+	}
+	return ip, nil
+}
+
+// validatePorts checks that every port is a valid TCP port number.
+func validatePorts(ports []int) error {
+	for _, port := range ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid --open-port %d: must be between 1 and 65535", port)
+		}
+	}
+	return nil
+}
+
+// deleteSessionFirewall removes a session's firewall, if it has one.
+func deleteSessionFirewall(ctx context.Context, prov provider.Provider, firewallID string) error {
+	if firewallID == "" {
+		return nil
+	}
+
+	fm, ok := prov.(provider.FirewallManager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support firewalls", prov.Name())
+	}
+	return fm.DeleteFirewall(ctx, firewallID)
+}
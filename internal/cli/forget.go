@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/sshexec"
+)
+
+var forgetCmd = &cobra.Command{
+	Use:   "forget <ip>",
+	Short: "Remove a stored SSH host key",
+	Long: `Remove the SSH host key recorded for an IP address.
+
+sandctl records each VM's host key on first connect (~/.sandctl/known_hosts)
+and refuses to connect if it changes. Providers recycle IP addresses, so if
+a new VM gets the IP of an old one, remove the stale entry with this command.
+
+Keys are removed automatically when sandctl creates or destroys a session.`,
+	Example: `  # Forget the host key for an IP
+  sandctl forget 203.0.113.10`,
+	Args: cobra.ExactArgs(1),
+	RunE: runForget,
+}
+
+func init() {
+	rootCmd.AddCommand(forgetCmd)
+}
+
+func runForget(cmd *cobra.Command, args []string) error {
+	host := args[0]
+
+	removed, err := sshexec.ForgetHost(sshexec.DefaultKnownHostsPath(), host)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		fmt.Printf("No host key stored for %s.\n", host)
+		return nil
+	}
+
+	fmt.Printf("Removed host key for %s.\n", host)
+	return nil
+}
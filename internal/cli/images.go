@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+var imagesProvider string
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List available OS images",
+	Long: `List the OS images available for new sessions.
+
+Pass a NAME to 'sandctl new --image'. A distribution alias such as 'ubuntu'
+or 'debian' can be used instead and resolves to its newest image.`,
+	Example: `  # List images
+  sandctl images
+
+  # Create a session from the newest Debian image
+  sandctl new --image debian`,
+	Args: cobra.NoArgs,
+	RunE: runImages,
+}
+
+func init() {
+	imagesCmd.Flags().StringVarP(&imagesProvider, "provider", "p", "", "provider to query (default: from config)")
+
+	rootCmd.AddCommand(imagesCmd)
+}
+
+func runImages(cmd *cobra.Command, args []string) error {
+	prov, err := getProvider(imagesProvider)
+	if err != nil {
+		return err
+	}
+
+	images, err := listProviderImages(context.Background(), prov)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].OSFlavor != images[j].OSFlavor {
+			return images[i].OSFlavor < images[j].OSFlavor
+		}
+		if c := provider.CompareOSVersions(images[i].OSVersion, images[j].OSVersion); c != 0 {
+			return c > 0
+		}
+		return images[i].Architecture < images[j].Architecture
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tOS\tVERSION\tARCH\tDESCRIPTION")
+	for _, img := range images {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			img.Name, img.OSFlavor, img.OSVersion, img.Architecture, img.Description)
+	}
+	return w.Flush()
+}
+
+// listProviderImages lists images for providers that support it.
+func listProviderImages(ctx context.Context, prov provider.Provider) ([]provider.Image, error) {
+	lister, ok := prov.(provider.ImageLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support listing images", prov.Name())
+	}
+	return lister.ListImages(ctx)
+}
+
+// resolveImage returns image with a distribution alias (e.g., "ubuntu")
+// replaced by the newest matching image name. Other names are returned as is.
+func resolveImage(ctx context.Context, prov provider.Provider, image string) (string, error) {
+	if !provider.IsImageAlias(image) {
+		return image, nil
+	}
+
+	images, err := listProviderImages(ctx, prov)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve image alias '%s': %w", image, err)
+	}
+
+	name, ok := provider.ResolveImageAlias(images, image)
+	if !ok {
+		return "", fmt.Errorf("no image matches '%s'. Use 'sandctl images' to see available images", image)
+	}
+	verboseLog("Resolved image alias %s to %s", image, name)
+	return name, nil
+}
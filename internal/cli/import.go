@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var (
+	importName     string
+	importProvider string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <provider-id>",
+	Short: "Adopt an existing provider VM as a session",
+	Long: `Import a VM that was created outside sandctl so it can be managed
+with list, console, exec, and destroy.
+
+The VM is looked up by its provider-specific ID (for Hetzner, the numeric
+server ID). A session name is generated unless --name is given. VMs that are
+already tracked by a session are skipped.`,
+	Example: `  # Import a Hetzner server by ID
+  sandctl import 12345678
+
+  # Import with a specific session name
+  sandctl import 12345678 --name myproj`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importName, "name", "", "session name to use instead of a generated one")
+	importCmd.Flags().StringVarP(&importProvider, "provider", "p", "", "provider the VM belongs to (default: from config)")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	providerID := args[0]
+
+	prov, err := getProvider(importProvider)
+	if err != nil {
+		return err
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	if existing := findSessionByProviderID(sessions, prov.Name(), providerID); existing != nil {
+		fmt.Printf("VM %s is already tracked as session '%s'.\n", providerID, existing.ID)
+		return nil
+	}
+
+	vm, err := prov.Get(ctx, providerID)
+	if err != nil {
+		if errors.Is(err, provider.ErrNotFound) {
+			ui.PrintError(os.Stderr, "VM '%s' not found in %s", providerID, prov.Name())
+			return nil
+		}
+		return fmt.Errorf("failed to get VM: %w", err)
+	}
+
+	usedNames := make([]string, len(sessions))
+	for i, sess := range sessions {
+		usedNames[i] = sess.ID
+	}
+
+	sessionID, err := resolveSessionName(importName, usedNames)
+	if err != nil {
+		return err
+	}
+
+	sess := session.Session{
+		ID:         sessionID,
+		Status:     mapVMStatusToSession(vm.Status),
+		CreatedAt:  vm.CreatedAt,
+		Provider:   prov.Name(),
+		Profile:    cfg.ActiveProfile,
+		ProviderID: vm.ID,
+		IPAddress:  vm.IPAddress,
+		IPv6:       vm.IPv6,
+		SSHUser:    providerSSHUser(cfg, prov.Name()),
+		SSHPort:    providerSSHPort(cfg, prov.Name()),
+	}
+
+	if err := store.Add(sess); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	ui.PrintSuccess(os.Stdout, "Imported VM %s (%s) as session '%s'", vm.ID, vm.Name, sessionID)
+	return nil
+}
+
+// findSessionByProviderID returns the session tracking the given provider VM, or nil.
+func findSessionByProviderID(sessions []session.Session, providerName, providerID string) *session.Session {
+	for i := range sessions {
+		if sessions[i].Provider == providerName && sessions[i].ProviderID == providerID {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
@@ -1,22 +1,29 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
 	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/provider"
 	"github.com/sandctl/sandctl/internal/sshagent"
 	"github.com/sandctl/sandctl/internal/ui"
 )
 
+// credentialCheckTimeout bounds the API call made by --verify and doctor.
+const credentialCheckTimeout = 15 * time.Second
+
 var (
 	// Flags for non-interactive mode
 	initHetznerToken      string
@@ -30,6 +37,9 @@ var (
 	initGitUserName       string
 	initGitUserEmail      string
 	initGitHubToken       string
+	initVerify            bool
+	initRotate            bool
+	initPrint             bool
 )
 
 // initCmd represents the init command.
@@ -53,34 +63,76 @@ Press Enter to keep existing values, or type new ones to update.
 
 For non-interactive setup (CI/scripts), use flags:
   sandctl init --hetzner-token TOKEN --ssh-agent
-  sandctl init --hetzner-token TOKEN --ssh-public-key ~/.ssh/id_ed25519.pub`,
+  sandctl init --hetzner-token TOKEN --ssh-public-key ~/.ssh/id_ed25519.pub
+
+Add --verify to check the token against the provider API before saving.
+
+When switching to a new SSH key, add --rotate to upload it right away. The
+previous key is reported and can then be removed with 'sandctl keys prune'.
+
+To write the config file yourself, e.g. from CI, use --print to get a
+commented template with placeholder values:
+  sandctl init --print > ~/.sandctl/config && chmod 600 ~/.sandctl/config`,
 	RunE: runInit,
 }
 
 func init() {
 	rootCmd.AddCommand(initCmd)
 
-	// Non-interactive flags
-	initCmd.Flags().StringVar(&initHetznerToken, "hetzner-token", "", "Hetzner Cloud API token")
-	initCmd.Flags().StringVar(&initSSHPublicKey, "ssh-public-key", "", "Path to SSH public key (e.g., ~/.ssh/id_ed25519.pub)")
-	initCmd.Flags().BoolVar(&initSSHAgent, "ssh-agent", false, "Use SSH agent for key management (1Password, ssh-agent)")
-	initCmd.Flags().StringVar(&initSSHKeyFingerprint, "ssh-key-fingerprint", "", "SSH key fingerprint when using --ssh-agent with multiple keys")
-	initCmd.Flags().StringVar(&initRegion, "region", "", "Default Hetzner region (ash, hel1, fsn1, nbg1)")
-	initCmd.Flags().StringVar(&initServerType, "server-type", "", "Default server type (cpx21, cpx31, cpx41)")
-	initCmd.Flags().StringVar(&initOpencodeZenKey, "opencode-zen-key", "", "Opencode Zen key for AI access (optional)")
-	initCmd.Flags().StringVar(&initGitConfigPath, "git-config-path", "", "Path to gitconfig file to copy to sandboxes")
-	initCmd.Flags().StringVar(&initGitUserName, "git-user-name", "", "Git user.name for commits")
-	initCmd.Flags().StringVar(&initGitUserEmail, "git-user-email", "", "Git user.email for commits")
-	initCmd.Flags().StringVar(&initGitHubToken, "github-token", "", "GitHub personal access token for PR creation")
+	addInitFlags(initCmd)
+	initCmd.Flags().BoolVar(&initPrint, "print", false, "Print a commented config template to stdout instead of configuring")
+}
+
+// addInitFlags registers the non-interactive setup flags, which init shares
+// with config migrate.
+func addInitFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&initHetznerToken, "hetzner-token", "", "Hetzner Cloud API token")
+	cmd.Flags().StringVar(&initSSHPublicKey, "ssh-public-key", "", "Path to SSH public key (e.g., ~/.ssh/id_ed25519.pub)")
+	cmd.Flags().BoolVar(&initSSHAgent, "ssh-agent", false, "Use SSH agent for key management (1Password, ssh-agent)")
+	cmd.Flags().StringVar(&initSSHKeyFingerprint, "ssh-key-fingerprint", "", "SSH key fingerprint when using --ssh-agent with multiple keys")
+	cmd.Flags().StringVar(&initRegion, "region", "", "Default Hetzner region (ash, hel1, fsn1, nbg1)")
+	cmd.Flags().StringVar(&initServerType, "server-type", "", "Default server type (cpx21, cpx31, cpx41)")
+	cmd.Flags().StringVar(&initOpencodeZenKey, "opencode-zen-key", "", "Opencode Zen key for AI access (optional)")
+	cmd.Flags().StringVar(&initGitConfigPath, "git-config-path", "", "Path to gitconfig file to copy to sandboxes")
+	cmd.Flags().StringVar(&initGitUserName, "git-user-name", "", "Git user.name for commits")
+	cmd.Flags().StringVar(&initGitUserEmail, "git-user-email", "", "Git user.email for commits")
+	cmd.Flags().StringVar(&initGitHubToken, "github-token", "", "GitHub personal access token for PR creation")
+	cmd.Flags().BoolVar(&initRotate, "rotate", false, "Upload the new SSH key now and report the previous one for 'sandctl keys prune'")
+	cmd.Flags().BoolVar(&initVerify, "verify", false, "Verify the provider token before saving (non-interactive mode)")
 }
 
 // runInit executes the init command.
 func runInit(cmd *cobra.Command, args []string) error {
-	configPath := cfgFile
-	if configPath == "" {
-		configPath = config.DefaultConfigPath()
+	if initPrint {
+		fmt.Print(config.Template())
+		return nil
+	}
+
+	configPath, err := configFileForWrite()
+	if err != nil {
+		return err
+	}
+
+	if err := validateInitFlags(); err != nil {
+		return err
+	}
+
+	// Check if running non-interactively with flags
+	hasFlags := initHetznerToken != "" || initSSHPublicKey != "" || initSSHAgent
+	if hasFlags {
+		return runNonInteractiveInit(configPath)
 	}
 
+	// Check if we have a terminal for interactive mode
+	if !ui.IsTerminal() {
+		return errors.New("init requires a terminal for interactive mode, or use --hetzner-token with --ssh-agent or --ssh-public-key flags")
+	}
+
+	return runInitFlow(configPath, os.Stdin, os.Stdout)
+}
+
+// validateInitFlags checks the non-interactive setup flags for conflicts.
+func validateInitFlags() error {
 	// Validate mutually exclusive flags
 	if initSSHAgent && initSSHPublicKey != "" {
 		return errors.New("--ssh-agent and --ssh-public-key are mutually exclusive")
@@ -105,111 +157,184 @@ func runInit(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("git config file not found: %s", initGitConfigPath)
 		}
 	}
+	return nil
+}
 
-	// Check if running non-interactively with flags
-	hasFlags := initHetznerToken != "" || initSSHPublicKey != "" || initSSHAgent
-	if hasFlags {
-		return runNonInteractiveInit(configPath)
+// runNonInteractiveInit handles init with command-line flags.
+func runNonInteractiveInit(configPath string) error {
+	if err := requireInitFlags(); err != nil {
+		return err
 	}
 
-	// Check if we have a terminal for interactive mode
-	if !ui.IsTerminal() {
-		return errors.New("init requires a terminal for interactive mode, or use --hetzner-token with --ssh-agent or --ssh-public-key flags")
+	// Update the existing config, keeping whatever the flags don't set
+	previousCfg := loadExistingConfig(configPath)
+	cfg := initBaseConfig(previousCfg)
+
+	// Set defaults
+	existingHetzner := cfg.Providers["hetzner"]
+	region := firstNonEmpty(initRegion, existingHetzner.Region, "ash")
+	serverType := firstNonEmpty(initServerType, existingHetzner.ServerType, "cpx31")
+	setInitHetznerConfig(cfg, initHetznerToken, region, serverType)
+
+	sshCfg, err := sshKeyConfigFromFlags()
+	if err != nil {
+		return err
 	}
+	setSSHKeyConfig(cfg, sshCfg)
+	applyOptionalInitFlags(cfg)
 
-	return runInitFlow(configPath, os.Stdin, os.Stdout)
+	return saveInitConfig(configPath, previousCfg, cfg)
 }
 
-// runNonInteractiveInit handles init with command-line flags.
-func runNonInteractiveInit(configPath string) error {
-	// Validate all required flags are provided
+// requireInitFlags checks that the flags needed without prompts are given.
+func requireInitFlags() error {
 	if initHetznerToken == "" {
 		return errors.New("--hetzner-token is required in non-interactive mode")
 	}
 	if !initSSHAgent && initSSHPublicKey == "" {
 		return errors.New("--ssh-public-key or --ssh-agent is required in non-interactive mode")
 	}
+	return nil
+}
 
-	// Set defaults
-	region := initRegion
-	if region == "" {
-		region = "ash"
-	}
-	serverType := initServerType
-	if serverType == "" {
-		serverType = "cpx31"
-	}
-
-	// Build config
-	cfg := &config.Config{
-		DefaultProvider: "hetzner",
-		OpencodeZenKey:  initOpencodeZenKey,
-		Providers: map[string]config.ProviderConfig{
-			"hetzner": {
-				Token:      initHetznerToken,
-				Region:     region,
-				ServerType: serverType,
-				Image:      "ubuntu-24.04",
-			},
-		},
-	}
-
-	// Handle SSH key configuration
-	if initSSHAgent {
-		// SSH agent mode
-		agent, err := sshagent.New()
-		if err != nil {
-			return fmt.Errorf("failed to connect to SSH agent: %w", err)
+// sshKeyConfigFromFlags returns the SSH key selected by --ssh-agent and
+// --ssh-key-fingerprint, or --ssh-public-key.
+func sshKeyConfigFromFlags() (*sshKeyConfig, error) {
+	if !initSSHAgent {
+		sshKeyPath := expandPath(initSSHPublicKey)
+		if _, err := os.Stat(sshKeyPath); err != nil {
+			return nil, fmt.Errorf("SSH public key not found: %s", sshKeyPath)
 		}
-		defer agent.Close()
+		return &sshKeyConfig{source: "file", filePath: initSSHPublicKey}, nil
+	}
 
-		var key *sshagent.AgentKey
-		if initSSHKeyFingerprint != "" {
-			// Use specific key by fingerprint
-			key, err = agent.GetKeyByFingerprint(initSSHKeyFingerprint)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Use first available key
-			keys, err := agent.ListKeys()
-			if err != nil {
-				return err
-			}
-			key = &keys[0]
+	agent, err := sshagent.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SSH agent: %w", err)
+	}
+	defer agent.Close()
+
+	var key *sshagent.AgentKey
+	if initSSHKeyFingerprint != "" {
+		// Use specific key by fingerprint
+		key, err = agent.GetKeyByFingerprint(initSSHKeyFingerprint)
+		if err != nil {
+			return nil, err
 		}
+	} else {
+		// Use first available key
+		keys, err := agent.ListKeys()
+		if err != nil {
+			return nil, err
+		}
+		key = &keys[0]
+	}
 
+	return &sshKeyConfig{
+		source:      "agent",
+		publicKey:   strings.TrimSpace(key.PublicKey),
+		fingerprint: key.Fingerprint,
+	}, nil
+}
+
+// setSSHKeyConfig sets the SSH key of cfg to sshCfg.
+func setSSHKeyConfig(cfg *config.Config, sshCfg *sshKeyConfig) {
+	if sshCfg.source == "agent" {
 		cfg.SSHKeySource = "agent"
-		cfg.SSHPublicKeyInline = strings.TrimSpace(key.PublicKey)
-		cfg.SSHKeyFingerprint = key.Fingerprint
+		cfg.SSHPublicKeyInline = sshCfg.publicKey
+		cfg.SSHKeyFingerprint = sshCfg.fingerprint
 	} else {
-		// File mode
-		sshKeyPath := expandPath(initSSHPublicKey)
-		if _, err := os.Stat(sshKeyPath); err != nil {
-			return fmt.Errorf("SSH public key not found: %s", sshKeyPath)
-		}
-		cfg.SSHPublicKey = initSSHPublicKey
+		cfg.SSHPublicKey = sshCfg.filePath
+	}
+}
+
+// applyOptionalInitFlags sets the optional settings given as flags, keeping
+// the values in cfg for those that aren't.
+func applyOptionalInitFlags(cfg *config.Config) {
+	if initOpencodeZenKey != "" {
+		cfg.OpencodeZenKey = initOpencodeZenKey
 	}
 
-	// Handle git configuration
 	if initGitConfigPath != "" {
-		cfg.GitConfigPath = initGitConfigPath
+		setInitGitConfig(cfg, initGitConfigPath, "", "")
 	} else if initGitUserName != "" && initGitUserEmail != "" {
-		cfg.GitUserName = initGitUserName
-		cfg.GitUserEmail = initGitUserEmail
+		setInitGitConfig(cfg, "", initGitUserName, initGitUserEmail)
 	}
 
-	// Handle GitHub token
 	if initGitHubToken != "" {
 		cfg.GitHubToken = initGitHubToken
 	}
+}
+
+// saveInitConfig verifies the provider credentials if --verify is given,
+// saves cfg, and with --rotate uploads its SSH key in place of the one in
+// previousCfg.
+func saveInitConfig(configPath string, previousCfg, cfg *config.Config) error {
+	if initVerify {
+		if err := verifyProviderCredentials(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Credentials for %s verified.\n", cfg.DefaultProvider)
+	}
 
-	// Save config
 	if err := config.Save(configPath, cfg); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
 	fmt.Printf("Configuration saved to %s\n", configPath)
+
+	if initRotate {
+		return rotateSSHKey(previousCfg, cfg, os.Stdout)
+	}
+	return nil
+}
+
+// rotateSSHKey uploads the newly configured SSH key to the default provider
+// and reports the previously configured key, which 'sandctl keys prune' will
+// then delete.
+func rotateSSHKey(previousCfg, cfg *config.Config, output io.Writer) error {
+	prov, err := provider.Get(cfg.DefaultProvider, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keysTimeout)
+	defer cancel()
+
+	newKey, err := cfg.GetSSHPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to get SSH public key: %w", err)
+	}
+	if _, err := ensureSSHKey(ctx, prov, newKey); err != nil {
+		return fmt.Errorf("failed to upload SSH key: %w", err)
+	}
+	fmt.Fprintf(output, "Uploaded SSH key %s to %s.\n", sshKeyName(newKey), prov.Name())
+
+	if previousCfg == nil {
+		return nil
+	}
+	oldKey, err := previousCfg.GetSSHPublicKey()
+	if err != nil || samePublicKey(oldKey, newKey) {
+		return nil
+	}
+	fmt.Fprintf(output, "Previous key %s is no longer used. Run 'sandctl keys prune' to delete it.\n", sshKeyName(oldKey))
+	return nil
+}
+
+// verifyProviderCredentials checks the default provider's credentials with a
+// short-lived API call.
+func verifyProviderCredentials(cfg *config.Config) error {
+	prov, err := provider.Get(cfg.DefaultProvider, cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialCheckTimeout)
+	defer cancel()
+
+	if err := prov.VerifyCredentials(ctx); err != nil {
+		return fmt.Errorf("failed to verify %s credentials: %w", prov.Name(), err)
+	}
 	return nil
 }
 
@@ -280,41 +405,26 @@ func runInitFlow(configPath string, input io.Reader, output io.Writer) error {
 		return err
 	}
 
-	// Build config
-	cfg := &config.Config{
-		DefaultProvider: "hetzner",
-		OpencodeZenKey:  zenKey,
-		Providers: map[string]config.ProviderConfig{
-			"hetzner": {
-				Token:      hetznerToken,
-				Region:     region,
-				ServerType: serverType,
-				Image:      "ubuntu-24.04",
-			},
-		},
-	}
+	// Update the existing config with the answers, keeping everything else
+	cfg := initBaseConfig(existingCfg)
+	setInitHetznerConfig(cfg, hetznerToken, region, serverType)
+	cfg.OpencodeZenKey = zenKey
 
 	// Set SSH key configuration based on source
-	if sshCfg.source == "agent" {
-		cfg.SSHKeySource = "agent"
-		cfg.SSHPublicKeyInline = sshCfg.publicKey
-		cfg.SSHKeyFingerprint = sshCfg.fingerprint
-	} else {
-		cfg.SSHPublicKey = sshCfg.filePath
-	}
+	setSSHKeyConfig(cfg, sshCfg)
 
 	// Set git configuration
-	if gitCfgPath != "" {
-		cfg.GitConfigPath = gitCfgPath
-	} else if gitUserName != "" && gitUserEmail != "" {
-		cfg.GitUserName = gitUserName
-		cfg.GitUserEmail = gitUserEmail
+	switch {
+	case gitCfgPath != "":
+		setInitGitConfig(cfg, gitCfgPath, "", "")
+	case gitUserName != "" && gitUserEmail != "":
+		setInitGitConfig(cfg, "", gitUserName, gitUserEmail)
+	default:
+		setInitGitConfig(cfg, "", "", "")
 	}
 
 	// Set GitHub token
-	if githubToken != "" {
-		cfg.GitHubToken = githubToken
-	}
+	cfg.GitHubToken = githubToken
 
 	// Save config
 	if err := config.Save(configPath, cfg); err != nil {
@@ -325,6 +435,13 @@ func runInitFlow(configPath string, input io.Reader, output io.Writer) error {
 	fmt.Fprintln(output)
 	fmt.Fprintf(output, "Configuration saved successfully to %s\n", configPath)
 	fmt.Fprintln(output)
+
+	if initRotate {
+		if err := rotateSSHKey(existingCfg, cfg, output); err != nil {
+			return err
+		}
+		fmt.Fprintln(output)
+	}
 	fmt.Fprintln(output, "Next steps:")
 	fmt.Fprintln(output, "  sandctl new")
 	fmt.Fprintln(output)
@@ -332,6 +449,47 @@ func runInitFlow(configPath string, input io.Reader, output io.Writer) error {
 	return nil
 }
 
+// initBaseConfig returns the config init starts from: a copy of existing with
+// the SSH key cleared, so settings init doesn't ask about, such as profiles,
+// the session store, or provider networking, are kept. A legacy config, or
+// none, starts from scratch.
+func initBaseConfig(existing *config.Config) *config.Config {
+	cfg := &config.Config{}
+	if existing != nil && !existing.IsLegacyConfig() {
+		*cfg = *existing
+		cfg.Providers = maps.Clone(existing.Providers)
+	}
+	if cfg.Providers == nil {
+		cfg.Providers = make(map[string]config.ProviderConfig)
+	}
+
+	cfg.DefaultProvider = "hetzner"
+	cfg.SSHKeySource = ""
+	cfg.SSHPublicKey = ""
+	cfg.SSHPublicKeyInline = ""
+	cfg.SSHKeyFingerprint = ""
+	return cfg
+}
+
+// setInitHetznerConfig sets the Hetzner settings init asks for, keeping the
+// provider's other settings.
+func setInitHetznerConfig(cfg *config.Config, token, region, serverType string) {
+	pc := cfg.Providers["hetzner"]
+	pc.Token = token
+	pc.Region = region
+	pc.ServerType = serverType
+	pc.Image = firstNonEmpty(pc.Image, "ubuntu-24.04")
+	cfg.Providers["hetzner"] = pc
+}
+
+// setInitGitConfig replaces the git settings of cfg. Either path or both name
+// and email are set; all empty removes them.
+func setInitGitConfig(cfg *config.Config, path, name, email string) {
+	cfg.GitConfigPath = path
+	cfg.GitUserName = name
+	cfg.GitUserEmail = email
+}
+
 // loadExistingConfig attempts to load an existing config file.
 // Returns nil if no config exists or if it cannot be loaded.
 func loadExistingConfig(path string) *config.Config {
@@ -341,72 +499,16 @@ func loadExistingConfig(path string) *config.Config {
 		return cfg
 	}
 
-	// If validation failed, try loading the raw YAML to preserve values
+	// If validation failed, decode the YAML without validating it, so every
+	// setting is preserved. Unknown keys are ignored.
 	data, readErr := os.ReadFile(path)
 	if readErr != nil {
 		return nil
 	}
 
-	// Use a map to read all fields including old ones
-	var rawCfg map[string]interface{}
-	if yaml.Unmarshal(data, &rawCfg) != nil {
-		return nil
-	}
-
-	// Extract fields
 	var c config.Config
-
-	// Legacy fields
-	if token, ok := rawCfg["sprites_token"].(string); ok {
-		c.SpritesToken = token
-	}
-	if zenKey, ok := rawCfg["opencode_zen_key"].(string); ok {
-		c.OpencodeZenKey = zenKey
-	}
-
-	// New fields
-	if dp, ok := rawCfg["default_provider"].(string); ok {
-		c.DefaultProvider = dp
-	}
-	if sshKey, ok := rawCfg["ssh_public_key"].(string); ok {
-		c.SSHPublicKey = sshKey
-	}
-
-	// Providers (complex structure)
-	if providers, ok := rawCfg["providers"].(map[string]interface{}); ok {
-		c.Providers = make(map[string]config.ProviderConfig)
-		for name, provRaw := range providers {
-			if prov, ok := provRaw.(map[string]interface{}); ok {
-				pc := config.ProviderConfig{}
-				if token, ok := prov["token"].(string); ok {
-					pc.Token = token
-				}
-				if region, ok := prov["region"].(string); ok {
-					pc.Region = region
-				}
-				if st, ok := prov["server_type"].(string); ok {
-					pc.ServerType = st
-				}
-				if img, ok := prov["image"].(string); ok {
-					pc.Image = img
-				}
-				c.Providers[name] = pc
-			}
-		}
-	}
-
-	// Git configuration fields
-	if gitConfigPath, ok := rawCfg["git_config_path"].(string); ok {
-		c.GitConfigPath = gitConfigPath
-	}
-	if gitUserName, ok := rawCfg["git_user_name"].(string); ok {
-		c.GitUserName = gitUserName
-	}
-	if gitUserEmail, ok := rawCfg["git_user_email"].(string); ok {
-		c.GitUserEmail = gitUserEmail
-	}
-	if githubToken, ok := rawCfg["github_token"].(string); ok {
-		c.GitHubToken = githubToken
+	if yaml.Unmarshal(data, &c) != nil {
+		return nil
 	}
 
 	// Only return if we found at least one field
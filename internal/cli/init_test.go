@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/sandctl/sandctl/internal/config"
 )
 
 // TestExpandPath_GivenTildePath_ThenExpandsHome tests tilde expansion.
@@ -241,3 +243,188 @@ providers:
 		t.Error("config should NOT be detected as legacy")
 	}
 }
+
+// TestRunConfigMigrate_GivenLegacyConfigAndFlags_ThenMigratesAndBacksUp tests non-interactive migration.
+func TestRunConfigMigrate_GivenLegacyConfigAndFlags_ThenMigratesAndBacksUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	sshKeyPath := filepath.Join(tmpDir, "id_ed25519.pub")
+	if err := os.WriteFile(sshKeyPath, []byte("ssh-ed25519 AAAA... test@example.com"), 0644); err != nil {
+		t.Fatalf("failed to create SSH key file: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config")
+	legacyContent := `sprites_token: "old-sprites-token"
+opencode_zen_key: "old-zen-key"
+`
+	if err := os.WriteFile(configPath, []byte(legacyContent), 0600); err != nil {
+		t.Fatalf("failed to create legacy config: %v", err)
+	}
+
+	// Save and restore global flag state
+	oldCfgFile := cfgFile
+	oldToken := initHetznerToken
+	oldKey := initSSHPublicKey
+	oldZenKey := initOpencodeZenKey
+	defer func() {
+		cfgFile = oldCfgFile
+		initHetznerToken = oldToken
+		initSSHPublicKey = oldKey
+		initOpencodeZenKey = oldZenKey
+	}()
+
+	cfgFile = configPath
+	initHetznerToken = "test-token"
+	initSSHPublicKey = sshKeyPath
+	initOpencodeZenKey = ""
+
+	if err := runConfigMigrate(configMigrateCmd, nil); err != nil {
+		t.Fatalf("runConfigMigrate error: %v", err)
+	}
+
+	backup, err := os.ReadFile(configPath + ".bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if string(backup) != legacyContent {
+		t.Errorf("backup = %q, want original config", backup)
+	}
+
+	cfg := loadExistingConfig(configPath)
+	if cfg == nil {
+		t.Fatal("migrated config could not be loaded")
+	}
+	if cfg.IsLegacyConfig() {
+		t.Error("migrated config should not be legacy")
+	}
+	if cfg.OpencodeZenKey != "old-zen-key" {
+		t.Errorf("OpencodeZenKey = %q, want old-zen-key", cfg.OpencodeZenKey)
+	}
+	if cfg.SpritesToken != "" {
+		t.Errorf("SpritesToken = %q, want it dropped", cfg.SpritesToken)
+	}
+	if hetzner, ok := cfg.GetProviderConfig("hetzner"); !ok || hetzner.Token != "test-token" {
+		t.Errorf("hetzner provider = %+v, want token test-token", hetzner)
+	}
+}
+
+// TestRunConfigMigrate_GivenLegacyGitSettingsAndFlags_ThenKeepsGitSettings tests
+// that non-interactive migration carries over the git settings.
+func TestRunConfigMigrate_GivenLegacyGitSettingsAndFlags_ThenKeepsGitSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	sshKeyPath := filepath.Join(tmpDir, "id_ed25519.pub")
+	if err := os.WriteFile(sshKeyPath, []byte("ssh-ed25519 AAAA... test@example.com"), 0644); err != nil {
+		t.Fatalf("failed to create SSH key file: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config")
+	legacyContent := `sprites_token: "old-sprites-token"
+opencode_zen_key: "old-zen-key"
+git_user_name: "Dev"
+git_user_email: "dev@example.com"
+github_token: "ghp_old"
+`
+	if err := os.WriteFile(configPath, []byte(legacyContent), 0600); err != nil {
+		t.Fatalf("failed to create legacy config: %v", err)
+	}
+
+	oldCfgFile := cfgFile
+	oldToken := initHetznerToken
+	oldKey := initSSHPublicKey
+	defer func() {
+		cfgFile = oldCfgFile
+		initHetznerToken = oldToken
+		initSSHPublicKey = oldKey
+	}()
+
+	cfgFile = configPath
+	initHetznerToken = "test-token"
+	initSSHPublicKey = sshKeyPath
+
+	if err := runConfigMigrate(configMigrateCmd, nil); err != nil {
+		t.Fatalf("runConfigMigrate error: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("migrated config could not be loaded: %v", err)
+	}
+	if cfg.GitUserName != "Dev" || cfg.GitUserEmail != "dev@example.com" || cfg.GitHubToken != "ghp_old" {
+		t.Errorf("git settings = %q <%q>, github_token = %q, want them carried over", cfg.GitUserName, cfg.GitUserEmail, cfg.GitHubToken)
+	}
+	if cfg.OpencodeZenKey != "old-zen-key" {
+		t.Errorf("OpencodeZenKey = %q, want old-zen-key", cfg.OpencodeZenKey)
+	}
+}
+
+// TestRunNonInteractiveInit_GivenExistingConfig_ThenKeepsOtherSettings tests
+// that re-running init only replaces the settings it manages.
+func TestRunNonInteractiveInit_GivenExistingConfig_ThenKeepsOtherSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	sshKeyPath := filepath.Join(tmpDir, "id_ed25519.pub")
+	if err := os.WriteFile(sshKeyPath, []byte("ssh-ed25519 AAAA... test@example.com"), 0644); err != nil {
+		t.Fatalf("failed to create SSH key file: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config")
+	existing := &config.Config{
+		DefaultProvider: "hetzner",
+		SSHPublicKey:    "~/.ssh/old.pub",
+		SSHUser:         "ubuntu",
+		SessionStore:    "sqlite",
+		IgnoreSSHConfig: true,
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "old-token", Region: "hel1", Network: "internal", SSHPort: 2222},
+		},
+		Profiles: map[string]config.Profile{
+			"work": {Providers: map[string]config.ProviderConfig{"hetzner": {Token: "work-token"}}},
+		},
+		ActiveProfile: "work",
+		GitUserName:   "Dev",
+		GitUserEmail:  "dev@example.com",
+	}
+	if err := config.Save(configPath, existing); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	oldToken := initHetznerToken
+	oldKey := initSSHPublicKey
+	oldRegion := initRegion
+	oldServerType := initServerType
+	defer func() {
+		initHetznerToken = oldToken
+		initSSHPublicKey = oldKey
+		initRegion = oldRegion
+		initServerType = oldServerType
+	}()
+
+	initHetznerToken = "new-token"
+	initSSHPublicKey = sshKeyPath
+	initRegion = ""
+	initServerType = ""
+
+	if err := runNonInteractiveInit(configPath); err != nil {
+		t.Fatalf("runNonInteractiveInit error: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	pc := cfg.Providers["hetzner"]
+	if pc.Token != "new-token" || cfg.SSHPublicKey != sshKeyPath {
+		t.Errorf("token = %q, ssh_public_key = %q, want the values from the flags", pc.Token, cfg.SSHPublicKey)
+	}
+	if pc.Region != "hel1" || pc.Network != "internal" || pc.SSHPort != 2222 {
+		t.Errorf("hetzner config = %+v, want region, network, and ssh_port kept", pc)
+	}
+	if _, ok := cfg.Profiles["work"]; !ok || cfg.ActiveProfile != "work" {
+		t.Errorf("profiles = %v, active_profile = %q, want the work profile kept", cfg.Profiles, cfg.ActiveProfile)
+	}
+	if cfg.SessionStore != "sqlite" || !cfg.IgnoreSSHConfig || cfg.SSHUser != "ubuntu" {
+		t.Errorf("session_store = %q, ignore_ssh_config = %v, ssh_user = %q, want them kept", cfg.SessionStore, cfg.IgnoreSSHConfig, cfg.SSHUser)
+	}
+	if cfg.GitUserName != "Dev" || cfg.GitUserEmail != "dev@example.com" {
+		t.Errorf("git user = %q <%q>, want it kept", cfg.GitUserName, cfg.GitUserEmail)
+	}
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var inspectJSON bool
+
+// inspectNoteWidth is the longest note shown by inspect; use 'sandctl note'
+// to print it in full.
+const inspectNoteWidth = 60
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <name>",
+	Short: "Show detailed information about a session",
+	Long: `Show the local session record together with the VM details reported by
+the provider (server type, region, datacenter, addresses).
+
+If the provider cannot be reached, only the local session fields are shown.`,
+	Example: `  # Show session details
+  sandctl inspect alice
+
+  # Output as JSON or YAML
+  sandctl inspect alice --json
+  sandctl inspect alice -o yaml`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().BoolVar(&inspectJSON, "json", false, "output as JSON (same as --output json)")
+
+	rootCmd.AddCommand(inspectCmd)
+}
+
+// inspectVM is the JSON representation of the provider VM details.
+type inspectVM struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	IPv6       string    `json:"ipv6,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Region     string    `json:"region,omitempty"`
+	Datacenter string    `json:"datacenter,omitempty"`
+	ServerType string    `json:"server_type,omitempty"`
+}
+
+// inspectOutput is the document printed by inspect in JSON or YAML format.
+type inspectOutput struct {
+	Session              *session.Session `json:"session"`
+	TimeoutRemaining     string           `json:"timeout_remaining,omitempty"`
+	ProvisioningDuration string           `json:"provisioning_duration,omitempty"`
+	VM                   *inspectVM       `json:"vm,omitempty"`
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	// Normalize the session name (case-insensitive)
+	sessionName := session.NormalizeName(args[0])
+
+	// Validate session name format
+	if !session.ValidateID(sessionName) {
+		return fmt.Errorf("invalid session name format: %s", args[0])
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	sess, err := store.Get(sessionName)
+	if err != nil {
+		var notFound *session.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.PrintError(os.Stderr, "session '%s' not found", sessionName)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, "Run 'sandctl list' to see available sessions.")
+			return nil
+		}
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	vm := fetchSessionVM(context.Background(), sess)
+
+	format := output
+	if inspectJSON {
+		format = ui.FormatJSON
+	}
+	return outputInspect(os.Stdout, format, sess, vm)
+}
+
+// fetchSessionVM returns the provider VM backing a session, or nil if it
+// cannot be retrieved.
+func fetchSessionVM(ctx context.Context, sess *session.Session) *provider.VM {
+	if sess.IsLegacySession() || sess.ProviderID == "" {
+		return nil
+	}
+
+	prov, err := getProviderFromSession(sess)
+	if err != nil {
+		logger.Warn("failed to get provider", "provider", sess.Provider, "error", err)
+		return nil
+	}
+
+	vm, err := prov.Get(ctx, sess.ProviderID)
+	if err != nil {
+		logger.Warn("failed to get VM", "session", sess.ID, "error", err)
+		return nil
+	}
+	return vm
+}
+
+// outputInspect writes the session and VM details in the given output format.
+func outputInspect(w io.Writer, format string, sess *session.Session, vm *provider.VM) error {
+	out := inspectOutput{Session: sess}
+	if remaining := sess.TimeoutRemaining(); remaining != nil {
+		out.TimeoutRemaining = remaining.Round(time.Second).String()
+	}
+	if d := sess.ProvisioningDuration(); d != nil {
+		out.ProvisioningDuration = d.Round(time.Second).String()
+	}
+	if vm != nil {
+		out.VM = &inspectVM{
+			ID:         vm.ID,
+			Name:       vm.Name,
+			Status:     string(vm.Status),
+			IPAddress:  vm.IPAddress,
+			IPv6:       vm.IPv6,
+			CreatedAt:  vm.CreatedAt,
+			Region:     vm.Region,
+			Datacenter: vm.Datacenter,
+			ServerType: vm.ServerType,
+		}
+	}
+
+	return ui.Render(w, format, out, func(w io.Writer) error {
+		printInspect(w, sess, vm)
+		return nil
+	})
+}
+
+// printInspect writes the session and VM details as a readable block.
+func printInspect(w io.Writer, sess *session.Session, vm *provider.VM) {
+	providerName := sess.Provider
+	if providerName == "" {
+		providerName = "(legacy)"
+	}
+
+	fmt.Fprintf(w, "Session:      %s\n", sess.ID)
+	fmt.Fprintf(w, "Status:       %s\n", sess.Status)
+	if sess.FailureReason != "" {
+		fmt.Fprintf(w, "Failure:      %s\n", sess.FailureReason)
+	}
+	fmt.Fprintf(w, "Provider:     %s\n", providerName)
+	if sess.Profile != "" {
+		fmt.Fprintf(w, "Profile:      %s\n", sess.Profile)
+	}
+	fmt.Fprintf(w, "Created:      %s\n", formatCreatedTime(sess.CreatedAt))
+	fmt.Fprintf(w, "Provisioning: %s\n", formatProvisioning(sess))
+	if sess.IsArchived() {
+		fmt.Fprintf(w, "Archived:     %s\n", formatCreatedTime(*sess.ArchivedAt))
+	}
+	fmt.Fprintf(w, "Timeout:      %s\n", formatTimeout(sess.TimeoutRemaining()))
+	fmt.Fprintf(w, "Labels:       %s\n", formatLabels(sess.Labels))
+	fmt.Fprintf(w, "Open Ports:   %s\n", formatPorts(sess.OpenPorts))
+	fmt.Fprintf(w, "Volume:       %s\n", formatVolume(sess))
+	fmt.Fprintf(w, "Notes:        %s\n", valueOrDash(truncateNote(sess.Notes, inspectNoteWidth)))
+
+	fmt.Fprintln(w)
+	if vm == nil {
+		fmt.Fprintln(w, "VM details unavailable.")
+		return
+	}
+
+	fmt.Fprintf(w, "VM ID:        %s\n", vm.ID)
+	fmt.Fprintf(w, "VM Status:    %s\n", vm.Status)
+	fmt.Fprintf(w, "Server Type:  %s\n", valueOrDash(vm.ServerType))
+	fmt.Fprintf(w, "Region:       %s\n", valueOrDash(vm.Region))
+	fmt.Fprintf(w, "Datacenter:   %s\n", valueOrDash(vm.Datacenter))
+	fmt.Fprintf(w, "IPv4:         %s\n", valueOrDash(vm.IPAddress))
+	fmt.Fprintf(w, "IPv6:         %s\n", valueOrDash(vm.IPv6))
+	fmt.Fprintf(w, "VM Created:   %s\n", formatCreatedTime(vm.CreatedAt))
+}
+
+// formatLabels formats labels as sorted key=value pairs.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// formatPorts formats a list of ports as a comma-separated string.
+func formatPorts(ports []int) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+
+	strs := make([]string, len(ports))
+	for i, p := range ports {
+		strs[i] = strconv.Itoa(p)
+	}
+	return strings.Join(strs, ", ")
+}
+
+// formatVolume formats a session's data volume for display.
+func formatVolume(sess *session.Session) string {
+	if sess.VolumeID == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d GB at %s)", sess.VolumeID, sess.VolumeSizeGB, volumeMountPoint)
+}
+
+// truncateNote flattens a note onto one line and shortens it to at most
+// width characters.
+func truncateNote(note string, width int) string {
+	note = strings.Join(strings.Fields(note), " ")
+	runes := []rune(note)
+	if len(runes) <= width {
+		return note
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// valueOrDash returns s, or "-" if it is empty.
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/sshagent"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+// sshKeyPrefix is the name prefix of provider SSH keys uploaded by sandctl.
+const sshKeyPrefix = "sandctl-"
+
+// keysTimeout bounds the provider calls made by keys subcommands.
+const keysTimeout = 2 * time.Minute
+
+var (
+	keysProvider   string
+	keysPruneYes   bool
+	keysPruneCheck bool
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage SSH keys uploaded to the provider",
+	Long: `Manage the SSH public keys sandctl uploads to the provider.
+
+sandctl uploads your configured public key as "sandctl-<hash>" the first time
+you create a session. After rotating your key, old uploads remain at the
+provider until pruned.`,
+}
+
+var keysPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete provider SSH keys that no longer match your local key",
+	Long: `Delete sandctl-* SSH keys at the provider that don't match the public key
+in your configuration.
+
+The keys to be deleted are listed before anything is removed. Running
+sessions keep working; the key is only used when creating new VMs.`,
+	Example: `  # Show which keys would be deleted
+  sandctl keys prune --dry-run
+
+  # Delete stale keys without prompting
+  sandctl keys prune --yes`,
+	Args: cobra.NoArgs,
+	RunE: runKeysPrune,
+}
+
+func init() {
+	keysCmd.PersistentFlags().StringVarP(&keysProvider, "provider", "p", "", "provider to manage keys for (default: from config)")
+	keysPruneCmd.Flags().BoolVar(&keysPruneCheck, "dry-run", false, "only list the keys that would be deleted")
+	keysPruneCmd.Flags().BoolVarP(&keysPruneYes, "yes", "y", false, "skip confirmation prompt")
+
+	keysCmd.AddCommand(keysPruneCmd)
+	rootCmd.AddCommand(keysCmd)
+}
+
+func runKeysPrune(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	localKey, err := cfg.GetSSHPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to get SSH public key: %w", err)
+	}
+
+	prov, err := getProvider(keysProvider)
+	if err != nil {
+		return err
+	}
+	keyManager, ok := prov.(provider.SSHKeyManager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support SSH key management", prov.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keysTimeout)
+	defer cancel()
+
+	keys, err := keyManager.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	stale := staleSSHKeys(keys, localKey)
+	if len(stale) == 0 {
+		fmt.Println("No stale SSH keys found.")
+		return nil
+	}
+
+	fmt.Printf("Stale SSH keys at %s:\n", prov.Name())
+	for _, key := range stale {
+		fmt.Printf("  %s (ID %s)\n", key.Name, key.ID)
+	}
+	fmt.Println()
+
+	if keysPruneCheck {
+		return nil
+	}
+
+	if !keysPruneYes {
+		confirmed, err := ui.Confirm(os.Stdin, os.Stdout, fmt.Sprintf("Delete %d SSH keys?", len(stale)))
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Canceled.")
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, key := range stale {
+		if err := keyManager.DeleteKey(ctx, key.ID); err != nil {
+			failed++
+			ui.PrintError(os.Stderr, "failed to delete %s: %v", key.Name, err)
+			continue
+		}
+		fmt.Printf("Deleted %s\n", key.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d SSH keys", failed, len(stale))
+	}
+	return nil
+}
+
+// staleSSHKeys returns the sandctl-managed keys that don't match localKey.
+func staleSSHKeys(keys []provider.SSHKey, localKey string) []provider.SSHKey {
+	var stale []provider.SSHKey
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Name, sshKeyPrefix) {
+			continue
+		}
+		if key.Name == sshKeyName(localKey) || samePublicKey(key.PublicKey, localKey) {
+			continue
+		}
+		stale = append(stale, key)
+	}
+	return stale
+}
+
+// samePublicKey reports whether two authorized_keys entries hold the same key,
+// ignoring comments.
+func samePublicKey(a, b string) bool {
+	af, bf := strings.Fields(a), strings.Fields(b)
+	if len(af) < 2 || len(bf) < 2 {
+		return false
+	}
+	return af[0] == bf[0] && af[1] == bf[1]
+}
+
+// sessionSSHKey is a key given with 'new --ssh-key' in place of the configured one.
+type sessionSSHKey struct {
+	PublicKey      string // Full public key in authorized_keys format
+	Fingerprint    string // SHA256 fingerprint
+	PrivateKeyPath string // Private key file; empty if the key is only in the agent
+}
+
+// resolveSSHKeyOverride loads the key named by value, which is either an agent
+// key fingerprint ("SHA256:...") or a public or private key file, and checks
+// that the matching private key is available.
+func resolveSSHKeyOverride(value string) (*sessionSSHKey, error) {
+	if strings.HasPrefix(value, "SHA256:") {
+		a, err := sshagent.New()
+		if err != nil {
+			return nil, err
+		}
+		defer a.Close()
+
+		agentKey, err := a.GetKeyByFingerprint(value)
+		if err != nil {
+			return nil, err
+		}
+		return &sessionSSHKey{PublicKey: agentKey.PublicKey, Fingerprint: agentKey.Fingerprint}, nil
+	}
+
+	path := expandPath(value)
+	pubPath, privPath := path+".pub", path
+	if strings.HasSuffix(path, ".pub") {
+		pubPath, privPath = path, strings.TrimSuffix(path, ".pub")
+	}
+
+	data, err := os.ReadFile(pubPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH public key: %w", err)
+	}
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH public key %s: %w", pubPath, err)
+	}
+
+	key := &sessionSSHKey{
+		PublicKey:   strings.TrimSpace(string(data)),
+		Fingerprint: ssh.FingerprintSHA256(pubKey),
+	}
+
+	// The private key may be held only by the agent
+	if _, err := os.Stat(privPath); err == nil {
+		key.PrivateKeyPath = privPath
+	} else if _, err := sshagent.GetSignerByFingerprint(key.Fingerprint); err != nil {
+		return nil, fmt.Errorf("no private key for %s: %s does not exist and the key is not in the SSH agent", pubPath, privPath)
+	}
+	return key, nil
+}
@@ -2,20 +2,41 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/sandctl/sandctl/internal/provider"
 	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+// defaultWatchInterval is how often list --watch refreshes.
+const defaultWatchInterval = 5 * time.Second
+
+// ANSI escape sequences used by list --watch.
+const (
+	clearScreen = "\033[H\033[2J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
 )
 
 var (
-	listFormat string
-	listAll    bool
+	listFormat   string
+	listAll      bool
+	listArchived bool
+	listFilters  []string
+	listWatch    bool
+	listInterval time.Duration
+	listSince    string
+	listBefore   string
+	listLong     bool
 )
 
 var listCmd = &cobra.Command{
@@ -24,87 +45,287 @@ var listCmd = &cobra.Command{
 	Long: `Display all active sandctl sessions.
 
 By default, only shows sessions in provisioning or running state.
-Use --all to include stopped and failed sessions.
+Use --all to include stopped and failed sessions, and --archived to show
+only sessions moved into the history with 'sandctl archive'.
+
+This command syncs with the provider API to show current VM status. VM
+state fetched in the last few seconds is reused; use --refresh to bypass it.
+
+Use --since and --before to only show sessions created in a time range.
+Each takes a duration ago, such as 24h, or an RFC3339 time.
 
-This command syncs with the provider API to show current VM status.`,
+Use --long to add how long each session took to provision.
+
+Use --watch to refresh the table every --interval until no session is
+still provisioning, or until Ctrl-C. When stdout is not a terminal the
+table is printed once.`,
 	Example: `  # List active sessions
   sandctl list
 
   # List all sessions including stopped
   sandctl list --all
 
+  # Show archived sessions
+  sandctl list --archived
+
+  # Only show sessions with matching labels
+  sandctl list --filter project=ghost
+
+  # Sessions created in the last day, including stopped ones
+  sandctl list --all --since 24h
+
+  # Include provisioning times
+  sandctl list --long
+
   # Output as JSON
-  sandctl list --format json`,
+  sandctl list -o json
+
+  # Refresh every 10 seconds while sessions provision
+  sandctl list --watch --interval 10s`,
 	Aliases: []string{"ls"},
 	RunE:    runList,
 }
 
 func init() {
 	listCmd.Flags().StringVarP(&listFormat, "format", "f", "table", "output format: table, json")
+	_ = listCmd.Flags().MarkDeprecated("format", "use --output instead")
 	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "include stopped/failed sessions")
+	listCmd.Flags().BoolVar(&listArchived, "archived", false, "show only archived sessions")
+	listCmd.MarkFlagsMutuallyExclusive("all", "archived")
+	listCmd.Flags().StringArrayVar(&listFilters, "filter", nil, "only show sessions with this label (key=value, repeatable)")
+	listCmd.Flags().StringVar(&listSince, "since", "", "only show sessions created after this time (duration ago like 24h, or RFC3339)")
+	listCmd.Flags().StringVar(&listBefore, "before", "", "only show sessions created before this time (duration ago like 24h, or RFC3339)")
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "include how long each session took to provision")
+	listCmd.Flags().BoolVarP(&listWatch, "watch", "w", false, "refresh the table until all sessions finish provisioning")
+	listCmd.Flags().DurationVar(&listInterval, "interval", defaultWatchInterval, "with --watch, time between refreshes")
 
 	rootCmd.AddCommand(listCmd)
 }
 
 func runList(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	store := getSessionStore()
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseKeyValueFlags("filter", listFilters)
+	if err != nil {
+		return err
+	}
+
+	created, err := parseCreatedRange(listSince, listBefore, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// The deprecated --format flag takes precedence when given
+	format := output
+	if cmd.Flags().Changed("format") {
+		format = listFormat
+		if err := ui.ValidateFormat(format); err != nil {
+			return err
+		}
+	}
+
+	if listWatch {
+		if format != ui.FormatTable {
+			return fmt.Errorf("--watch only supports the table format")
+		}
+		if listInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		// Without a terminal, fall through and print once
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			return watchList(store, filter, created, listInterval)
+		}
+	}
+
+	sessions, err := loadListSessions(ctx, store, filter, created)
+	if err != nil {
+		return err
+	}
+
+	// Handle empty state
+	if len(sessions) == 0 && format == ui.FormatTable {
+		fmt.Println("No active sessions.")
+		fmt.Println()
+		fmt.Println("Use 'sandctl new' to create one.")
+		return nil
+	}
 
+	return ui.Render(os.Stdout, format, sessions, func(w io.Writer) error {
+		return outputTable(w, sessions, listLong)
+	})
+}
+
+// loadListSessions returns the sessions to show, synced with the provider
+// and narrowed by the label filter and creation time range.
+func loadListSessions(ctx context.Context, store session.Store, filter map[string]string, created createdRange) ([]session.Session, error) {
 	// Get sessions from local store
 	var sessions []session.Session
 	var err error
 
-	if listAll {
+	switch {
+	case listArchived:
+		sessions, err = store.ListArchived()
+	case listAll:
 		sessions, err = store.List()
-	} else {
+	default:
 		sessions, err = store.ListActive()
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to list sessions: %w", err)
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
 
 	// Sync with provider API
 	sessions = syncWithProviderAPI(ctx, sessions, store)
 
-	// Handle empty state
-	if len(sessions) == 0 {
-		fmt.Println("No active sessions.")
-		fmt.Println()
-		fmt.Println("Use 'sandctl new' to create one.")
-		return nil
+	// Apply label filter
+	if len(filter) > 0 {
+		sessions = filterByLabels(sessions, filter)
 	}
 
-	// Output in requested format
-	switch listFormat {
-	case "json":
-		return outputJSON(sessions)
-	case "table":
-		return outputTable(sessions)
-	default:
-		return fmt.Errorf("unknown format: %s (valid: table, json)", listFormat)
+	// Apply creation time filter
+	if !created.isZero() {
+		sessions = filterByCreated(sessions, created)
+	}
+
+	return sessions, nil
+}
+
+// watchList redraws the session table every interval until no session is
+// provisioning or the user interrupts. Terminal resizes trigger a redraw.
+func watchList(store session.Store, filter map[string]string, created createdRange, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sigwinch := make(chan os.Signal, 1)
+	signal.Notify(sigwinch, syscall.SIGWINCH)
+	defer signal.Stop(sigwinch)
+
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Every redraw should show the provider's current state
+	refresh = true
+
+	var sessions []session.Session
+	reload := true
+	for {
+		if reload {
+			loaded, err := loadListSessions(ctx, store, filter, created)
+			if err != nil {
+				return err
+			}
+			sessions = loaded
+		}
+
+		fmt.Print(clearScreen)
+		fmt.Printf("Every %s: sandctl list    %s\n\n", interval, time.Now().Format("15:04:05"))
+		if len(sessions) == 0 {
+			fmt.Println("No active sessions.")
+		} else if err := outputTable(os.Stdout, sessions, listLong); err != nil {
+			return err
+		}
+
+		if !anyProvisioning(sessions) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sigwinch:
+			reload = false
+		case <-ticker.C:
+			reload = true
+		}
+	}
+}
+
+// anyProvisioning reports whether any session is still provisioning.
+func anyProvisioning(sessions []session.Session) bool {
+	for _, sess := range sessions {
+		if sess.Status == session.StatusProvisioning {
+			return true
+		}
+	}
+	return false
+}
+
+// listProviderVMs returns prov's VMs, cached under cacheKey. A listing cached
+// in the last few seconds is used unless --refresh is set or it lacks one of
+// the VMs in ids, which means the VM was created after the listing. Listings
+// fetched from the API are cached for the next command.
+func listProviderVMs(ctx context.Context, prov provider.Provider, cacheKey string, ids []string) ([]*provider.VM, error) {
+	cache := getVMCache()
+	if !refresh {
+		if vms, ok := cache.Get(cacheKey); ok && containsVMs(vms, ids) {
+			verboseLog("Using cached VM list for %s", cacheKey)
+			return vms, nil
+		}
+	}
+
+	vms, err := prov.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Put(cacheKey, vms); err != nil {
+		verboseLog("Failed to cache VM list for %s: %v", cacheKey, err)
+	}
+	return vms, nil
+}
+
+// containsVMs reports whether every ID in ids is in vms.
+func containsVMs(vms []*provider.VM, ids []string) bool {
+	known := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		known[vm.ID] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return false
+		}
 	}
+	return true
 }
 
 // syncWithProviderAPI updates local session statuses from provider APIs.
-func syncWithProviderAPI(ctx context.Context, sessions []session.Session, store *session.Store) []session.Session {
-	// Group sessions by provider
-	byProvider := make(map[string][]int) // provider name -> session indices
+func syncWithProviderAPI(ctx context.Context, sessions []session.Session, store session.Store) []session.Session {
+	// Group sessions by provider and the profile they were created with,
+	// since each profile may be a different provider project. Archived
+	// sessions are history, so they're left as recorded.
+	type providerKey struct{ profile, provider string }
+	byProvider := make(map[providerKey][]int) // provider -> session indices
 	for i, sess := range sessions {
-		if sess.Provider != "" {
-			byProvider[sess.Provider] = append(byProvider[sess.Provider], i)
+		if sess.Provider != "" && !sess.IsArchived() {
+			key := providerKey{sess.Profile, sess.Provider}
+			byProvider[key] = append(byProvider[key], i)
 		}
 	}
 
 	// Sync each provider
-	for provName, indices := range byProvider {
-		prov, err := getProvider(provName)
+	for key, indices := range byProvider {
+		provName := vmCacheKey(key.profile, key.provider)
+		prov, err := getProviderFromSession(&sessions[indices[0]])
 		if err != nil {
 			verboseLog("Failed to get provider %s for sync: %v", provName, err)
 			continue
 		}
 
-		vms, err := prov.List(ctx)
+		var ids []string
+		for _, i := range indices {
+			if sessions[i].ProviderID != "" {
+				ids = append(ids, sessions[i].ProviderID)
+			}
+		}
+
+		vms, err := listProviderVMs(ctx, prov, provName, ids)
 		if err != nil {
 			verboseLog("Failed to list VMs from %s: %v", provName, err)
 			continue
@@ -129,9 +350,14 @@ func syncWithProviderAPI(ctx context.Context, sessions []session.Session, store
 					sessions[i].Status = newStatus
 					_ = store.Update(sess.ID, newStatus)
 				}
-				// Update IP address if it changed
-				if vm.IPAddress != "" && vm.IPAddress != sess.IPAddress {
-					sessions[i].IPAddress = vm.IPAddress
+				// Update IP addresses if they changed
+				if (vm.IPAddress != "" && vm.IPAddress != sess.IPAddress) || (vm.IPv6 != "" && vm.IPv6 != sess.IPv6) {
+					if vm.IPAddress != "" {
+						sessions[i].IPAddress = vm.IPAddress
+					}
+					if vm.IPv6 != "" {
+						sessions[i].IPv6 = vm.IPv6
+					}
 					_ = store.UpdateSession(sessions[i])
 				}
 			} else if sess.Status.IsActive() {
@@ -155,6 +381,86 @@ func syncWithProviderAPI(ctx context.Context, sessions []session.Session, store
 	return sessions
 }
 
+// filterByLabels returns the sessions whose labels match every entry in filter.
+func filterByLabels(sessions []session.Session, filter map[string]string) []session.Session {
+	matched := make([]session.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		if sess.MatchesLabels(filter) {
+			matched = append(matched, sess)
+		}
+	}
+	return matched
+}
+
+// createdRange bounds session creation times. A zero bound is open.
+type createdRange struct {
+	since  time.Time
+	before time.Time
+}
+
+// isZero reports whether the range has no bounds.
+func (r createdRange) isZero() bool {
+	return r.since.IsZero() && r.before.IsZero()
+}
+
+// contains reports whether t falls within the range.
+func (r createdRange) contains(t time.Time) bool {
+	if !r.since.IsZero() && t.Before(r.since) {
+		return false
+	}
+	if !r.before.IsZero() && !t.Before(r.before) {
+		return false
+	}
+	return true
+}
+
+// parseCreatedRange parses the --since and --before flags. Each is either a
+// duration before now or an RFC3339 time; an empty value leaves that bound open.
+func parseCreatedRange(since, before string, now time.Time) (createdRange, error) {
+	var r createdRange
+	var err error
+	if since != "" {
+		if r.since, err = parseTimeBound("since", since, now); err != nil {
+			return r, err
+		}
+	}
+	if before != "" {
+		if r.before, err = parseTimeBound("before", before, now); err != nil {
+			return r, err
+		}
+	}
+	if !r.since.IsZero() && !r.before.IsZero() && !r.since.Before(r.before) {
+		return r, fmt.Errorf("--since must be earlier than --before")
+	}
+	return r, nil
+}
+
+// parseTimeBound parses a duration ago, such as 24h, or an RFC3339 time.
+func parseTimeBound(flagName, value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid --%s value %q: duration cannot be negative", flagName, value)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s value %q: expected a duration like 24h or an RFC3339 time", flagName, value)
+	}
+	return t, nil
+}
+
+// filterByCreated returns the sessions created within r.
+func filterByCreated(sessions []session.Session, r createdRange) []session.Session {
+	matched := make([]session.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		if r.contains(sess.CreatedAt) {
+			matched = append(matched, sess)
+		}
+	}
+	return matched
+}
+
 // mapVMStatusToSession converts provider.VMStatus to session.Status.
 func mapVMStatusToSession(status provider.VMStatus) session.Status {
 	switch status {
@@ -171,18 +477,17 @@ func mapVMStatusToSession(status provider.VMStatus) session.Status {
 	}
 }
 
-// outputJSON outputs sessions as JSON.
-func outputJSON(sessions []session.Session) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(sessions)
-}
-
-// outputTable outputs sessions as a formatted table.
-func outputTable(sessions []session.Session) error {
+// outputTable writes sessions as a formatted table. With long, a column
+// with each session's provisioning time is added.
+func outputTable(w io.Writer, sessions []session.Session, long bool) error {
 	// Print header
-	fmt.Printf("%-18s %-10s %-16s %-20s %s\n",
-		"ID", "PROVIDER", "STATUS", "CREATED", "TIMEOUT")
+	if long {
+		fmt.Fprintf(w, "%-18s %-10s %-16s %-20s %-14s %s\n",
+			"ID", "PROVIDER", "STATUS", "CREATED", "PROVISIONING", "TIMEOUT")
+	} else {
+		fmt.Fprintf(w, "%-18s %-10s %-16s %-20s %s\n",
+			"ID", "PROVIDER", "STATUS", "CREATED", "TIMEOUT")
+	}
 
 	// Print sessions
 	for _, sess := range sessions {
@@ -193,7 +498,19 @@ func outputTable(sessions []session.Session) error {
 			providerName = "(legacy)"
 		}
 
-		fmt.Printf("%-18s %-10s %-16s %-20s %s\n",
+		if long {
+			fmt.Fprintf(w, "%-18s %-10s %-16s %-20s %-14s %s\n",
+				sess.ID,
+				providerName,
+				sess.Status,
+				created,
+				formatProvisioning(&sess),
+				timeout,
+			)
+			continue
+		}
+
+		fmt.Fprintf(w, "%-18s %-10s %-16s %-20s %s\n",
 			sess.ID,
 			providerName,
 			sess.Status,
@@ -222,6 +539,22 @@ func formatTimeout(remaining *time.Duration) string {
 	return fmt.Sprintf("%dm remaining", int(d.Minutes()))
 }
 
+// formatProvisioning formats how long the session took to provision. Sessions
+// still provisioning or that failed to provision show their state instead.
+func formatProvisioning(sess *session.Session) string {
+	if d := sess.ProvisioningDuration(); d != nil {
+		return d.Round(time.Second).String()
+	}
+	switch {
+	case sess.ProvisioningStartedAt == nil:
+		return "-"
+	case sess.Status == session.StatusProvisioning:
+		return "in progress"
+	default:
+		return "incomplete"
+	}
+}
+
 // formatCreatedTime formats the creation time for display.
 func formatCreatedTime(t time.Time) string {
 	return t.Local().Format("2006-01-02 15:04:05")
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+// Log files written on the VM during provisioning.
+const (
+	cloudInitLogPath  = "/var/log/cloud-init-output.log"
+	initScriptLogPath = "/var/log/sandctl-init.log"
+)
+
+var logsFollow bool
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <name>",
+	Short: "Show provisioning logs for a session",
+	Long: `Show the provisioning logs of a running session.
+
+Prints the cloud-init output and, if a template init script was run,
+its saved output. Use --follow to keep streaming new log lines.`,
+	Example: `  # Show provisioning logs
+  sandctl logs alice
+
+  # Stream logs as they are written
+  sandctl logs alice --follow`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runLogs,
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep streaming new log output")
+
+	rootCmd.AddCommand(logsCmd)
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	// Normalize the session name (case-insensitive)
+	sessionName := session.NormalizeName(args[0])
+
+	// Validate session name format
+	if !session.ValidateID(sessionName) {
+		return fmt.Errorf("invalid session name format: %s", args[0])
+	}
+
+	// Get session store
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	// Check if session exists in local store
+	sess, err := store.Get(sessionName)
+	if err != nil {
+		var notFound *session.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.PrintError(os.Stderr, "session '%s' not found", sessionName)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, "Run 'sandctl list' to see available sessions.")
+			return nil
+		}
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	// Check if session has provider info (new format)
+	if sess.IsLegacySession() {
+		ui.PrintError(os.Stderr, "session '%s' is from an old version and incompatible", sessionName)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Please destroy this session and create a new one.")
+		return nil
+	}
+
+	// Check if session is running
+	if sess.Status != session.StatusRunning {
+		ui.FormatSessionNotRunning(os.Stderr, sessionName, sess.Status)
+		return nil
+	}
+
+	// Fail fast if the VM was deleted outside sandctl
+	if err := ensureSessionVM(store, sess); err != nil {
+		return err
+	}
+
+	// Check if we have IP address
+	host := sessionAddress(sess)
+	if host == "" {
+		return fmt.Errorf("session '%s' has no IP address", sessionName)
+	}
+
+	client, err := createSessionSSHClient(sess, host)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer client.Close()
+
+	return client.ExecWithStreams(logsCommand(logsFollow), nil, os.Stdout, os.Stderr)
+}
+
+// logsCommand builds the remote command that prints the provisioning logs.
+// The init script log is only included if the file exists.
+func logsCommand(follow bool) string {
+	tailArgs := "-n +1"
+	if follow {
+		tailArgs = "-n +1 -F"
+	}
+	return fmt.Sprintf(
+		"sudo tail %s %s $(test -f %s && echo %s)",
+		tailArgs, cloudInitLogPath, initScriptLogPath, initScriptLogPath,
+	)
+}
@@ -1,11 +1,24 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"crypto/md5" //nolint:gosec // Used for unique naming, not security
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -20,16 +33,51 @@ import (
 	"github.com/sandctl/sandctl/internal/ui"
 )
 
+// sshDialTimeout bounds each SSH connection attempt while waiting for a VM.
+const sshDialTimeout = 10 * time.Second
+
+// Bounds on the waits while a new VM is provisioned.
+const (
+	// vmReadyTimeout bounds WaitReady, until the provider reports the VM running.
+	vmReadyTimeout = 5 * time.Minute
+
+	// sshReadyTimeout bounds the wait for sshd on the VM to accept our key.
+	sshReadyTimeout = 5 * time.Minute
+
+	// cloudInitTimeout bounds the wait for cloud-init to finish.
+	cloudInitTimeout = 10 * time.Minute
+)
+
 var (
-	newTimeout   string
-	noConsole    bool
-	templateFlag string
-	providerArg  string
-	regionArg    string
-	serverType   string
-	imageArg     string
+	newTimeout      string
+	noTimeout       bool
+	noConsole       bool
+	templateFlag    string
+	providerArg     string
+	regionArg       string
+	serverType      string
+	imageArg        string
+	nameArg         string
+	labelArgs       []string
+	noOpenCode      bool
+	fromArg         string
+	untilArg        string
+	sshUserArg      string
+	sshPortArg      int
+	dryRun          bool
+	openPorts       []int
+	openPortMyIP    bool
+	sshKeyArg       string
+	attachVolume    int
+	volumeArg       string
+	providerSetArgs []string
+	placementGroup  string
+	newCount        int
 )
 
+// defaultNewParallel is how many sessions new --count provisions at once.
+const defaultNewParallel = 5
+
 var newCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Create a new sandboxed agent session",
@@ -39,7 +87,20 @@ The system provisions a VM using the configured provider (default: Hetzner Cloud
 installs development tools (Docker, Git, Node.js, Python), and optionally sets up
 OpenCode with your configured Zen key. After provisioning, an interactive console
 session is automatically started (unless --no-console is specified or stdin is
-not a terminal).`,
+not a terminal). Pressing Ctrl-C while provisioning deletes the partially
+created VM.
+
+If default_timeout is set in the config, it applies when neither --timeout nor
+--until is given. Use --no-timeout (or --timeout 0) to opt out.
+
+Use --set provider.key=value to override a provider setting for this session
+without editing the config, e.g. hetzner.network, hetzner.placement_group, or
+hetzner.ssh_key_id to install an existing provider SSH key. That key must be
+your configured SSH key, since sandctl connects to the VM with it.
+
+Use --count to create several identical sessions at once, e.g. for a fleet of
+agents. Each gets a generated name, they are provisioned in parallel, and a
+summary is printed at the end; no console is started.`,
 	Example: `  # Create a new session and connect automatically
   sandctl new
 
@@ -49,22 +110,77 @@ not a terminal).`,
   # Create with auto-destroy timeout
   sandctl new --timeout 2h
 
+  # Create with an absolute auto-destroy deadline
+  sandctl new --until 2025-06-01T18:00:00Z
+
   # Create without automatic console (for scripts)
   sandctl new --no-console
 
   # Create in specific region with specific server type
-  sandctl new --region hel1 --server-type cpx41`,
+  sandctl new --region hel1 --server-type cpx41
+
+  # Create with a specific session name
+  sandctl new --name myproj
+
+  # Create with labels
+  sandctl new --label project=ghost --label env=dev
+
+  # Create a copy of an existing session's setup
+  sandctl new --from alice
+
+  # Open a dev server port, reachable only from your current IP
+  sandctl new --open-port 3000 --open-port-my-ip
+
+  # Attach a 50 GB data volume, mounted at /data
+  sandctl new --attach-volume 50
+
+  # Reattach a volume kept by 'destroy --keep-volume'
+  sandctl new --volume 12345
+
+  # Provision with a different key than the configured one
+  sandctl new --ssh-key ~/.ssh/team_ed25519.pub
+
+  # Spread VMs across hosts (the group is created if it doesn't exist)
+  sandctl new --placement-group workers
+
+  # Override provider settings for this session only
+  sandctl new --set hetzner.network=internal --set hetzner.placement_group=spread
+
+  # Create three sessions from the same template
+  sandctl new --count 3 -T Ghost
+
+  # Preview what would be created, including the cloud-init script
+  sandctl new --dry-run -T Ghost`,
 	RunE: runNew,
 }
 
 func init() {
-	newCmd.Flags().StringVarP(&newTimeout, "timeout", "t", "", "auto-destroy after duration (e.g., 1h, 30m)")
+	newCmd.Flags().StringVarP(&newTimeout, "timeout", "t", "", "auto-destroy after duration (e.g., 1h, 30m; 0 disables; default: default_timeout from config)")
 	newCmd.Flags().BoolVar(&noConsole, "no-console", false, "skip automatic console connection after provisioning")
 	newCmd.Flags().StringVarP(&templateFlag, "template", "T", "", "template to use for initialization")
 	newCmd.Flags().StringVarP(&providerArg, "provider", "p", "", "provider to use (default: from config)")
 	newCmd.Flags().StringVar(&regionArg, "region", "", "datacenter region (overrides config default)")
 	newCmd.Flags().StringVar(&serverType, "server-type", "", "server hardware type (overrides config default)")
-	newCmd.Flags().StringVar(&imageArg, "image", "", "OS image (overrides config default)")
+	newCmd.Flags().StringVar(&imageArg, "image", "", "OS image or alias like ubuntu, debian (overrides config default)")
+	newCmd.Flags().StringVar(&nameArg, "name", "", "session name to use instead of a generated one")
+	newCmd.Flags().StringArrayVar(&labelArgs, "label", nil, "label to attach to the session (key=value, repeatable)")
+	newCmd.Flags().BoolVar(&noOpenCode, "no-opencode", false, "skip OpenCode setup even if a Zen key is configured")
+	newCmd.Flags().StringVar(&untilArg, "until", "", "auto-destroy at an RFC3339 time (e.g., 2025-06-01T18:00:00Z)")
+	newCmd.Flags().BoolVar(&noTimeout, "no-timeout", false, "don't auto-destroy, even if default_timeout is configured")
+	newCmd.MarkFlagsMutuallyExclusive("timeout", "until", "no-timeout")
+	newCmd.Flags().StringVar(&sshUserArg, "ssh-user", "", "SSH login user for custom images (default: from provider config, then top-level ssh_user, or agent)")
+	newCmd.Flags().IntVar(&sshPortArg, "ssh-port", 0, "SSH port for images that run sshd on a non-standard port (default: from provider config, or 22)")
+	newCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print what would be created and the cloud-init script without provisioning")
+	newCmd.Flags().IntSliceVar(&openPorts, "open-port", nil, "inbound TCP port to open in a provider firewall (repeatable)")
+	newCmd.Flags().BoolVar(&openPortMyIP, "open-port-my-ip", false, "only allow --open-port traffic from your current public IP")
+	newCmd.Flags().IntVar(&attachVolume, "attach-volume", 0, "attach a persistent data volume of this size in GB, mounted at "+volumeMountPoint)
+	newCmd.Flags().StringVar(&volumeArg, "volume", "", "attach an existing volume by ID, e.g. one kept by 'destroy --keep-volume', mounted at "+volumeMountPoint)
+	newCmd.MarkFlagsMutuallyExclusive("attach-volume", "volume")
+	newCmd.Flags().StringVar(&sshKeyArg, "ssh-key", "", "SSH public key file or agent fingerprint (SHA256:...) to use instead of the configured key")
+	newCmd.Flags().StringVar(&placementGroup, "placement-group", "", "placement group to spread the VM across hosts in, created if missing (overrides config default)")
+	newCmd.Flags().StringArrayVar(&providerSetArgs, "set", nil, "override a provider setting for this session (provider.key=value, repeatable)")
+	newCmd.Flags().IntVar(&newCount, "count", 1, "number of sessions to create with the same settings (implies --no-console)")
+	newCmd.Flags().StringVar(&fromArg, "from", "", "copy region, server type, image, template, SSH user, and labels from an existing session")
 
 	rootCmd.AddCommand(newCmd)
 }
@@ -72,6 +188,16 @@ func init() {
 func runNew(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	if newCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	if newCount > 1 && nameArg != "" {
+		return fmt.Errorf("--name cannot be used with --count")
+	}
+	if newCount > 1 && dryRun {
+		return fmt.Errorf("--dry-run cannot be used with --count")
+	}
+
 	// Load configuration
 	cfg, err := loadConfig()
 	if err != nil {
@@ -83,55 +209,194 @@ func runNew(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("legacy configuration detected\n\n%s", config.MigrationInstructions())
 	}
 
+	// Parse labels if provided
+	labels, err := parseKeyValueFlags("label", labelArgs)
+	if err != nil {
+		return err
+	}
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+
+	params := createParams{
+		Provider:   providerArg,
+		Region:     regionArg,
+		ServerType: serverType,
+		Image:      imageArg,
+		Template:   templateFlag,
+		SSHUser:    sshUserArg,
+		SSHPort:    sshPortArg,
+		Labels:     labels,
+	}
+
+	// Copy unset parameters from the source session when cloning
+	if fromArg != "" {
+		store, err := getSessionStore()
+		if err != nil {
+			return err
+		}
+		source, err := store.Get(session.NormalizeName(fromArg))
+		if err != nil {
+			var notFound *session.NotFoundError
+			if errors.As(err, &notFound) {
+				return fmt.Errorf("source session '%s' not found. Use 'sandctl list --all' to see available sessions", fromArg)
+			}
+			return fmt.Errorf("failed to load source session: %w", err)
+		}
+		params = params.withDefaults(source)
+		verboseLog("Cloning from session: %s", source.ID)
+	}
+
 	// Get provider
-	providerName := providerArg
+	providerName := params.Provider
 	if providerName == "" {
 		providerName = cfg.DefaultProvider
 	}
 
-	prov, err := getProvider(providerName)
+	// Apply --set overrides to a copy of the config, for this session only
+	providerSets, err := parseProviderSets(providerSetArgs, providerName)
+	if err != nil {
+		return err
+	}
+	cfg, err = withProviderOverrides(cfg, providerName, providerSets)
+	if err != nil {
+		return err
+	}
+	if _, ok := providerSets["ssh_key_id"]; ok && sshKeyArg != "" {
+		return fmt.Errorf("--ssh-key and --set %s.ssh_key_id cannot be used together", providerName)
+	}
+
+	prov, err := provider.Get(providerName, cfg)
 	if err != nil {
 		return err
 	}
 
+	// Check firewall options before anything is provisioned
+	var firewallSources []string
+	if len(openPorts) > 0 {
+		if _, ok := prov.(provider.FirewallManager); !ok {
+			return fmt.Errorf("provider %s does not support --open-port", prov.Name())
+		}
+		if err := validatePorts(openPorts); err != nil {
+			return err
+		}
+		if openPortMyIP {
+			ip, err := detectPublicIP(ctx)
+			if err != nil {
+				return err
+			}
+			verboseLog("Restricting opened ports to %s", ip)
+			firewallSources = []string{ip}
+		}
+	} else if openPortMyIP {
+		return fmt.Errorf("--open-port-my-ip requires --open-port")
+	}
+
+	// Check volume options before anything is provisioned
+	if attachVolume != 0 {
+		if _, ok := prov.(provider.VolumeManager); !ok {
+			return fmt.Errorf("provider %s does not support --attach-volume", prov.Name())
+		}
+		if attachVolume < 0 {
+			return fmt.Errorf("--attach-volume must be a positive size in GB")
+		}
+	}
+	if volumeArg != "" {
+		if _, ok := prov.(provider.VolumeManager); !ok {
+			return fmt.Errorf("provider %s does not support --volume", prov.Name())
+		}
+		if newCount > 1 {
+			return fmt.Errorf("--volume can only be attached to one session; drop --count")
+		}
+	}
+
+	// Resolve image aliases such as "ubuntu" to a concrete image
+	image := params.Image
+	if image == "" {
+		if pc, ok := cfg.GetProviderConfig(prov.Name()); ok {
+			image = pc.Image
+		}
+	}
+	if provider.IsImageAlias(image) {
+		params.Image, err = resolveImage(ctx, prov, image)
+		if err != nil {
+			return err
+		}
+	}
+
+	sshUser := firstNonEmpty(params.SSHUser, providerSSHUser(cfg, prov.Name()))
+	sshPort := params.SSHPort
+	if sshPort == 0 {
+		sshPort = providerSSHPort(cfg, prov.Name())
+	}
+	if sshPort < 0 || sshPort > 65535 {
+		return fmt.Errorf("invalid --ssh-port %d: must be between 1 and 65535", sshPort)
+	}
+
+	// Resolve the SSH key, checking that an overriding key is usable before provisioning
+	var sshKey *sessionSSHKey
+	if sshKeyArg != "" {
+		sshKey, err = resolveSSHKeyOverride(sshKeyArg)
+		if err != nil {
+			return err
+		}
+		verboseLog("Using SSH key %s", sshKey.Fingerprint)
+	}
+
 	// Look up template if provided
 	var tmplConfig *templateconfig.TemplateConfig
-	if templateFlag != "" {
+	if params.Template != "" {
 		store := getTemplateStore()
-		tmplConfig, err = store.Get(templateFlag)
+		tmplConfig, err = store.Get(params.Template)
 		if err != nil {
 			if _, ok := err.(*templateconfig.NotFoundError); ok {
-				return fmt.Errorf("template '%s' not found. Use 'sandctl template list' to see available templates", templateFlag)
+				return fmt.Errorf("template '%s' not found. Use 'sandctl template list' to see available templates", params.Template)
 			}
 			return fmt.Errorf("failed to load template: %w", err)
 		}
 		verboseLog("Template: %s (normalized: %s)", tmplConfig.OriginalName, tmplConfig.Template)
-	}
 
-	// Parse timeout if provided
-	var timeout *session.Duration
-	if newTimeout != "" {
-		d, parseErr := time.ParseDuration(newTimeout)
-		if parseErr != nil {
-			return fmt.Errorf("invalid timeout format: %w", parseErr)
+		// Read the env_file now, so a bad file fails before anything is created
+		tmplConfig.Env, err = store.ResolveEnv(tmplConfig)
+		if err != nil {
+			return fmt.Errorf("invalid env_file for template '%s': %w", tmplConfig.OriginalName, err)
 		}
-		timeout = &session.Duration{Duration: d}
+	}
+
+	// Resolve the auto-destroy timeout from flags or the config default
+	timeout, err := resolveTimeout(newTimeout, untilArg, noTimeout, cfg.DefaultTimeout, time.Now())
+	if err != nil {
+		return err
 	}
 
 	// Get used names from store to avoid collisions
-	store := getSessionStore()
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
 	usedNames, err := store.GetUsedNames()
 	if err != nil {
 		return fmt.Errorf("failed to get existing sessions: %w", err)
 	}
 
-	// Generate session ID (human-readable name)
-	sessionID, err := session.GenerateID(usedNames)
+	// Use the requested name or generate one (human-readable name)
+	sessionID, err := resolveSessionName(nameArg, usedNames)
 	if err != nil {
-		return fmt.Errorf("failed to generate session name: %w", err)
+		return err
+	}
+
+	// Generate the names of any further sessions up front so they don't collide
+	names := []string{sessionID}
+	for len(names) < newCount {
+		usedNames = append(usedNames, names[len(names)-1])
+		name, err := resolveSessionName("", usedNames)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
 	}
 
-	verboseLog("Generated session ID: %s", sessionID)
+	verboseLog("Session ID: %s", strings.Join(names, ", "))
 	verboseLog("Provider: %s", prov.Name())
 	verboseLog("Timeout: %v", timeout)
 
@@ -142,40 +407,198 @@ func runNew(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(os.Stderr)
 	}
 
-	fmt.Println("Creating new session...")
+	printCostEstimate(cfg, prov.Name(), params.ServerType, timeout)
 
-	// Ensure SSH key is uploaded to provider
-	sshKeyID, err := ensureSSHKey(ctx, cfg, prov)
+	// Build cloud-init script
+	userData := hetzner.CloudInitScript()
+	if attachVolume > 0 || volumeArg != "" {
+		userData = hetzner.CloudInitScriptWithVolume(volumeMountPoint)
+	}
+
+	createOpts := provider.CreateOpts{
+		Name:           sessionID,
+		Region:         params.Region,
+		ServerType:     params.ServerType,
+		Image:          params.Image,
+		UserData:       userData,
+		Labels:         params.Labels,
+		PlacementGroup: placementGroup,
+	}
+
+	// Warn before provisioning fails with "resource unavailable"
+	if warning := regionWarning(ctx, prov, createOpts); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n\n", warning)
+	}
+
+	_, keepProviderKey := providerSets["ssh_key_id"]
+	pubKeyData, sshKeyID, err := resolveSessionKey(cfg, providerName, sshKey, keepProviderKey)
 	if err != nil {
-		return fmt.Errorf("failed to set up SSH key: %w", err)
+		return err
+	}
+
+	if dryRun {
+		return printDryRun(os.Stdout, prov, createOpts, pubKeyData, sshKeyID, sshUser, sshPort, tmplConfig, timeout, openPorts)
+	}
+
+	// Fail before provisioning if the VM couldn't be connected to afterwards
+	if sshKey == nil {
+		if err := checkAgentKey(cfg); err != nil {
+			return err
+		}
+	}
+	if keepProviderKey {
+		if err := checkProviderKey(ctx, prov, cfg, sshKeyID); err != nil {
+			return err
+		}
+	}
+
+	if newCount > 1 {
+		fmt.Printf("Creating %d sessions...\n", newCount)
+	} else {
+		fmt.Println("Creating new session...")
+	}
+
+	// Ensure SSH key is uploaded to provider
+	if sshKeyID == "" {
+		if sshKeyID, err = ensureSSHKey(ctx, prov, pubKeyData); err != nil {
+			return fmt.Errorf("failed to set up SSH key: %w", err)
+		}
 	}
 	verboseLog("SSH key ID: %s", sshKeyID)
+	createOpts.SSHKeyID = sshKeyID
+
+	spec := &newSessionSpec{
+		cfg:             cfg,
+		prov:            prov,
+		params:          params,
+		createOpts:      createOpts,
+		sshUser:         sshUser,
+		sshPort:         sshPort,
+		sshKey:          sshKey,
+		tmplConfig:      tmplConfig,
+		timeout:         timeout,
+		firewallSources: firewallSources,
+		preferIPv6:      useIPv6 || cfg.PreferIPv6,
+	}
 
-	// Build cloud-init script
-	userData := hetzner.CloudInitScript()
+	if newCount > 1 {
+		return runNewBatch(ctx, spec, store, names)
+	}
 
-	// Create VM
-	createOpts := provider.CreateOpts{
-		Name:       sessionID,
-		SSHKeyID:   sshKeyID,
-		Region:     regionArg,
-		ServerType: serverType,
-		Image:      imageArg,
-		UserData:   userData,
+	// Ctrl-C cancels provisioning; the current step finishes so the VM and
+	// any other resources it creates are known, then they are cleaned up
+	provisionCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	sess, err := provisionSession(ctx, provisionCtx, spec, store, sessionID, os.Stdout, os.Stderr)
+
+	// Later steps, like the console, handle Ctrl-C themselves
+	stopSignals()
+
+	// A failed init script was already reported - exit without console
+	if err != nil {
+		return err
+	}
+
+	// Print success message with session name
+	fmt.Println()
+	fmt.Printf("Session created: %s\n", sessionID)
+	fmt.Printf("IP address: %s\n", sess.IPAddress)
+	if sess.IPv6 != "" {
+		fmt.Printf("IPv6 address: %s\n", sess.IPv6)
+	}
+	if sess.VolumeID != "" {
+		fmt.Printf("Volume: %d GB at %s\n", sess.VolumeSizeGB, volumeMountPoint)
+	}
+	if timeout != nil {
+		fmt.Printf("Timeout: %s (auto-destroy after %s)\n", timeout.Duration,
+			sess.CreatedAt.Add(timeout.Duration).Local().Format("2006-01-02 15:04"))
+	} else {
+		fmt.Println("Timeout: none")
+	}
+
+	// Determine if we should start console automatically
+	isInteractive := term.IsTerminal(int(os.Stdin.Fd()))
+	shouldStartConsole := !noConsole && isInteractive
+
+	if shouldStartConsole {
+		fmt.Println("Connecting to console...")
+		fmt.Println()
+
+		// Start SSH console
+		consoleErr := startSSHConsole(sess)
+		if consoleErr != nil {
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintf(os.Stderr, "Warning: Failed to connect to console: %v\n", consoleErr)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintf(os.Stderr, "Session was created successfully. Use 'sandctl console %s' to connect manually.\n", sessionID)
+		}
+	} else {
+		fmt.Println()
+		fmt.Printf("Use 'sandctl console %s' to connect.\n", sessionID)
+		fmt.Printf("Use 'sandctl destroy %s' when done.\n", sessionID)
 	}
 
+	return nil
+}
+
+// newSessionSpec holds what runNew resolves once and shares between every
+// session it provisions.
+type newSessionSpec struct {
+	cfg             *config.Config
+	prov            provider.Provider
+	params          createParams
+	createOpts      provider.CreateOpts // Name is set per session
+	sshUser         string
+	sshPort         int
+	sshKey          *sessionSSHKey
+	tmplConfig      *templateconfig.TemplateConfig
+	timeout         *session.Duration
+	firewallSources []string
+	preferIPv6      bool // reach the VM over IPv6, per --ipv6 or prefer_ipv6
+}
+
+// errInitScriptFailed is returned by provisionSession when the session was
+// created but its template init script failed.
+var errInitScriptFailed = errors.New("init script failed")
+
+// provisionSession creates, sets up, and records the session named sessionID,
+// writing progress to stdout and problems to stderr. On failure or
+// cancellation everything created is cleaned up; a failed init script leaves
+// the session running and returns errInitScriptFailed.
+func provisionSession(ctx, provisionCtx context.Context, spec *newSessionSpec, store session.Store, sessionID string, stdout, stderr io.Writer) (*session.Session, error) {
+	createOpts := spec.createOpts
+	createOpts.Name = sessionID
+
 	// Create session record (provisioning state)
+	createdAt := time.Now().UTC()
 	sess := session.Session{
 		ID:        sessionID,
 		Status:    session.StatusProvisioning,
-		CreatedAt: time.Now().UTC(),
-		Timeout:   timeout,
-		Provider:  prov.Name(),
+		CreatedAt: createdAt,
+		Timeout:   spec.timeout,
+		Provider:  spec.prov.Name(),
+		Profile:   spec.cfg.ActiveProfile,
+		SSHUser:   spec.sshUser,
+		SSHPort:   spec.sshPort,
+		OpenPorts: openPorts,
+
+		ProvisioningStartedAt: &createdAt,
+	}
+	if len(spec.params.Labels) > 0 {
+		sess.Labels = spec.params.Labels
+	}
+	if spec.tmplConfig != nil {
+		sess.Template = spec.tmplConfig.Template
+	}
+	if spec.sshKey != nil {
+		sess.SSHKeyFingerprint = spec.sshKey.Fingerprint
+		sess.SSHKeyFile = spec.sshKey.PrivateKeyPath
 	}
 
 	// Add to local store immediately
 	if err := store.Add(sess); err != nil {
-		return fmt.Errorf("failed to save session: %w", err)
+		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
 	// Build provisioning steps
@@ -185,8 +608,9 @@ func runNew(cmd *cobra.Command, args []string) error {
 			Message: "Provisioning VM",
 			Action: func() error {
 				var err error
-				vm, err = prov.Create(ctx, createOpts)
+				vm, err = spec.prov.Create(ctx, createOpts)
 				if err != nil {
+					sess.FailureReason = session.FailureVMCreate
 					return fmt.Errorf("failed to provision VM: %w", err)
 				}
 				verboseLog("VM created: id=%s, name=%s, ip=%s", vm.ID, vm.Name, vm.IPAddress)
@@ -195,150 +619,434 @@ func runNew(cmd *cobra.Command, args []string) error {
 		},
 		{
 			Message: "Waiting for VM to be ready",
-			Action: func() error {
-				err := prov.WaitReady(ctx, vm.ID, 5*time.Minute)
+			ActionWithStatus: func(setStatus func(string)) error {
+				readyCtx, cancel := context.WithTimeout(provisionCtx, vmReadyTimeout)
+				defer cancel()
+
+				err := spec.prov.WaitReady(readyCtx, vm.ID, provider.WithSSHPort(spec.sshPort), provider.WithIPv6(spec.preferIPv6), provider.WithProgress(func(state provider.ReadyState) {
+					setStatus(readyStatus(state))
+				}))
 				if err != nil {
+					sess.FailureReason = session.FailureWaitReady
+					if errors.Is(err, provider.ErrTimeout) {
+						sess.FailureReason = session.FailureWaitReadyTimeout
+					}
 					return fmt.Errorf("VM failed to become ready: %w", err)
 				}
 				// Refresh VM info to get IP
-				vm, err = prov.Get(ctx, vm.ID)
+				vm, err = spec.prov.Get(ctx, vm.ID)
 				if err != nil {
+					sess.FailureReason = session.FailureWaitReady
 					return fmt.Errorf("failed to get VM info: %w", err)
 				}
+				// The IP may be recycled from an old VM; drop its stale host key
+				forgetHostKey(vm.IPAddress)
+				forgetHostKey(vm.IPv6)
 				return nil
 			},
 		},
 	}
 
+	// Open requested ports in a session firewall
+	if len(openPorts) > 0 {
+		steps = append(steps, ui.ProgressStep{
+			Message: "Configuring firewall",
+			Action: func() error {
+				fm := spec.prov.(provider.FirewallManager)
+				id, err := fm.CreateFirewall(ctx, vm.ID, spec.sshPort, openPorts, spec.firewallSources)
+				if err != nil {
+					sess.FailureReason = session.FailureFirewall
+					return err
+				}
+				sess.FirewallID = id
+				verboseLog("Firewall created: id=%s", id)
+				return nil
+			},
+		})
+	}
+
+	// Create and attach the data volume; cloud-init mounts it
+	if attachVolume > 0 {
+		steps = append(steps, ui.ProgressStep{
+			Message: fmt.Sprintf("Attaching %d GB volume", attachVolume),
+			Action: func() error {
+				vols := spec.prov.(provider.VolumeManager)
+				id, err := vols.CreateVolume(ctx, vm.ID, attachVolume)
+				// Record a volume that failed to attach so cleanup removes it
+				sess.VolumeID = id
+				if err != nil {
+					sess.FailureReason = session.FailureVolume
+					return err
+				}
+				sess.VolumeSizeGB = attachVolume
+				verboseLog("Volume created: id=%s", id)
+				return nil
+			},
+		})
+	}
+
+	// Reattach an existing volume. It is only recorded once provisioning
+	// succeeds, so cleaning up a failed session never deletes it; deleting
+	// the VM detaches it.
+	var volumeSizeGB int
+	if volumeArg != "" {
+		steps = append(steps, ui.ProgressStep{
+			Message: fmt.Sprintf("Attaching volume %s", volumeArg),
+			Action: func() error {
+				vols := spec.prov.(provider.VolumeManager)
+				size, err := vols.AttachVolume(ctx, vm.ID, volumeArg)
+				if err != nil {
+					sess.FailureReason = session.FailureVolume
+					return err
+				}
+				volumeSizeGB = size
+				verboseLog("Volume attached: id=%s", volumeArg)
+				return nil
+			},
+		})
+	}
+
+	// A single SSH connection, opened once sshd is up, is reused for every
+	// post-provision step
+	var client *sshexec.Client
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
 	// Wait for cloud-init to complete (creates agent user with SSH access)
 	steps = append(steps, ui.ProgressStep{
 		Message: "Waiting for setup to complete",
 		Action: func() error {
-			return waitForCloudInit(vm.IPAddress, 10*time.Minute)
+			// waitForSSH polls on its own, so each poll dials only once
+			addr := (&session.Session{IPAddress: vm.IPAddress, IPv6: vm.IPv6}).SSHAddress(spec.preferIPv6)
+			c, err := createSessionSSHClient(&sess, addr, sshexec.WithTimeout(sshDialTimeout), sshexec.WithConnectAttempts(1))
+			if err != nil {
+				return fmt.Errorf("failed to create SSH client: %w", err)
+			}
+			client = c
+
+			sshCtx, cancel := context.WithTimeout(provisionCtx, sshReadyTimeout)
+			defer cancel()
+			if err := waitForSSH(sshCtx, client); err != nil {
+				sess.FailureReason = session.FailureSSHUnavailable
+				return err
+			}
+
+			cloudInitCtx, cancel := context.WithTimeout(provisionCtx, cloudInitTimeout)
+			defer cancel()
+			if err := waitForCloudInit(cloudInitCtx, client); err != nil {
+				sess.FailureReason = session.FailureCloudInit
+				return err
+			}
+			return nil
 		},
 	})
 
 	// Add OpenCode setup if configured
-	if cfg.OpencodeZenKey != "" {
+	if spec.cfg.OpencodeZenKey != "" && !noOpenCode {
 		steps = append(steps, ui.ProgressStep{
 			Message: "Setting up OpenCode",
 			Action: func() error {
-				return setupOpenCodeViaSSH(vm.IPAddress, cfg)
+				if err := setupOpenCodeViaSSH(client, spec.cfg); err != nil {
+					sess.FailureReason = session.FailureSetup
+					return err
+				}
+				return nil
 			},
 		})
 	}
 
 	// Add git config setup if configured
-	if cfg.HasGitConfig() {
+	if spec.cfg.HasGitConfig() {
 		steps = append(steps, ui.ProgressStep{
 			Message: "Configuring git",
 			Action: func() error {
-				return setupGitConfigViaSSH(vm.IPAddress, cfg)
+				if err := setupGitConfigViaSSH(client, spec.cfg, spec.sshUser); err != nil {
+					sess.FailureReason = session.FailureSetup
+					return err
+				}
+				return nil
 			},
 		})
 	}
 
 	// Add GitHub CLI authentication if token is configured
-	if cfg.HasGitHubToken() {
+	if spec.cfg.HasGitHubToken() {
 		steps = append(steps, ui.ProgressStep{
 			Message: "Authenticating GitHub CLI",
 			Action: func() error {
-				return setupGitHubCLIViaSSH(vm.IPAddress, cfg)
+				if err := setupGitHubCLIViaSSH(client, spec.cfg, spec.sshUser); err != nil {
+					sess.FailureReason = session.FailureSetup
+					return err
+				}
+				return nil
 			},
 		})
 	}
 
-	provisionErr := ui.RunSteps(os.Stdout, steps)
+	provisionErr := ui.RunSteps(stdout, cancellableSteps(provisionCtx, steps))
 
+	if provisionErr != nil && provisionCtx.Err() != nil {
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Cancelled, cleaning up...")
+		sess.FailureReason = session.FailureCancelled
+		cleanupFailedSession(ctx, spec.prov, store, &sess, vm)
+		fmt.Fprintf(stderr, "Session '%s' was not created.\n", sessionID)
+		return &sess, &exitError{code: 130}
+	}
 	if provisionErr != nil {
 		// Cleanup on failure
-		cleanupFailedSession(ctx, prov, store, sessionID, vm)
-		return provisionErr
+		cleanupFailedSession(ctx, spec.prov, store, &sess, vm)
+		return &sess, provisionErr
 	}
 
+	provisionedAt := time.Now().UTC()
+	sess.ProvisioningCompletedAt = &provisionedAt
+	verboseLog("Provisioned in %s", provisionedAt.Sub(createdAt).Round(time.Second))
+
 	// Check for and run custom init script for the template
 	var initScriptFailed bool
-	if tmplConfig != nil {
+	if spec.tmplConfig != nil {
 		tmplStore := getTemplateStore()
-		if initScript, err := tmplStore.GetInitScript(tmplConfig.Template); err == nil && initScript != "" {
-			fmt.Println()
-			fmt.Println("Running template init script...")
-			initErr := runTemplateInitScript(vm.IPAddress, tmplConfig, initScript)
+		if initScript, err := tmplStore.GetInitScript(spec.tmplConfig.Template); err == nil && initScript != "" {
+			fmt.Fprintln(stdout)
+			fmt.Fprintln(stdout, "Running template init script...")
+			initErr := runTemplateInitScript(client, spec.tmplConfig, initScript, stdout, stderr)
 			if initErr != nil {
 				initScriptFailed = true
-				fmt.Fprintln(os.Stderr)
-				fmt.Fprintf(os.Stderr, "Init script failed: %v\n", initErr)
-				fmt.Fprintln(os.Stderr)
-				fmt.Fprintf(os.Stderr, "Session is available for debugging. Use 'sandctl console %s' to connect.\n", sessionID)
-				fmt.Fprintf(os.Stderr, "Use 'sandctl destroy %s' when done.\n", sessionID)
+				fmt.Fprintln(stderr)
+				fmt.Fprintf(stderr, "Init script failed: %v\n", initErr)
+				fmt.Fprintln(stderr)
+				fmt.Fprintf(stderr, "Session is available for debugging. Use 'sandctl console %s' to connect.\n", sessionID)
+				fmt.Fprintf(stderr, "Use 'sandctl destroy %s' when done.\n", sessionID)
 			} else {
-				fmt.Println("Init script completed successfully.")
+				fmt.Fprintln(stdout, "Init script completed successfully.")
 			}
 		}
 	}
 
+	// Provisioning is done; the console below opens its own connection
+	client.Close()
+
 	// Update session with provider info
 	sess.Status = session.StatusRunning
 	sess.ProviderID = vm.ID
 	sess.IPAddress = vm.IPAddress
+	sess.IPv6 = vm.IPv6
+	sess.Region = firstNonEmpty(vm.Region, spec.params.Region)
+	sess.ServerType = firstNonEmpty(vm.ServerType, spec.params.ServerType)
+	sess.Image = firstNonEmpty(vm.Image, spec.params.Image)
+	if volumeArg != "" {
+		sess.VolumeID = volumeArg
+		sess.VolumeSizeGB = volumeSizeGB
+	}
 	if err := store.UpdateSession(sess); err != nil {
-		verboseLog("Warning: failed to update session: %v", err)
+		logger.Warn("failed to update session", "session", sessionID, "error", err)
 	}
 
-	// If init script failed, we've already printed the message - exit without console
 	if initScriptFailed {
-		return fmt.Errorf("init script failed")
+		return &sess, errInitScriptFailed
 	}
+	return &sess, nil
+}
 
-	// Print success message with session name
+// newBatchResult is the outcome of provisioning one session with new --count.
+type newBatchResult struct {
+	sess *session.Session
+	err  error
+}
+
+// runNewBatch provisions the named sessions from spec in parallel and prints
+// a summary. Per-step progress is not shown since the sessions interleave.
+// Ctrl-C stops starting new sessions and cleans up those in progress.
+func runNewBatch(ctx context.Context, spec *newSessionSpec, store session.Store, names []string) error {
+	provisionCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	results := make([]newBatchResult, len(names))
+	started := make([]bool, len(names))
+	jobs := make(chan int)
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+
+	workers := min(defaultNewParallel, len(names))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				sess, err := provisionSession(ctx, provisionCtx, spec, store, names[i], io.Discard, io.Discard)
+				results[i] = newBatchResult{sess: sess, err: err}
+
+				outputMu.Lock()
+				if err != nil {
+					ui.PrintError(os.Stderr, "%s: %v", names[i], err)
+				} else {
+					ui.PrintSuccess(os.Stdout, "%s is ready at %s", names[i], sess.IPAddress)
+				}
+				outputMu.Unlock()
+			}
+		}()
+	}
+feed:
+	for i := range names {
+		select {
+		case jobs <- i:
+			started[i] = true
+		case <-provisionCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	stopSignals()
+
+	// Print summary
+	failed := 0
+	table := ui.NewTable("NAME", "STATUS", "IP ADDRESS")
+	for i, r := range results {
+		status, ip := "running", "-"
+		switch {
+		case !started[i]:
+			status = "not started"
+		case errors.Is(r.err, errInitScriptFailed):
+			status = "init script failed"
+		case r.err != nil:
+			status = "failed"
+		}
+		if r.err != nil {
+			failed++
+		}
+		if r.sess != nil && r.sess.IPAddress != "" && (r.err == nil || errors.Is(r.err, errInitScriptFailed)) {
+			ip = r.sess.IPAddress
+		}
+		table.AddRow(names[i], status, ip)
+	}
 	fmt.Println()
-	fmt.Printf("Session created: %s\n", sessionID)
-	fmt.Printf("IP address: %s\n", vm.IPAddress)
+	table.Render(os.Stdout)
 
-	// Determine if we should start console automatically
-	isInteractive := term.IsTerminal(int(os.Stdin.Fd()))
-	shouldStartConsole := !noConsole && isInteractive
+	if provisionCtx.Err() != nil && ctx.Err() == nil {
+		return &exitError{code: 130}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d sessions", failed, len(names))
+	}
 
-	if shouldStartConsole {
-		fmt.Println("Connecting to console...")
-		fmt.Println()
+	fmt.Println()
+	fmt.Println("Use 'sandctl console <name>' to connect.")
+	return nil
+}
 
-		// Start SSH console
-		consoleErr := startSSHConsole(vm.IPAddress)
-		if consoleErr != nil {
-			fmt.Fprintln(os.Stderr)
-			fmt.Fprintf(os.Stderr, "Warning: Failed to connect to console: %v\n", consoleErr)
-			fmt.Fprintln(os.Stderr)
-			fmt.Fprintf(os.Stderr, "Session was created successfully. Use 'sandctl console %s' to connect manually.\n", sessionID)
+// reservedLabelKey is the label sandctl sets on every resource it creates.
+const reservedLabelKey = "managed-by"
+
+// labelNamePattern matches a label name or value: at most 63 characters,
+// starting and ending with an alphanumeric, with '-', '_' and '.' between.
+var labelNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+// labelPrefixPattern matches an optional label key prefix, a DNS subdomain.
+var labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// validateLabels checks --label values against the provider label syntax, so
+// a bad label fails before anything is provisioned rather than at VM create.
+// The reserved managed-by key is rejected since sandctl overwrites it.
+func validateLabels(labels map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		if key == reservedLabelKey {
+			return fmt.Errorf("invalid --label %s: the %s label is reserved for sandctl", key, reservedLabelKey)
+		}
+
+		name := key
+		if prefix, rest, ok := strings.Cut(key, "/"); ok {
+			if len(prefix) > 253 || !labelPrefixPattern.MatchString(prefix) {
+				return fmt.Errorf("invalid --label key %q: prefix must be a lowercase DNS subdomain", key)
+			}
+			name = rest
+		}
+		if !labelNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid --label key %q: must be at most 63 characters, start and end with a letter or digit, and contain only letters, digits, '-', '_' or '.'", key)
 		}
-	} else {
-		fmt.Println()
-		fmt.Printf("Use 'sandctl console %s' to connect.\n", sessionID)
-		fmt.Printf("Use 'sandctl destroy %s' when done.\n", sessionID)
-	}
 
+		if value := labels[key]; value != "" && !labelNamePattern.MatchString(value) {
+			return fmt.Errorf("invalid --label value %q for %s: must be empty or at most 63 characters, start and end with a letter or digit, and contain only letters, digits, '-', '_' or '.'", value, key)
+		}
+	}
 	return nil
 }
 
-// ensureSSHKey makes sure the user's SSH key is uploaded to the provider.
-func ensureSSHKey(ctx context.Context, cfg *config.Config, prov provider.Provider) (string, error) {
-	// Check if provider supports SSH key management
-	keyManager, ok := prov.(provider.SSHKeyManager)
+// parseProviderSets parses repeatable --set provider.key=value flags into a
+// map of setting to value. Every setting must be for providerName.
+func parseProviderSets(values []string, providerName string) (map[string]string, error) {
+	sets := make(map[string]string, len(values))
+	for _, v := range values {
+		setting, value, ok := strings.Cut(v, "=")
+		provName, key, hasKey := strings.Cut(setting, ".")
+		if !ok || !hasKey || provName == "" || key == "" {
+			return nil, fmt.Errorf("invalid --set value %q: expected provider.key=value", v)
+		}
+		if provName != providerName {
+			return nil, fmt.Errorf("--set %s is for provider %s, but this session uses %s", setting, provName, providerName)
+		}
+		sets[key] = value
+	}
+	return sets, nil
+}
+
+// withProviderOverrides returns a copy of cfg with sets applied to the
+// settings of providerName. cfg itself is left unchanged.
+func withProviderOverrides(cfg *config.Config, providerName string, sets map[string]string) (*config.Config, error) {
+	if len(sets) == 0 {
+		return cfg, nil
+	}
+
+	provCfg, ok := cfg.GetProviderConfig(providerName)
 	if !ok {
-		return "", fmt.Errorf("provider %s does not support SSH key management", prov.Name())
+		return nil, fmt.Errorf("provider %s is not configured", providerName)
+	}
+	for _, key := range slices.Sorted(maps.Keys(sets)) {
+		if err := provCfg.SetField(key, sets[key]); err != nil {
+			return nil, fmt.Errorf("invalid --set %s.%s: %w", providerName, key, err)
+		}
+	}
+
+	overridden := *cfg
+	overridden.Providers = maps.Clone(cfg.Providers)
+	overridden.Providers[providerName] = *provCfg
+	return &overridden, nil
+}
+
+// resolveSessionKey returns the public key a new session is set up with: the
+// --ssh-key override if given, otherwise the configured key. If
+// keepProviderKey is set, the provider key from --set ssh_key_id is installed
+// as is instead, and its ID is returned with no public key.
+func resolveSessionKey(cfg *config.Config, providerName string, sshKey *sessionSSHKey, keepProviderKey bool) (pubKeyData, sshKeyID string, err error) {
+	switch {
+	case keepProviderKey:
+		pc, _ := cfg.GetProviderConfig(providerName)
+		return "", strconv.FormatInt(pc.SSHKeyID, 10), nil
+	case sshKey != nil:
+		return sshKey.PublicKey, "", nil
 	}
 
-	// Get public key (from agent or file)
-	pubKeyData, err := cfg.GetSSHPublicKey()
+	pubKeyData, err = cfg.GetSSHPublicKey()
 	if err != nil {
-		return "", fmt.Errorf("failed to get SSH public key: %w", err)
+		return "", "", fmt.Errorf("failed to get SSH public key: %w", err)
 	}
+	return pubKeyData, "", nil
+}
 
-	// Generate a name for the key based on content hash
-	keyName := fmt.Sprintf("sandctl-%s", hashPrefix(pubKeyData, 8))
+// ensureSSHKey makes sure the given public key is uploaded to the provider.
+func ensureSSHKey(ctx context.Context, prov provider.Provider, pubKeyData string) (string, error) {
+	// Check if provider supports SSH key management
+	keyManager, ok := prov.(provider.SSHKeyManager)
+	if !ok {
+		return "", fmt.Errorf("provider %s does not support SSH key management", prov.Name())
+	}
 
 	// Ensure key exists in provider
-	keyID, err := keyManager.EnsureSSHKey(ctx, keyName, pubKeyData)
+	keyID, err := keyManager.EnsureSSHKey(ctx, sshKeyName(pubKeyData), pubKeyData)
 	if err != nil {
 		return "", err
 	}
@@ -346,6 +1054,41 @@ func ensureSSHKey(ctx context.Context, cfg *config.Config, prov provider.Provide
 	return keyID, nil
 }
 
+// checkProviderKey checks that the provider key installed with --set
+// ssh_key_id is the configured key sandctl connects with, since the VM
+// otherwise comes up with no key sandctl can log in with.
+func checkProviderKey(ctx context.Context, prov provider.Provider, cfg *config.Config, keyID string) error {
+	keyManager, ok := prov.(provider.SSHKeyManager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support SSH key management, so --set %s.ssh_key_id cannot be checked", prov.Name(), prov.Name())
+	}
+
+	localKey, err := cfg.GetSSHPublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to get SSH public key: %w", err)
+	}
+
+	keys, err := keyManager.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list SSH keys: %w", err)
+	}
+	for _, key := range keys {
+		if key.ID != keyID {
+			continue
+		}
+		if !samePublicKey(key.PublicKey, localKey) {
+			return fmt.Errorf("provider SSH key %s (%s) is not your configured SSH key, so sandctl could not connect to the VM; use --ssh-key with that key instead", keyID, key.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf("provider SSH key %s not found", keyID)
+}
+
+// sshKeyName returns the provider key name for a public key, based on its content hash.
+func sshKeyName(pubKeyData string) string {
+	return sshKeyPrefix + hashPrefix(pubKeyData, 8)
+}
+
 // hashPrefix returns a prefix of the MD5 hash of the input string.
 func hashPrefix(s string, n int) string {
 	h := md5.Sum([]byte(s)) //nolint:gosec // Not used for security, just for unique naming
@@ -356,80 +1099,424 @@ func hashPrefix(s string, n int) string {
 	return hexStr
 }
 
-// setupOpenCodeViaSSH installs and configures OpenCode via SSH.
-func setupOpenCodeViaSSH(ipAddress string, cfg *config.Config) error {
-	client, err := createSSHClient(ipAddress)
-	if err != nil {
-		return fmt.Errorf("failed to create SSH client: %w", err)
+// setupOpenCodeViaSSH installs and configures OpenCode via SSH. If OpenCode
+// is already installed with the configured key, nothing is changed.
+func setupOpenCodeViaSSH(client *sshexec.Client, cfg *config.Config) error {
+	if openCodeConfigured(client, cfg.OpencodeZenKey) {
+		return ui.SkipStep("OpenCode already configured")
 	}
-	defer client.Close()
 
 	// Install OpenCode
-	installCmd := "curl -fsSL https://opencode.ai/install | bash"
-	_, err = client.Exec(installCmd)
+	installCmd := "set -o pipefail; curl -fsSL https://opencode.ai/install | bash"
+	if _, err := client.Exec(installCmd); err != nil {
+		return fmt.Errorf("failed to install OpenCode: %w", err)
+	}
+
+	// Verify the binary is in place
+	if _, err := client.Exec(openCodeCheckCommand); err != nil {
+		return fmt.Errorf("OpenCode binary not found after install: %w", err)
+	}
+
+	return writeOpenCodeAuth(client, cfg.OpencodeZenKey)
+}
+
+// writeOpenCodeAuth replaces the OpenCode auth file on the VM with one
+// holding zenKey.
+func writeOpenCodeAuth(client *sshexec.Client, zenKey string) error {
+	writeCmd, err := openCodeAuthWriteCommand(zenKey)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Exec(writeCmd); err != nil {
+		return fmt.Errorf("failed to write OpenCode auth: %w", err)
+	}
+	return nil
+}
+
+// openCodeAuthWriteCommand returns the shell command that writes the auth
+// file for zenKey. The content is base64-encoded to handle special
+// characters in the key.
+func openCodeAuthWriteCommand(zenKey string) (string, error) {
+	authJSON, err := openCodeAuthJSON(zenKey)
 	if err != nil {
-		verboseLog("Warning: OpenCode installation failed: %v", err)
-		return nil // Non-fatal
+		return "", err
 	}
+	encoded := base64.StdEncoding.EncodeToString(authJSON)
+	return fmt.Sprintf(
+		"mkdir -p ~/.local/share/opencode && echo '%s' | base64 -d > %s && chmod 600 %s",
+		encoded, openCodeAuthPath, openCodeAuthPath,
+	), nil
+}
+
+// openCodeCheckCommand succeeds if the OpenCode binary is installed.
+const openCodeCheckCommand = `test -x "$HOME/.opencode/bin/opencode" || command -v opencode >/dev/null`
+
+// openCodeVersionCommand prints the installed OpenCode version. The install
+// directory isn't on PATH for non-login shells.
+const openCodeVersionCommand = `PATH="$HOME/.opencode/bin:$PATH" opencode --version`
 
-	// Create config directory
-	_, _ = client.Exec("mkdir -p ~/.local/share/opencode")
+// openCodeAuthPath is where OpenCode reads its credentials.
+const openCodeAuthPath = "~/.local/share/opencode/auth.json"
 
-	// Write auth file
-	authJSON := fmt.Sprintf(`{"opencode":{"type":"api","key":"%s"}}`, cfg.OpencodeZenKey)
-	writeCmd := fmt.Sprintf("echo '%s' > ~/.local/share/opencode/auth.json", authJSON)
-	_, err = client.Exec(writeCmd)
+// openCodeConfigured reports whether OpenCode is installed on the VM and its
+// auth file already holds zenKey.
+func openCodeConfigured(client *sshexec.Client, zenKey string) bool {
+	if _, err := client.Exec(openCodeCheckCommand); err != nil {
+		return false
+	}
+	data, err := client.Exec("cat " + openCodeAuthPath)
 	if err != nil {
-		verboseLog("Warning: Failed to write OpenCode auth: %v", err)
+		return false
 	}
+	return openCodeAuthHasKey([]byte(data), zenKey)
+}
 
+// openCodeAuthHasKey reports whether OpenCode auth.json content holds zenKey
+// for the opencode provider. Other entries in the file are ignored.
+func openCodeAuthHasKey(data []byte, zenKey string) bool {
+	var auth map[string]struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return false
+	}
+	return auth["opencode"].Key == zenKey
+}
+
+// openCodeAuthJSON builds the OpenCode auth.json content for a Zen key.
+func openCodeAuthJSON(zenKey string) ([]byte, error) {
+	auth := map[string]any{
+		"opencode": map[string]string{
+			"type": "api",
+			"key":  zenKey,
+		},
+	}
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenCode auth: %w", err)
+	}
+	return data, nil
+}
+
+// printCostEstimate prints the estimated hourly cost of the server type that
+// will be provisioned, and the total cost when a timeout is set.
+func printCostEstimate(cfg *config.Config, providerName, requestedType string, timeout *session.Duration) {
+	if providerName != "hetzner" {
+		return
+	}
+
+	effectiveType := requestedType
+	if effectiveType == "" {
+		if provCfg, ok := cfg.GetProviderConfig(providerName); ok && provCfg.ServerType != "" {
+			effectiveType = provCfg.ServerType
+		} else {
+			effectiveType = hetzner.DefaultServerType
+		}
+	}
+
+	hourly, ok := hetzner.EstimatedHourlyPrice(effectiveType)
+	if !ok {
+		verboseLog("No price estimate for server type %s", effectiveType)
+		return
+	}
+
+	if timeout != nil {
+		total := hourly * timeout.Hours()
+		fmt.Printf("Estimated cost: ~%s/hr for %s (~%s total for %v)\n",
+			formatEUR(hourly), effectiveType, formatEUR(total), timeout.Duration)
+		return
+	}
+	fmt.Printf("Estimated cost: ~%s/hr for %s\n", formatEUR(hourly), effectiveType)
+}
+
+// printDryRun prints the resolved creation plan and cloud-init script for
+// new --dry-run. Nothing is created and no session record is stored.
+func printDryRun(w io.Writer, prov provider.Provider, opts provider.CreateOpts, pubKeyData, sshKeyID string,
+	sshUser string, sshPort int, tmplConfig *templateconfig.TemplateConfig, timeout *session.Duration, ports []int) error {
+	opts = resolveCreateOpts(prov, opts)
+
+	keyName := sshKeyName(pubKeyData)
+	if sshKeyID != "" {
+		keyName = "provider key " + sshKeyID
+	}
+
+	template := "-"
+	if tmplConfig != nil {
+		template = tmplConfig.OriginalName
+		if getTemplateStore().HasInitScript(tmplConfig.Template) {
+			template += " (with init script)"
+		}
+	}
+	timeoutStr := "-"
+	if timeout != nil {
+		timeoutStr = timeout.String()
+	}
+
+	fmt.Fprintln(w, "Dry run: no resources will be created.")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Session:      %s\n", opts.Name)
+	fmt.Fprintf(w, "Provider:     %s\n", prov.Name())
+	fmt.Fprintf(w, "Region:       %s\n", valueOrDash(opts.Region))
+	fmt.Fprintf(w, "Server Type:  %s\n", valueOrDash(opts.ServerType))
+	fmt.Fprintf(w, "Image:        %s\n", valueOrDash(opts.Image))
+	fmt.Fprintf(w, "SSH Key:      %s\n", keyName)
+	fmt.Fprintf(w, "SSH User:     %s\n", sshUserOrDefault(sshUser))
+	fmt.Fprintf(w, "SSH Port:     %d\n", cmp.Or(sshPort, 22))
+	fmt.Fprintf(w, "Template:     %s\n", template)
+	fmt.Fprintf(w, "Labels:       %s\n", formatLabels(opts.Labels))
+	fmt.Fprintf(w, "Timeout:      %s\n", timeoutStr)
+	fmt.Fprintf(w, "Open Ports:   %s\n", formatPorts(ports))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Cloud-init script:")
+	fmt.Fprintln(w, opts.UserData)
 	return nil
 }
 
-// waitForCloudInit waits for cloud-init to complete by polling for the boot-finished file.
-func waitForCloudInit(ipAddress string, timeout time.Duration) error {
-	client, err := createSSHClient(ipAddress)
+// resolveTimeout returns the session timeout from --timeout, --until, or
+// --no-timeout, falling back to the configured default. A zero --timeout or
+// --no-timeout disables the timeout; nil means no timeout.
+func resolveTimeout(timeoutArg, until string, disable bool, defaultTimeout string, now time.Time) (*session.Duration, error) {
+	switch {
+	case disable:
+		return nil, nil
+	case until != "":
+		d, err := timeoutUntil(until, now)
+		if err != nil {
+			return nil, err
+		}
+		return &session.Duration{Duration: d}, nil
+	case timeoutArg != "":
+		d, err := time.ParseDuration(timeoutArg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout format: %w", err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid timeout: %s is negative", timeoutArg)
+		}
+		if d == 0 {
+			return nil, nil
+		}
+		return &session.Duration{Duration: d}, nil
+	case defaultTimeout != "":
+		d, err := time.ParseDuration(defaultTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default_timeout in config: %w", err)
+		}
+		if d <= 0 {
+			return nil, nil
+		}
+		return &session.Duration{Duration: d}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// timeoutUntil converts an RFC3339 deadline into a timeout relative to now.
+func timeoutUntil(deadline string, now time.Time) (time.Duration, error) {
+	t, err := time.Parse(time.RFC3339, deadline)
 	if err != nil {
-		return fmt.Errorf("failed to create SSH client: %w", err)
+		return 0, fmt.Errorf("invalid --until time (expected RFC3339, e.g. 2025-06-01T18:00:00Z): %w", err)
 	}
-	defer client.Close()
 
-	deadline := time.Now().Add(timeout)
-	pollInterval := 5 * time.Second
+	d := t.Sub(now)
+	if d <= 0 {
+		return 0, fmt.Errorf("--until time %s is not in the future", deadline)
+	}
+	return d, nil
+}
+
+// createParams holds the parameters a session is created with.
+type createParams struct {
+	Provider   string
+	Region     string
+	ServerType string
+	Image      string
+	Template   string
+	SSHUser    string
+	SSHPort    int
+	Labels     map[string]string
+}
+
+// withDefaults returns p with unset fields copied from the source session.
+// Labels are merged, with labels already in p taking precedence.
+func (p createParams) withDefaults(source *session.Session) createParams {
+	p.Provider = firstNonEmpty(p.Provider, source.Provider)
+	p.Region = firstNonEmpty(p.Region, source.Region)
+	p.ServerType = firstNonEmpty(p.ServerType, source.ServerType)
+	p.Image = firstNonEmpty(p.Image, source.Image)
+	p.Template = firstNonEmpty(p.Template, source.Template)
+	p.SSHUser = firstNonEmpty(p.SSHUser, source.SSHUser)
+	p.SSHPort = cmp.Or(p.SSHPort, source.SSHPort)
+
+	if len(source.Labels) > 0 {
+		merged := maps.Clone(source.Labels)
+		maps.Copy(merged, p.Labels)
+		p.Labels = merged
+	}
+	return p
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resolveSessionName returns the normalized requested name if it is valid and
+// unused, or a newly generated name when none was requested.
+func resolveSessionName(requested string, usedNames []string) (string, error) {
+	if requested == "" {
+		name, err := session.GenerateID(usedNames)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate session name: %w", err)
+		}
+		return name, nil
+	}
+
+	name := session.NormalizeName(requested)
+	if !session.ValidateID(name) {
+		return "", fmt.Errorf("invalid session name '%s': must be 2-15 letters, optionally followed by a number like -2", requested)
+	}
+
+	for _, used := range usedNames {
+		if session.NormalizeName(used) == name {
+			return "", fmt.Errorf("session '%s' already exists. Use 'sandctl destroy %s' first or choose another name", name, name)
+		}
+	}
+
+	return name, nil
+}
+
+// cancellableSteps wraps steps so that each one fails with ctx's error instead
+// of starting once ctx is done.
+func cancellableSteps(ctx context.Context, steps []ui.ProgressStep) []ui.ProgressStep {
+	wrapped := make([]ui.ProgressStep, len(steps))
+	for i, step := range steps {
+		wrapped[i] = ui.ProgressStep{
+			Message: step.Message,
+			ActionWithStatus: func(setStatus func(string)) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if step.ActionWithStatus != nil {
+					return step.ActionWithStatus(setStatus)
+				}
+				return step.Action()
+			},
+		}
+	}
+	return wrapped
+}
+
+// readyStatus describes a state reported while waiting for a VM.
+func readyStatus(state provider.ReadyState) string {
+	switch state {
+	case provider.ReadyInitializing:
+		return "creating server"
+	case provider.ReadyStarting:
+		return "booting"
+	case provider.ReadyRunning:
+		return "waiting for SSH"
+	default:
+		return string(state)
+	}
+}
+
+// waitForSSH waits for sshd on the VM to accept connections, until ctx's
+// deadline or sshReadyTimeout if it has none.
+func waitForSSH(ctx context.Context, client *sshexec.Client) error {
+	timeout := pollTimeout(ctx, sshReadyTimeout)
+
+	var lastErr error
+	err := provider.DefaultBackoff().Poll(ctx, timeout, func() (bool, error) {
+		lastErr = client.Connect()
+		if lastErr != nil {
+			verboseLog("SSH not ready: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if errors.Is(err, provider.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("SSH did not become available within %v: %w", timeout.Round(time.Second), lastErr)
+	}
+	return err
+}
+
+// waitForCloudInit waits for cloud-init to complete by polling for the
+// boot-finished file, until ctx's deadline or cloudInitTimeout if it has none.
+func waitForCloudInit(ctx context.Context, client *sshexec.Client) error {
+	timeout := pollTimeout(ctx, cloudInitTimeout)
 
-	for time.Now().Before(deadline) {
+	err := provider.DefaultBackoff().Poll(ctx, timeout, func() (bool, error) {
 		// Check if cloud-init has finished
-		output, err := client.Exec("test -f /var/lib/cloud/instance/boot-finished && echo done")
+		output, err := client.ExecContext(ctx, "test -f /var/lib/cloud/instance/boot-finished && echo done")
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
 		if err != nil {
 			verboseLog("cloud-init check failed: %v", err)
-		} else {
-			verboseLog("cloud-init check output: %q", output)
+			return false, nil
 		}
-		if err == nil && output == "done\n" {
-			return nil
-		}
-		time.Sleep(pollInterval)
+		verboseLog("cloud-init check output: %q", output)
+		return output == "done\n", nil
+	})
+	if errors.Is(err, provider.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("cloud-init did not complete within %v", timeout.Round(time.Second))
 	}
+	return err
+}
 
-	return fmt.Errorf("cloud-init did not complete within %v", timeout)
+// pollTimeout returns the time left until ctx's deadline, or fallback if ctx
+// has none.
+func pollTimeout(ctx context.Context, fallback time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return fallback
 }
 
-// cleanupFailedSession removes a session that failed to provision.
-func cleanupFailedSession(ctx context.Context, prov provider.Provider, store *session.Store, sessionID string, vm *provider.VM) {
+// cleanupFailedSession removes what was created for a session that failed to
+// provision and records it as failed, with sess.FailureReason or a generic
+// reason. The IDs of resources that couldn't be deleted are kept.
+func cleanupFailedSession(ctx context.Context, prov provider.Provider, store session.Store, sess *session.Session, vm *provider.VM) {
+	sessionID := sess.ID
 	verboseLog("Cleaning up failed session: %s", sessionID)
 
 	// Try to delete the VM if it was created
 	if vm != nil && vm.ID != "" {
-		_ = prov.Delete(ctx, vm.ID)
+		if err := prov.Delete(ctx, vm.ID); err != nil {
+			logger.Warn("failed to delete VM during cleanup", "session", sessionID, "vm", vm.ID, "error", err)
+		}
+	}
+
+	if err := deleteSessionFirewall(ctx, prov, sess.FirewallID); err != nil {
+		logger.Warn("failed to delete firewall during cleanup", "session", sessionID, "firewall", sess.FirewallID, "error", err)
+	} else {
+		sess.FirewallID = ""
+	}
+
+	if err := deleteSessionVolume(ctx, prov, sess.VolumeID); err != nil {
+		logger.Warn("failed to delete volume during cleanup", "session", sessionID, "volume", sess.VolumeID, "error", err)
+	} else {
+		sess.VolumeID = ""
+		sess.VolumeSizeGB = 0
 	}
 
 	// Update local store to failed status
-	_ = store.Update(sessionID, session.StatusFailed)
+	sess.Status = session.StatusFailed
+	sess.FailureReason = cmp.Or(sess.FailureReason, session.FailureProvisioning)
+	if err := store.UpdateSession(*sess); err != nil {
+		logger.Warn("failed to mark session as failed", "session", sessionID, "error", err)
+	}
 }
 
-// startSSHConsole opens an interactive SSH console to the VM.
-func startSSHConsole(ipAddress string) error {
-	client, err := createSSHClient(ipAddress)
+// startSSHConsole opens an interactive SSH console to the session's VM.
+func startSSHConsole(sess *session.Session) error {
+	client, err := createSessionSSHClient(sess, sessionAddress(sess))
 	if err != nil {
 		return fmt.Errorf("failed to create SSH client: %w", err)
 	}
@@ -440,46 +1527,45 @@ func startSSHConsole(ipAddress string) error {
 
 // runTemplateInitScript uploads and executes a custom init script on the VM.
 // The script runs from the home directory with template info passed as environment variables.
-func runTemplateInitScript(ipAddress string, tmplConfig *templateconfig.TemplateConfig, scriptContent string) error {
-	client, err := createSSHClient(ipAddress)
-	if err != nil {
-		return fmt.Errorf("failed to create SSH client: %w", err)
+func runTemplateInitScript(client *sshexec.Client, tmplConfig *templateconfig.TemplateConfig, scriptContent string, stdout, stderr io.Writer) error {
+	if err := uploadFile(client, []byte(scriptContent), "/tmp/sandctl-init.sh", 0700, "Uploading init script"); err != nil {
+		return fmt.Errorf("failed to upload init script: %w", err)
 	}
-	defer client.Close()
 
-	// Upload the script using base64 encoding to handle special characters
-	encoded := base64.StdEncoding.EncodeToString([]byte(scriptContent))
-	uploadCmd := fmt.Sprintf("echo '%s' | base64 -d > /tmp/sandctl-init.sh && chmod +x /tmp/sandctl-init.sh", encoded)
-	_, err = client.Exec(uploadCmd)
+	// Export the template's custom env, then template info (which takes precedence)
+	env := make(map[string]string, len(tmplConfig.Env)+2)
+	for k, v := range tmplConfig.Env {
+		env[k] = v
+	}
+	env["SANDCTL_TEMPLATE_NAME"] = tmplConfig.OriginalName
+	env["SANDCTL_TEMPLATE_NORMALIZED"] = tmplConfig.Template
+
+	exports, err := sshexec.ExportEnv(env)
 	if err != nil {
-		return fmt.Errorf("failed to upload init script: %w", err)
+		return fmt.Errorf("invalid template env: %w", err)
 	}
 
-	// Execute the script with template info as environment variables
+	// Execute the script, saving its combined output on the VM so
+	// 'sandctl logs' can show it later
 	execCmd := fmt.Sprintf(
-		"SANDCTL_TEMPLATE_NAME=%q SANDCTL_TEMPLATE_NORMALIZED=%q /tmp/sandctl-init.sh",
-		tmplConfig.OriginalName,
-		tmplConfig.Template,
+		"set -o pipefail; %s /tmp/sandctl-init.sh 2>&1 | sudo tee %s",
+		exports,
+		initScriptLogPath,
 	)
-	err = client.ExecWithStreams(execCmd, nil, os.Stdout, os.Stderr)
-	if err != nil {
+	if err := client.ExecWithStreams(execCmd, nil, stdout, stderr); err != nil {
 		return fmt.Errorf("script execution failed: %w", err)
 	}
 
 	// Clean up the temp script
-	_, _ = client.Exec("rm -f /tmp/sandctl-init.sh")
+	if _, err := client.Exec("rm -f /tmp/sandctl-init.sh"); err != nil {
+		logger.Warn("failed to remove init script", "error", err)
+	}
 
 	return nil
 }
 
-// setupGitConfigViaSSH configures git in the sandbox via SSH.
-func setupGitConfigViaSSH(ipAddress string, cfg *config.Config) error {
-	client, err := createSSHClient(ipAddress)
-	if err != nil {
-		return fmt.Errorf("failed to create SSH client: %w", err)
-	}
-	defer client.Close()
-
+// setupGitConfigViaSSH configures git for user in the sandbox via SSH.
+func setupGitConfigViaSSH(client *sshexec.Client, cfg *config.Config, user string) error {
 	gitCfg, err := cfg.GetGitConfig()
 	if err != nil {
 		return fmt.Errorf("failed to get git config: %w", err)
@@ -498,47 +1584,46 @@ func setupGitConfigViaSSH(ipAddress string, cfg *config.Config) error {
 		gitConfigContent = fmt.Sprintf("[user]\n\tname = %s\n\temail = %s\n", gitCfg.UserName, gitCfg.UserEmail)
 	}
 
-	// Base64 encode and transfer via SSH
-	encoded := base64.StdEncoding.EncodeToString([]byte(gitConfigContent))
-	writeCmd := fmt.Sprintf("echo '%s' | base64 -d > /home/agent/.gitconfig", encoded)
-	_, err = client.Exec(writeCmd)
-	if err != nil {
+	user = sshUserOrDefault(user)
+	gitConfigPath := path.Join(remoteHomeDir(user), ".gitconfig")
+
+	// Leave an identical gitconfig alone, e.g. on a re-provisioned VM
+	if existing, err := client.Exec("cat " + sshexec.ShellQuote(gitConfigPath)); err == nil && existing == gitConfigContent {
+		return ui.SkipStep("Git already configured")
+	}
+
+	if err := client.TransferFile([]byte(gitConfigContent), gitConfigPath, 0644); err != nil {
 		return fmt.Errorf("failed to write gitconfig: %w", err)
 	}
 
 	// Set correct ownership and permissions
-	_, err = client.Exec("chown agent:agent /home/agent/.gitconfig && chmod 644 /home/agent/.gitconfig")
+	_, err = client.Exec(fmt.Sprintf("chown %[1]s:%[1]s %[2]s && chmod 644 %[2]s",
+		sshexec.ShellQuote(user), sshexec.ShellQuote(gitConfigPath)))
 	if err != nil {
-		verboseLog("Warning: failed to set gitconfig permissions: %v", err)
+		logger.Warn("failed to set gitconfig permissions", "error", err)
 	}
 
 	return nil
 }
 
-// setupGitHubCLIViaSSH authenticates GitHub CLI in the sandbox via SSH.
-func setupGitHubCLIViaSSH(ipAddress string, cfg *config.Config) error {
+// setupGitHubCLIViaSSH authenticates GitHub CLI for user in the sandbox via SSH.
+func setupGitHubCLIViaSSH(client *sshexec.Client, cfg *config.Config, user string) error {
 	if !cfg.HasGitHubToken() {
 		return nil // No token to set up
 	}
 
-	client, err := createSSHClient(ipAddress)
-	if err != nil {
-		return fmt.Errorf("failed to create SSH client: %w", err)
-	}
-	defer client.Close()
+	sudoUser := "sudo -u " + sshexec.ShellQuote(sshUserOrDefault(user))
 
 	// Authenticate gh CLI by passing token via stdin
 	// Use a here-document to avoid exposing the token in process arguments
-	authCmd := fmt.Sprintf("echo '%s' | sudo -u agent gh auth login --with-token --hostname github.com", cfg.GitHubToken)
-	_, err = client.Exec(authCmd)
-	if err != nil {
+	authCmd := fmt.Sprintf("echo '%s' | %s gh auth login --with-token --hostname github.com", cfg.GitHubToken, sudoUser)
+	if _, err := client.Exec(authCmd); err != nil {
 		return fmt.Errorf("failed to authenticate GitHub CLI: %w", err)
 	}
 
 	// Configure git to use gh for HTTPS credentials
-	_, err = client.Exec("sudo -u agent gh auth setup-git")
-	if err != nil {
-		verboseLog("Warning: failed to setup gh as git credential helper: %v", err)
+	if _, err := client.Exec(sudoUser + " gh auth setup-git"); err != nil {
+		logger.Warn("failed to setup gh as git credential helper", "error", err)
 	}
 
 	return nil
@@ -0,0 +1,990 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/hetzner"
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/sshagent"
+	"github.com/sandctl/sandctl/internal/sshexec"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+// useTestSSHConfig points the shared config at a freshly generated key pair
+// in file mode and restores the previous config when the test ends.
+func useTestSSHConfig(t *testing.T) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write private key: %v", err)
+	}
+	if err := os.WriteFile(keyPath+".pub", ssh.MarshalAuthorizedKey(signer.PublicKey()), 0600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+
+	oldCfg := cfg
+	cfg = &config.Config{SSHPublicKey: keyPath + ".pub"}
+	t.Cleanup(func() { cfg = oldCfg })
+}
+
+// TestWaitForSSH_GivenHandshakeNeverSucceeds_ThenReturnsTimeoutError tests retry exhaustion.
+func TestWaitForSSH_GivenHandshakeNeverSucceeds_ThenReturnsTimeoutError(t *testing.T) {
+	useTestSSHConfig(t)
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	// Accept connections and immediately close them so the handshake fails
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	var attempts atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			attempts.Add(1)
+			conn.Close()
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	client, err := createSSHClient("127.0.0.1", sshexec.WithPort(port), sshexec.WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("createSSHClient() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = waitForSSH(ctx, client)
+	if err == nil {
+		t.Fatal("expected error when SSH never becomes available")
+	}
+	if !strings.Contains(err.Error(), "did not become available") {
+		t.Errorf("error = %q, want it to mention availability", err.Error())
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("waitForSSH took %v, expected it to respect the timeout", time.Since(start))
+	}
+	if n := attempts.Load(); n < 2 {
+		t.Errorf("expected multiple connection attempts, got %d", n)
+	}
+}
+
+// TestCreateSessionSSHClient_GivenSessionPort_ThenConnectsToPort tests that a session's SSH port is used.
+func TestCreateSessionSSHClient_GivenSessionPort_ThenConnectsToPort(t *testing.T) {
+	useTestSSHConfig(t)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	t.Setenv("HOME", t.TempDir())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	var attempts atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			attempts.Add(1)
+			conn.Close()
+		}
+	}()
+
+	sess := &session.Session{ID: "alice", SSHPort: listener.Addr().(*net.TCPAddr).Port}
+	client, err := createSessionSSHClient(sess, "127.0.0.1", sshexec.WithTimeout(time.Second), sshexec.WithConnectAttempts(1))
+	if err != nil {
+		t.Fatalf("createSessionSSHClient() error = %v", err)
+	}
+	defer client.Close()
+
+	_ = client.Connect()
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("got %d connections on the session port, want 1", n)
+	}
+}
+
+// TestResolveSessionName_GivenRequestedName_ThenReturnsNormalized tests explicit names.
+func TestResolveSessionName_GivenRequestedName_ThenReturnsNormalized(t *testing.T) {
+	name, err := resolveSessionName("  Alice ", []string{"bob"})
+	if err != nil {
+		t.Fatalf("resolveSessionName() error = %v", err)
+	}
+	if name != "alice" {
+		t.Errorf("resolveSessionName() = %q, want %q", name, "alice")
+	}
+}
+
+// TestResolveSessionName_GivenUsedName_ThenReturnsConflictError tests name conflicts.
+func TestResolveSessionName_GivenUsedName_ThenReturnsConflictError(t *testing.T) {
+	_, err := resolveSessionName("Alice", []string{"alice"})
+	if err == nil {
+		t.Fatal("expected error for name already in use")
+	}
+	if !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("error = %q, want it to mention conflict", err.Error())
+	}
+}
+
+// TestResolveSessionName_GivenInvalidName_ThenReturnsError tests name validation.
+func TestResolveSessionName_GivenInvalidName_ThenReturnsError(t *testing.T) {
+	invalid := []string{"a", "my_proj", "abc123", "averyveryverylongname"}
+
+	for _, name := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := resolveSessionName(name, nil); err == nil {
+				t.Errorf("expected error for %q", name)
+			}
+		})
+	}
+}
+
+// TestResolveSessionName_GivenEmpty_ThenGeneratesName tests fallback to generation.
+func TestResolveSessionName_GivenEmpty_ThenGeneratesName(t *testing.T) {
+	name, err := resolveSessionName("", nil)
+	if err != nil {
+		t.Fatalf("resolveSessionName() error = %v", err)
+	}
+	if !session.ValidateID(name) {
+		t.Errorf("generated name %q is not a valid session ID", name)
+	}
+}
+
+// useTestAgent serves keyring as the only SSH agent, on a socket in dir,
+// until the test ends.
+func useTestAgent(t *testing.T, dir string, keyring agent.Agent) {
+	t.Helper()
+
+	sockPath := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	t.Setenv("HOME", dir)
+	t.Setenv("SSH_AUTH_SOCK", sockPath)
+}
+
+// testSSHServer is an SSH server that records the commands it is asked to
+// run and completes each of them successfully with no output.
+type testSSHServer struct {
+	port int
+
+	mu       sync.Mutex
+	commands []string
+}
+
+// startTestSSHServer starts a testSSHServer that accepts only authorizedKey,
+// or any key if it is nil, until the test ends.
+func startTestSSHServer(t *testing.T, authorizedKey ssh.PublicKey) *testSSHServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+
+	serverCfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorizedKey != nil && !bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, errors.New("unauthorized key")
+			}
+			return nil, nil
+		},
+	}
+	serverCfg.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	srv := &testSSHServer{port: listener.Addr().(*net.TCPAddr).Port}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn, serverCfg)
+		}
+	}()
+	return srv
+}
+
+// serve handles the exec requests of a single connection.
+func (s *testSSHServer) serve(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newCh := range chans {
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range chReqs {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				_ = ssh.Unmarshal(req.Payload, &payload)
+				req.Reply(true, nil)
+
+				s.mu.Lock()
+				s.commands = append(s.commands, payload.Command)
+				s.mu.Unlock()
+
+				_, _ = io.Copy(io.Discard, ch)
+				_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+				ch.Close()
+			}
+		}()
+	}
+}
+
+// Commands returns the commands run so far.
+func (s *testSSHServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.commands)
+}
+
+// newTestSSHServerClient starts a testSSHServer accepting any key and
+// returns a client for it.
+func newTestSSHServerClient(t *testing.T) (*testSSHServer, *sshexec.Client) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	srv := startTestSSHServer(t, nil)
+	client := sshexec.NewClientWithSigner("127.0.0.1", signer,
+		sshexec.WithPort(srv.port), sshexec.WithTimeout(5*time.Second), sshexec.WithConnectAttempts(1))
+	t.Cleanup(func() { client.Close() })
+	return srv, client
+}
+
+// TestSetupGitConfigViaSSH_GivenCustomUser_ThenWritesToUserHome tests that
+// the gitconfig goes to the session user's home directory.
+func TestSetupGitConfigViaSSH_GivenCustomUser_ThenWritesToUserHome(t *testing.T) {
+	srv, client := newTestSSHServerClient(t)
+	gitCfg := &config.Config{GitUserName: "Dev", GitUserEmail: "dev@example.com"}
+
+	if err := setupGitConfigViaSSH(client, gitCfg, "ubuntu"); err != nil {
+		t.Fatalf("setupGitConfigViaSSH() error = %v", err)
+	}
+
+	commands := strings.Join(srv.Commands(), "\n")
+	for _, want := range []string{"/home/ubuntu/.gitconfig", "chown 'ubuntu':'ubuntu'"} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("commands = %q, want them to contain %q", commands, want)
+		}
+	}
+	if strings.Contains(commands, "agent") {
+		t.Errorf("commands = %q, should not reference the default user", commands)
+	}
+}
+
+// TestSetupGitHubCLIViaSSH_GivenCustomUser_ThenRunsGhAsUser tests that gh is
+// authenticated for the session user.
+func TestSetupGitHubCLIViaSSH_GivenCustomUser_ThenRunsGhAsUser(t *testing.T) {
+	srv, client := newTestSSHServerClient(t)
+	ghCfg := &config.Config{GitHubToken: "ghp_test"}
+
+	if err := setupGitHubCLIViaSSH(client, ghCfg, "ubuntu"); err != nil {
+		t.Fatalf("setupGitHubCLIViaSSH() error = %v", err)
+	}
+
+	commands := srv.Commands()
+	if len(commands) != 2 {
+		t.Fatalf("got %d commands, want 2: %q", len(commands), commands)
+	}
+	for _, command := range commands {
+		if !strings.Contains(command, "sudo -u 'ubuntu' gh auth") {
+			t.Errorf("command %q does not run gh as the session user", command)
+		}
+	}
+}
+
+// TestCreateSSHClient_GivenFileModeKeyOnlyInAgent_ThenUsesAgent tests the agent fallback
+// for passphrase-protected keys whose private key file can't be used directly.
+// The agent also holds another key, listed first, so only a lookup by the
+// configured public key picks the one the server accepts.
+func TestCreateSSHClient_GivenFileModeKeyOnlyInAgent_ThenUsesAgent(t *testing.T) {
+	keyring := agent.NewKeyring()
+	var signers []ssh.Signer
+	for range 2 {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		signer, err := ssh.NewSignerFromKey(priv)
+		if err != nil {
+			t.Fatalf("failed to create signer: %v", err)
+		}
+		if err := keyring.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+			t.Fatalf("failed to add key to agent: %v", err)
+		}
+		signers = append(signers, signer)
+	}
+	configured := signers[1].PublicKey()
+
+	// Only the public key is on disk, under a name without a matching
+	// private key path; the private key lives in the agent
+	dir := t.TempDir()
+	pubKeyPath := filepath.Join(dir, "sandbox_key.pem")
+	if err := os.WriteFile(pubKeyPath, ssh.MarshalAuthorizedKey(configured), 0600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+	useTestAgent(t, dir, keyring)
+
+	oldCfg := cfg
+	cfg = &config.Config{SSHPublicKey: pubKeyPath}
+	t.Cleanup(func() { cfg = oldCfg })
+
+	srv := startTestSSHServer(t, configured)
+	client, err := createSSHClient("127.0.0.1",
+		sshexec.WithPort(srv.port), sshexec.WithTimeout(5*time.Second), sshexec.WithConnectAttempts(1))
+	if err != nil {
+		t.Fatalf("createSSHClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v, want the agent key matching the public key to be used", err)
+	}
+}
+
+// TestOpenCodeAuthHasKey_GivenAuthFile_ThenMatchesConfiguredKey tests the OpenCode setup skip check.
+func TestOpenCodeAuthHasKey_GivenAuthFile_ThenMatchesConfiguredKey(t *testing.T) {
+	written, err := openCodeAuthJSON("zen-key")
+	if err != nil {
+		t.Fatalf("openCodeAuthJSON() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"written by sandctl", string(written), true},
+		{"other providers kept", `{"anthropic":{"type":"oauth"},"opencode":{"type":"api","key":"zen-key"}}`, true},
+		{"different key", `{"opencode":{"type":"api","key":"old-key"}}`, false},
+		{"no opencode entry", `{"anthropic":{"type":"oauth"}}`, false},
+		{"not json", "cat: no such file", false},
+	}
+
+	for _, tt := range tests {
+		if got := openCodeAuthHasKey([]byte(tt.data), "zen-key"); got != tt.want {
+			t.Errorf("%s: openCodeAuthHasKey() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestOpenCodeAuthJSON_GivenSpecialCharacters_ThenProducesValidJSON tests auth escaping.
+func TestOpenCodeAuthJSON_GivenSpecialCharacters_ThenProducesValidJSON(t *testing.T) {
+	key := `ab"c'd\e$f`
+
+	data, err := openCodeAuthJSON(key)
+	if err != nil {
+		t.Fatalf("openCodeAuthJSON() error = %v", err)
+	}
+
+	var auth struct {
+		OpenCode struct {
+			Type string `json:"type"`
+			Key  string `json:"key"`
+		} `json:"opencode"`
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		t.Fatalf("auth JSON is invalid: %v (%s)", err, data)
+	}
+	if auth.OpenCode.Key != key {
+		t.Errorf("key = %q, want %q", auth.OpenCode.Key, key)
+	}
+	if auth.OpenCode.Type != "api" {
+		t.Errorf("type = %q, want %q", auth.OpenCode.Type, "api")
+	}
+}
+
+// TestCreateParams_WithDefaults_GivenSource_ThenFillsUnsetFields tests --from cloning.
+func TestCreateParams_WithDefaults_GivenSource_ThenFillsUnsetFields(t *testing.T) {
+	source := &session.Session{
+		ID:         "alice",
+		Provider:   "hetzner",
+		ProviderID: "42",
+		IPAddress:  "203.0.113.10",
+		Region:     "hel1",
+		ServerType: "cpx41",
+		Image:      "ubuntu-24.04",
+		Template:   "ghost",
+		Labels:     map[string]string{"project": "ghost", "env": "dev"},
+	}
+
+	params := createParams{
+		ServerType: "cpx21",
+		Labels:     map[string]string{"env": "staging"},
+	}.withDefaults(source)
+
+	if params.Provider != "hetzner" || params.Region != "hel1" || params.Image != "ubuntu-24.04" || params.Template != "ghost" {
+		t.Errorf("withDefaults() = %+v, want unset fields copied from source", params)
+	}
+	if params.ServerType != "cpx21" {
+		t.Errorf("ServerType = %q, want explicit value %q", params.ServerType, "cpx21")
+	}
+	if params.Labels["project"] != "ghost" || params.Labels["env"] != "staging" {
+		t.Errorf("Labels = %v, want source labels overridden by explicit labels", params.Labels)
+	}
+	if source.Labels["env"] != "dev" {
+		t.Error("withDefaults() modified the source session's labels")
+	}
+}
+
+// TestTimeoutUntil_GivenDeadline_ThenReturnsDurationFromNow tests --until parsing.
+func TestTimeoutUntil_GivenDeadline_ThenReturnsDurationFromNow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 16, 0, 0, 0, time.UTC)
+
+	d, err := timeoutUntil("2025-06-01T18:30:00Z", now)
+	if err != nil {
+		t.Fatalf("timeoutUntil() error = %v", err)
+	}
+	if d != 150*time.Minute {
+		t.Errorf("timeoutUntil() = %v, want %v", d, 150*time.Minute)
+	}
+
+	d, err = timeoutUntil("2025-06-01T19:00:00+02:00", now)
+	if err != nil {
+		t.Fatalf("timeoutUntil() with offset error = %v", err)
+	}
+	if d != time.Hour {
+		t.Errorf("timeoutUntil() with offset = %v, want %v", d, time.Hour)
+	}
+}
+
+// TestTimeoutUntil_GivenInvalidOrPastDeadline_ThenReturnsError tests --until validation.
+func TestTimeoutUntil_GivenInvalidOrPastDeadline_ThenReturnsError(t *testing.T) {
+	now := time.Date(2025, 6, 1, 16, 0, 0, 0, time.UTC)
+
+	for _, deadline := range []string{"tomorrow", "2025-06-01 18:00", "2025-06-01T15:00:00Z", "2025-06-01T16:00:00Z"} {
+		if _, err := timeoutUntil(deadline, now); err == nil {
+			t.Errorf("timeoutUntil(%q) expected error", deadline)
+		}
+	}
+}
+
+// TestResolveTimeout_GivenFlagsAndDefault_ThenAppliesPrecedence tests timeout resolution.
+func TestResolveTimeout_GivenFlagsAndDefault_ThenAppliesPrecedence(t *testing.T) {
+	now := time.Date(2025, 6, 1, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		timeout  string
+		until    string
+		disable  bool
+		def      string
+		expected time.Duration // 0 means no timeout
+	}{
+		{"nothing set", "", "", false, "", 0},
+		{"config default", "", "", false, "8h", 8 * time.Hour},
+		{"flag overrides default", "2h", "", false, "8h", 2 * time.Hour},
+		{"until overrides default", "", "2025-06-01T17:00:00Z", false, "8h", time.Hour},
+		{"zero timeout opts out", "0", "", false, "8h", 0},
+		{"no-timeout opts out", "", "", true, "8h", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTimeout(tt.timeout, tt.until, tt.disable, tt.def, now)
+			if err != nil {
+				t.Fatalf("resolveTimeout() error = %v", err)
+			}
+			if tt.expected == 0 {
+				if got != nil {
+					t.Errorf("resolveTimeout() = %v, want no timeout", got.Duration)
+				}
+				return
+			}
+			if got == nil || got.Duration != tt.expected {
+				t.Errorf("resolveTimeout() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	for _, bad := range []string{"soon", "-1h"} {
+		if _, err := resolveTimeout(bad, "", false, "", now); err == nil {
+			t.Errorf("resolveTimeout(%q) expected error", bad)
+		}
+	}
+}
+
+// TestPrintDryRun_GivenOpts_ThenPrintsResolvedPlanAndCloudInit tests new --dry-run output.
+func TestPrintDryRun_GivenOpts_ThenPrintsResolvedPlanAndCloudInit(t *testing.T) {
+	testCfg := &config.Config{
+		DefaultProvider:    "hetzner",
+		SSHKeySource:       "agent",
+		SSHPublicKeyInline: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIExample test@example",
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "token", Region: "hel1"},
+		},
+	}
+	prov, err := hetzner.NewProvider(testCfg)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	opts := provider.CreateOpts{Name: "alice", ServerType: "cpx41", UserData: "#cloud-config-test"}
+
+	pubKeyData, sshKeyID, err := resolveSessionKey(testCfg, "hetzner", nil, false)
+	if err != nil {
+		t.Fatalf("resolveSessionKey() error = %v", err)
+	}
+
+	var buf strings.Builder
+	if err := printDryRun(&buf, prov, opts, pubKeyData, sshKeyID, "", 2222, nil, nil, []int{3000}); err != nil {
+		t.Fatalf("printDryRun() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"alice",
+		"hel1",
+		"cpx41",
+		hetzner.DefaultImage,
+		sshKeyName(testCfg.SSHPublicKeyInline),
+		"2222",
+		"#cloud-config-test",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+// TestResolveSessionKey_GivenOverrides_ThenPrefersThem tests that --ssh-key and
+// --set ssh_key_id take precedence over the configured key.
+func TestResolveSessionKey_GivenOverrides_ThenPrefersThem(t *testing.T) {
+	testCfg := &config.Config{
+		SSHKeySource:       "agent",
+		SSHPublicKeyInline: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIExample test@example",
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "token", SSHKeyID: 42},
+		},
+	}
+	override := &sessionSSHKey{PublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOverride other@example"}
+
+	tests := []struct {
+		name            string
+		sshKey          *sessionSSHKey
+		keepProviderKey bool
+		wantKey         string
+		wantID          string
+	}{
+		{"configured key", nil, false, testCfg.SSHPublicKeyInline, ""},
+		{"--ssh-key", override, false, override.PublicKey, ""},
+		{"--set ssh_key_id", nil, true, "", "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubKeyData, sshKeyID, err := resolveSessionKey(testCfg, "hetzner", tt.sshKey, tt.keepProviderKey)
+			if err != nil {
+				t.Fatalf("resolveSessionKey() error = %v", err)
+			}
+			if pubKeyData != tt.wantKey || sshKeyID != tt.wantID {
+				t.Errorf("resolveSessionKey() = %q, %q, want %q, %q", pubKeyData, sshKeyID, tt.wantKey, tt.wantID)
+			}
+		})
+	}
+}
+
+// TestWithProviderOverrides_GivenSets_ThenOverlaysCopy tests --set handling.
+func TestWithProviderOverrides_GivenSets_ThenOverlaysCopy(t *testing.T) {
+	base := &config.Config{
+		DefaultProvider: "hetzner",
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "token", Region: "hel1"},
+		},
+	}
+
+	sets, err := parseProviderSets([]string{
+		"hetzner.network=internal",
+		"hetzner.placement_group=spread",
+		"hetzner.ssh_key_id=42",
+	}, "hetzner")
+	if err != nil {
+		t.Fatalf("parseProviderSets() error = %v", err)
+	}
+
+	got, err := withProviderOverrides(base, "hetzner", sets)
+	if err != nil {
+		t.Fatalf("withProviderOverrides() error = %v", err)
+	}
+
+	pc := got.Providers["hetzner"]
+	if pc.Network != "internal" || pc.PlacementGroup != "spread" || pc.SSHKeyID != 42 || pc.Region != "hel1" {
+		t.Errorf("overridden config = %+v", pc)
+	}
+	if base.Providers["hetzner"].Network != "" {
+		t.Error("withProviderOverrides should not modify the original config")
+	}
+}
+
+// TestParseProviderSets_GivenInvalidValues_ThenReturnsError tests --set validation.
+func TestParseProviderSets_GivenInvalidValues_ThenReturnsError(t *testing.T) {
+	base := &config.Config{
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "token"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"missing value", "hetzner.network"},
+		{"missing provider", "network=internal"},
+		{"other provider", "aws.network=internal"},
+		{"unknown key", "hetzner.netwrok=internal"},
+		{"non-integer key ID", "hetzner.ssh_key_id=abc"},
+		{"token", "hetzner.token=secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sets, err := parseProviderSets([]string{tt.value}, "hetzner")
+			if err == nil {
+				_, err = withProviderOverrides(base, "hetzner", sets)
+			}
+			if err == nil {
+				t.Errorf("expected error for --set %s", tt.value)
+			}
+		})
+	}
+}
+
+// TestCancellableSteps_GivenCanceledContext_ThenSkipsRemainingSteps tests Ctrl-C handling.
+func TestCancellableSteps_GivenCanceledContext_ThenSkipsRemainingSteps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var ran []string
+	steps := []ui.ProgressStep{
+		{Message: "first", Action: func() error {
+			ran = append(ran, "first")
+			cancel()
+			return nil
+		}},
+		{Message: "second", Action: func() error {
+			ran = append(ran, "second")
+			return nil
+		}},
+	}
+
+	err := ui.RunSteps(io.Discard, cancellableSteps(ctx, steps))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunSteps() error = %v, want %v", err, context.Canceled)
+	}
+	if len(ran) != 1 || ran[0] != "first" {
+		t.Errorf("ran steps %v, want only the first", ran)
+	}
+}
+
+// TestOpenCodeAuthWriteCommand_GivenKey_ThenWritesEncodedAuth tests the auth write command.
+func TestOpenCodeAuthWriteCommand_GivenKey_ThenWritesEncodedAuth(t *testing.T) {
+	key := `new'key$`
+
+	command, err := openCodeAuthWriteCommand(key)
+	if err != nil {
+		t.Fatalf("openCodeAuthWriteCommand() error = %v", err)
+	}
+	if strings.Contains(command, key) {
+		t.Errorf("command contains the raw key: %q", command)
+	}
+
+	_, rest, ok := strings.Cut(command, "echo '")
+	encoded, _, ok2 := strings.Cut(rest, "'")
+	if !ok || !ok2 {
+		t.Fatalf("command has no encoded payload: %q", command)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("payload is not base64: %v", err)
+	}
+	if !openCodeAuthHasKey(data, key) {
+		t.Errorf("decoded auth %s does not hold the key", data)
+	}
+	if !strings.Contains(command, "chmod 600 "+openCodeAuthPath) {
+		t.Errorf("command does not restrict permissions: %q", command)
+	}
+}
+
+// TestRunNew_GivenInvalidCount_ThenReturnsError tests --count validation.
+func TestRunNew_GivenInvalidCount_ThenReturnsError(t *testing.T) {
+	oldCount, oldName, oldDryRun := newCount, nameArg, dryRun
+	t.Cleanup(func() { newCount, nameArg, dryRun = oldCount, oldName, oldDryRun })
+
+	tests := []struct {
+		name   string
+		count  int
+		sess   string
+		dryRun bool
+		want   string
+	}{
+		{"zero", 0, "", false, "--count must be at least 1"},
+		{"with name", 3, "alice", false, "--name cannot be used with --count"},
+		{"with dry run", 3, "", true, "--dry-run cannot be used with --count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newCount, nameArg, dryRun = tt.count, tt.sess, tt.dryRun
+			err := runNew(newCmd, nil)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("runNew() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// fakeDeleteProvider is a provider whose Delete records the deleted VM IDs.
+type fakeDeleteProvider struct {
+	provider.Provider
+	deleted []string
+}
+
+func (f *fakeDeleteProvider) Name() string { return "fake" }
+
+func (f *fakeDeleteProvider) Delete(ctx context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+// TestCleanupFailedSession_GivenFailureReason_ThenPersistsIt tests failure reason recording.
+func TestCleanupFailedSession_GivenFailureReason_ThenPersistsIt(t *testing.T) {
+	store := session.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	for _, id := range []string{"alice", "bob"} {
+		if err := store.Add(session.Session{ID: id, Status: session.StatusProvisioning}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	prov := &fakeDeleteProvider{}
+	alice := &session.Session{ID: "alice", Status: session.StatusProvisioning, FailureReason: session.FailureCloudInit}
+	cleanupFailedSession(context.Background(), prov, store, alice, &provider.VM{ID: "42"})
+	bob := &session.Session{ID: "bob", Status: session.StatusProvisioning}
+	cleanupFailedSession(context.Background(), prov, store, bob, nil)
+
+	if len(prov.deleted) != 1 || prov.deleted[0] != "42" {
+		t.Errorf("deleted VMs = %v, want [42]", prov.deleted)
+	}
+
+	tests := []struct {
+		id     string
+		reason string
+	}{
+		{"alice", session.FailureCloudInit},
+		{"bob", session.FailureProvisioning},
+	}
+	for _, tt := range tests {
+		got, err := store.Get(tt.id)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", tt.id, err)
+		}
+		if got.Status != session.StatusFailed || got.FailureReason != tt.reason {
+			t.Errorf("%s: status = %s, reason = %q; want failed, %q", tt.id, got.Status, got.FailureReason, tt.reason)
+		}
+	}
+}
+
+// TestCheckAgentKey_GivenKeyRemovedFromAgent_ThenReturnsError tests the agent-mode key check.
+func TestCheckAgentKey_GivenKeyRemovedFromAgent_ThenReturnsError(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+		t.Fatalf("failed to add key to agent: %v", err)
+	}
+	useTestAgent(t, t.TempDir(), keyring)
+
+	agentCfg := &config.Config{SSHKeySource: "agent", SSHKeyFingerprint: ssh.FingerprintSHA256(signer.PublicKey())}
+	if err := checkAgentKey(agentCfg); err != nil {
+		t.Fatalf("checkAgentKey() error = %v, want nil while the key is loaded", err)
+	}
+
+	if err := keyring.RemoveAll(); err != nil {
+		t.Fatalf("failed to remove keys: %v", err)
+	}
+	if err := checkAgentKey(agentCfg); !errors.Is(err, sshagent.ErrKeyNotLoaded) {
+		t.Errorf("checkAgentKey() error = %v, want %v", err, sshagent.ErrKeyNotLoaded)
+	}
+
+	// File mode doesn't depend on the agent
+	if err := checkAgentKey(&config.Config{SSHPublicKey: "~/.ssh/id_ed25519.pub"}); err != nil {
+		t.Errorf("checkAgentKey(file mode) error = %v, want nil", err)
+	}
+}
+
+// TestValidateLabels_GivenValidLabels_ThenReturnsNil tests accepted label syntax.
+func TestValidateLabels_GivenValidLabels_ThenReturnsNil(t *testing.T) {
+	labels := map[string]string{
+		"project":               "ghost",
+		"env":                   "",
+		"example.com/owner":     "alice_b.c-d",
+		strings.Repeat("k", 63): strings.Repeat("v", 63),
+	}
+
+	if err := validateLabels(labels); err != nil {
+		t.Errorf("validateLabels() error = %v", err)
+	}
+}
+
+// TestValidateLabels_GivenInvalidLabels_ThenReturnsError tests rejected labels,
+// including the reserved managed-by key.
+func TestValidateLabels_GivenInvalidLabels_ThenReturnsError(t *testing.T) {
+	tests := map[string]map[string]string{
+		"reserved key":   {"managed-by": "me"},
+		"space in key":   {"my label": "x"},
+		"leading dash":   {"-team": "x"},
+		"long key":       {strings.Repeat("k", 64): "x"},
+		"bad prefix":     {"Example.com/owner": "x"},
+		"empty name":     {"example.com/": "x"},
+		"space in value": {"team": "web app"},
+		"trailing dot":   {"team": "web."},
+		"long value":     {"team": strings.Repeat("v", 64)},
+		"slash in value": {"team": "a/b"},
+	}
+
+	for name, labels := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := validateLabels(labels); err == nil {
+				t.Errorf("validateLabels(%v) expected error", labels)
+			}
+		})
+	}
+}
+
+// fakeKeyProvider is a provider whose ListKeys returns a fixed set of keys.
+type fakeKeyProvider struct {
+	provider.Provider
+	keys []provider.SSHKey
+}
+
+func (f *fakeKeyProvider) Name() string { return "fake" }
+
+func (f *fakeKeyProvider) EnsureSSHKey(ctx context.Context, name, publicKey string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (f *fakeKeyProvider) ListKeys(ctx context.Context) ([]provider.SSHKey, error) {
+	return f.keys, nil
+}
+
+func (f *fakeKeyProvider) DeleteKey(ctx context.Context, keyID string) error {
+	return errors.New("not implemented")
+}
+
+// TestCheckProviderKey_GivenKeys_ThenRequiresConfiguredKey tests that --set
+// ssh_key_id must name the key sandctl connects with.
+func TestCheckProviderKey_GivenKeys_ThenRequiresConfiguredKey(t *testing.T) {
+	cfg := &config.Config{SSHKeySource: "agent", SSHPublicKeyInline: "ssh-ed25519 AAAAlocal me@laptop"}
+	prov := &fakeKeyProvider{keys: []provider.SSHKey{
+		{ID: "1", Name: "sandctl-1a2b3c4d", PublicKey: "ssh-ed25519 AAAAlocal"},
+		{ID: "2", Name: "team", PublicKey: "ssh-ed25519 AAAAteam"},
+	}}
+
+	if err := checkProviderKey(context.Background(), prov, cfg, "1"); err != nil {
+		t.Errorf("checkProviderKey(matching key) error = %v", err)
+	}
+	if err := checkProviderKey(context.Background(), prov, cfg, "2"); err == nil {
+		t.Error("expected error for a key other than the configured one")
+	}
+	if err := checkProviderKey(context.Background(), prov, cfg, "3"); err == nil {
+		t.Error("expected error for a missing key")
+	}
+	if err := checkProviderKey(context.Background(), &fakeDeleteProvider{}, cfg, "1"); err == nil {
+		t.Error("expected error for a provider without SSH key management")
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var noteClear bool
+
+var noteCmd = &cobra.Command{
+	Use:   "note <name> [text]",
+	Short: "Show or set a session's note",
+	Long: `Show or set a free-form note on a session, such as what it is for.
+
+With text, the note is replaced. Without text, the current note is printed.
+The note is shown, shortened, by 'sandctl inspect'.`,
+	Example: `  # Set a note
+  sandctl note alice "reproducing the checkout bug"
+
+  # Print the note
+  sandctl note alice
+
+  # Remove the note
+  sandctl note alice --clear`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runNote,
+}
+
+func init() {
+	noteCmd.Flags().BoolVar(&noteClear, "clear", false, "remove the note")
+
+	rootCmd.AddCommand(noteCmd)
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	// Normalize the session name (case-insensitive)
+	sessionName := session.NormalizeName(args[0])
+
+	// Validate session name format
+	if !session.ValidateID(sessionName) {
+		return fmt.Errorf("invalid session name format: %s", args[0])
+	}
+
+	if noteClear && len(args) > 1 {
+		return fmt.Errorf("--clear cannot be combined with note text")
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	sess, err := store.Get(sessionName)
+	if err != nil {
+		var notFound *session.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.PrintError(os.Stderr, "session '%s' not found", sessionName)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, "Run 'sandctl list' to see available sessions.")
+			return nil
+		}
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	// Print the current note
+	if len(args) == 1 && !noteClear {
+		if sess.Notes != "" {
+			fmt.Println(sess.Notes)
+		}
+		return nil
+	}
+
+	sess.Notes = strings.TrimSpace(strings.Join(args[1:], " "))
+	if err := store.UpdateSession(*sess); err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+
+	if sess.Notes == "" {
+		fmt.Printf("Note removed from '%s'.\n", sessionName)
+	} else {
+		fmt.Printf("Note saved for '%s'.\n", sessionName)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// opencodeCmd represents the opencode parent command.
+var opencodeCmd = &cobra.Command{
+	Use:   "opencode",
+	Short: "Manage OpenCode on sessions",
+	Long: `Manage the OpenCode setup of running sessions.
+
+Subcommands:
+  refresh  Rewrite a session's OpenCode auth with the configured Zen key`,
+}
+
+func init() {
+	rootCmd.AddCommand(opencodeCmd)
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var opencodeRefreshCmd = &cobra.Command{
+	Use:   "refresh <name>",
+	Short: "Update a session's OpenCode auth with the configured Zen key",
+	Long: `Rewrite OpenCode's auth.json on a running session with the Zen key
+currently in your config.
+
+Sessions are set up with the key configured when they were created. Run this
+after rotating opencode_zen_key to update a session that is already running.
+OpenCode must already be installed on the session.`,
+	Example: `  # Push the current Zen key to a running session
+  sandctl opencode refresh alice`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runOpencodeRefresh,
+}
+
+func init() {
+	opencodeCmd.AddCommand(opencodeRefreshCmd)
+}
+
+func runOpencodeRefresh(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.OpencodeZenKey == "" {
+		return fmt.Errorf("no OpenCode Zen key configured; set opencode_zen_key with 'sandctl init'")
+	}
+
+	// Normalize the session name (case-insensitive)
+	sessionName := session.NormalizeName(args[0])
+
+	// Validate session name format
+	if !session.ValidateID(sessionName) {
+		return fmt.Errorf("invalid session name format: %s", args[0])
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	sess, err := store.Get(sessionName)
+	if err != nil {
+		var notFound *session.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.PrintError(os.Stderr, "session '%s' not found", sessionName)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, "Run 'sandctl list' to see available sessions.")
+			return nil
+		}
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	if sess.IsLegacySession() {
+		ui.PrintError(os.Stderr, "session '%s' is from an old version and incompatible", sessionName)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Please destroy this session and create a new one.")
+		return nil
+	}
+
+	if sess.Status != session.StatusRunning {
+		ui.FormatSessionNotRunning(os.Stderr, sessionName, sess.Status)
+		return nil
+	}
+
+	// Fail fast if the VM was deleted outside sandctl
+	if err := ensureSessionVM(store, sess); err != nil {
+		return err
+	}
+
+	host := sessionAddress(sess)
+	if host == "" {
+		return fmt.Errorf("session '%s' has no IP address", sessionName)
+	}
+
+	client, err := createSessionSSHClient(sess, host)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Exec(openCodeCheckCommand); err != nil {
+		return fmt.Errorf("OpenCode is not installed on '%s'; create the session with a Zen key configured to set it up", sessionName)
+	}
+
+	if err := writeOpenCodeAuth(client, cfg.OpencodeZenKey); err != nil {
+		return err
+	}
+
+	version, err := client.Exec(openCodeVersionCommand)
+	if err != nil {
+		return fmt.Errorf("OpenCode auth was updated, but 'opencode --version' failed: %w", err)
+	}
+
+	ui.PrintSuccess(os.Stdout, "Updated OpenCode auth on '%s' (opencode %s)", sessionName, strings.TrimSpace(version))
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/hetzner"
+)
+
+var priceServerType string
+
+var priceCmd = &cobra.Command{
+	Use:   "price",
+	Short: "Show estimated server prices",
+	Long: `Show estimated hourly prices for Hetzner Cloud server types.
+
+Prices are approximate, in euros excluding VAT, and may differ by location.
+Check the Hetzner Cloud console for current pricing.`,
+	Example: `  # Show all server types
+  sandctl price
+
+  # Show a single server type
+  sandctl price --server-type cpx41`,
+	Args: cobra.NoArgs,
+	RunE: runPrice,
+}
+
+func init() {
+	priceCmd.Flags().StringVar(&priceServerType, "server-type", "", "only show this server type")
+
+	rootCmd.AddCommand(priceCmd)
+}
+
+func runPrice(cmd *cobra.Command, args []string) error {
+	prices := hetzner.ServerTypePrices()
+
+	if priceServerType != "" {
+		var filtered []hetzner.ServerTypePrice
+		for _, p := range prices {
+			if p.Name == priceServerType {
+				filtered = append(filtered, p)
+			}
+		}
+		if len(filtered) == 0 {
+			return fmt.Errorf("no price estimate for server type '%s'", priceServerType)
+		}
+		prices = filtered
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER TYPE\tVCPUS\tMEMORY\tHOURLY\tMONTHLY (730h)")
+
+	for _, p := range prices {
+		fmt.Fprintf(w, "%s\t%d\t%dGB\t%s\t%s\n",
+			p.Name,
+			p.VCPUs,
+			p.MemoryGB,
+			formatEUR(p.HourlyEUR),
+			formatEUR(p.HourlyEUR*730),
+		)
+	}
+
+	return w.Flush()
+}
+
+// formatEUR formats a euro amount, showing more precision for small values.
+func formatEUR(amount float64) string {
+	if amount < 1 {
+		return fmt.Sprintf("€%.4f", amount)
+	}
+	return fmt.Sprintf("€%.2f", amount)
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Switch between config profiles",
+	Long: `Manage config profiles.
+
+A profile is a named set of providers and defaults in the profiles section of
+the config, such as a separate provider project with its own token. Settings
+a profile leaves unset are taken from the top level of the config, which is
+the "default" profile.
+
+The active profile is used by every command unless --profile is given.
+Sessions remember the profile they were created with, so they are always
+managed through the right project.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List config profiles",
+	Long: `List the config profiles and the default provider of each. The profile in
+use is marked with *.`,
+	Example: `  # List profiles
+  sandctl profile list`,
+	Args: cobra.NoArgs,
+	RunE: runProfileList,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the active config profile",
+	Long: `Set the profile used when --profile isn't given, by writing active_profile
+to the config file. Use "default" to go back to the top-level settings.`,
+	Example: `  # Use the work profile from now on
+  sandctl profile use work
+
+  # Go back to the top-level settings
+  sandctl profile use default`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileUse,
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+	return printProfiles(os.Stdout, baseCfg, cfg.ActiveProfile)
+}
+
+// printProfiles writes a table of cfg's profiles, marking active. An empty
+// active means the default profile.
+func printProfiles(w io.Writer, cfg *config.Config, active string) error {
+	active = cmp.Or(active, config.DefaultProfile)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTIVE\tNAME\tPROVIDER")
+	for _, name := range append([]string{config.DefaultProfile}, cfg.ProfileNames()...) {
+		p, err := cfg.WithProfile(name)
+		if err != nil {
+			return err
+		}
+		marker := ""
+		if name == active {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", marker, name, valueOrDash(p.DefaultProvider))
+	}
+	return tw.Flush()
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	configPath, err := configFileForWrite()
+	if err != nil {
+		return err
+	}
+
+	fileCfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := setActiveProfile(fileCfg, name); err != nil {
+		return err
+	}
+	if err := config.Save(configPath, fileCfg); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	ui.PrintSuccess(os.Stdout, "Using profile '%s'", name)
+	return nil
+}
+
+// setActiveProfile makes name the active profile of cfg. The default profile
+// is stored as an empty active_profile.
+func setActiveProfile(cfg *config.Config, name string) error {
+	if _, err := cfg.WithProfile(name); err != nil {
+		return fmt.Errorf("%w. Use 'sandctl profile list' to see available profiles", err)
+	}
+	if name == config.DefaultProfile {
+		name = ""
+	}
+	cfg.ActiveProfile = name
+	return nil
+}
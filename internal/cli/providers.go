@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "List available providers",
+	Long: `List the providers built into sandctl.
+
+For each provider, shows whether it is configured in your config file, whether
+it is the default, and which optional features it supports.`,
+	Example: `  # List providers
+  sandctl providers`,
+	Args: cobra.NoArgs,
+	RunE: runProviders,
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+}
+
+func runProviders(cmd *cobra.Command, args []string) error {
+	// A missing or invalid config only means nothing is configured yet
+	cfg, err := loadConfig()
+	if err != nil {
+		verboseLog("Failed to load config: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCONFIGURED\tDEFAULT\tCAPABILITIES")
+	for _, name := range provider.Available() {
+		configured, isDefault := providerConfigState(cfg, name)
+
+		capabilities := "-"
+		if p, err := provider.Probe(name); err != nil {
+			verboseLog("Failed to inspect provider %s: %v", name, err)
+		} else if caps := provider.Capabilities(p); len(caps) > 0 {
+			capabilities = strings.Join(caps, ", ")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, yesNo(configured), yesNo(isDefault), capabilities)
+	}
+	return w.Flush()
+}
+
+// providerConfigState reports whether name has a provider section in cfg and
+// whether it is the default provider.
+func providerConfigState(cfg *config.Config, name string) (configured, isDefault bool) {
+	if cfg == nil {
+		return false, false
+	}
+	_, configured = cfg.GetProviderConfig(name)
+	return configured, cfg.DefaultProvider == name
+}
+
+// yesNo formats b for a table column.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
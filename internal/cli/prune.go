@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+)
+
+var (
+	pruneOlderThan time.Duration
+	pruneDryRun    bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stopped and failed sessions from the local store",
+	Long: `Remove records of stopped and failed sessions from the local store.
+
+A record is only removed once the provider confirms its VM no longer exists,
+so stopped VMs that are still around are kept. Running and provisioning
+sessions are never pruned.`,
+	Example: `  # Remove all dead session records
+  sandctl prune
+
+  # Only remove records older than a week, showing what would go
+  sandctl prune --older-than 168h --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().DurationVar(&pruneOlderThan, "older-than", 0, "only prune sessions created more than this long ago")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "show what would be pruned without removing anything")
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	if pruneOlderThan < 0 {
+		return fmt.Errorf("--older-than cannot be negative")
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	pruned := 0
+	for i := range sessions {
+		sess := &sessions[i]
+		if !sess.Status.IsTerminal() || sess.IsLegacySession() {
+			continue
+		}
+		if pruneOlderThan > 0 && sess.Age() < pruneOlderThan {
+			continue
+		}
+
+		gone, err := sessionVMGone(sess)
+		if err != nil {
+			logger.Warn("skipping session", "session", sess.ID, "error", err)
+			continue
+		}
+		if !gone {
+			verboseLog("Keeping %s: VM still exists", sess.ID)
+			continue
+		}
+
+		if pruneDryRun {
+			fmt.Printf("Would prune '%s' (%s)\n", sess.ID, sess.Status)
+			pruned++
+			continue
+		}
+
+		if err := store.Remove(sess.ID); err != nil {
+			logger.Warn("failed to remove session from local store", "session", sess.ID, "error", err)
+			continue
+		}
+		forgetHostKey(sess.IPAddress)
+		forgetHostKey(sess.IPv6)
+		fmt.Printf("Pruned '%s' (%s)\n", sess.ID, sess.Status)
+		pruned++
+	}
+
+	switch {
+	case pruned == 0:
+		fmt.Println("No sessions to prune.")
+	case pruneDryRun:
+		fmt.Printf("\n%d sessions would be pruned.\n", pruned)
+	default:
+		fmt.Printf("\nPruned %d sessions.\n", pruned)
+	}
+	return nil
+}
+
+// sessionVMGone reports whether the provider confirms a session's VM no
+// longer exists. A session that never got a VM counts as gone.
+func sessionVMGone(sess *session.Session) (bool, error) {
+	if sess.ProviderID == "" {
+		return true, nil
+	}
+
+	prov, err := getProviderFromSession(sess)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vmCheckTimeout)
+	defer cancel()
+
+	return vmGone(ctx, prov, sess.ProviderID)
+}
+
+// vmGone reports whether prov confirms the VM does not exist.
+func vmGone(ctx context.Context, prov provider.Provider, id string) (bool, error) {
+	_, err := prov.Get(ctx, id)
+	if errors.Is(err, provider.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check VM: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"log/slog"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sandctl/sandctl/internal/config"
+)
+
+// redactedValue replaces secrets in log output.
+const redactedValue = "****"
+
+// minSecretLength is the shortest configured value treated as a secret, so
+// that short placeholders don't mask unrelated text.
+const minSecretLength = 6
+
+// minSecretEntropy is the Shannon entropy (bits per character) above which a
+// long token is assumed to be a credential. Random API tokens score above 5;
+// words, paths, and hex hashes score well below.
+const minSecretEntropy = 4.5
+
+// tokenPattern matches long runs of token/base64 characters.
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+
+// redact masks configured secrets and high-entropy tokens in s.
+func redact(s string) string {
+	for _, secret := range configuredSecrets() {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+
+	return tokenPattern.ReplaceAllStringFunc(s, func(token string) string {
+		if shannonEntropy(token) >= minSecretEntropy {
+			return redactedValue
+		}
+		return token
+	})
+}
+
+// configuredSecrets returns the secret values in the loaded config.
+func configuredSecrets() []string {
+	if cfg == nil {
+		return nil
+	}
+
+	candidates := []string{cfg.OpencodeZenKey, cfg.GitHubToken, cfg.SpritesToken}
+	providers := slices.Collect(maps.Values(cfg.Providers))
+	for _, profile := range cfg.Profiles {
+		providers = slices.AppendSeq(providers, maps.Values(profile.Providers))
+	}
+	for _, provCfg := range providers {
+		// Don't run token commands on every log line; entropy detection
+		// still catches their output
+		if strings.HasPrefix(provCfg.Token, config.TokenCommandPrefix) {
+			continue
+		}
+		if token, err := provCfg.ResolveToken(); err == nil {
+			candidates = append(candidates, token)
+		}
+	}
+
+	var secrets []string
+	for _, c := range candidates {
+		if len(c) >= minSecretLength {
+			secrets = append(secrets, c)
+		}
+	}
+	return secrets
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(len(s))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactAttr is a slog ReplaceAttr function that masks secrets in log
+// messages and attribute values.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redact(a.Value.String()))
+	case slog.KindAny:
+		return slog.String(a.Key, redact(a.Value.String()))
+	default:
+		return a
+	}
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sandctl/sandctl/internal/config"
+)
+
+// useTestSecretsConfig installs a config holding known secrets for the test.
+func useTestSecretsConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	oldCfg := cfg
+	cfg = &config.Config{
+		DefaultProvider: "hetzner",
+		OpencodeZenKey:  "zen-short-key",
+		GitHubToken:     "ghp_notsorandom",
+		Providers: map[string]config.ProviderConfig{
+			"hetzner": {Token: "hz-token-value", Region: "ash"},
+		},
+	}
+	t.Cleanup(func() { cfg = oldCfg })
+	return cfg
+}
+
+// TestWriteVerboseLog_GivenConfigDump_ThenOmitsRawSecrets tests verbose log redaction.
+func TestWriteVerboseLog_GivenConfigDump_ThenOmitsRawSecrets(t *testing.T) {
+	testCfg := useTestSecretsConfig(t)
+
+	var buf bytes.Buffer
+	writeVerboseLog(&buf, "Config: %+v", *testCfg)
+
+	out := buf.String()
+	for _, secret := range []string{"hz-token-value", "zen-short-key", "ghp_notsorandom"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("verbose output contains secret %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "ash") {
+		t.Errorf("verbose output lost non-secret fields: %s", out)
+	}
+}
+
+// TestLogger_GivenSecretAttributes_ThenOmitsRawSecrets tests structured log redaction.
+func TestLogger_GivenSecretAttributes_ThenOmitsRawSecrets(t *testing.T) {
+	useTestSecretsConfig(t)
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, loggerOptions(slog.LevelDebug)))
+	log.Warn("request failed for hz-token-value", "error", errors.New("bad token hz-token-value"), "attempt", 2)
+
+	out := buf.String()
+	if strings.Contains(out, "hz-token-value") {
+		t.Errorf("log output contains secret: %s", out)
+	}
+	if !strings.Contains(out, "attempt=2") {
+		t.Errorf("log output lost non-secret attributes: %s", out)
+	}
+}
+
+// TestRedact_GivenHighEntropyToken_ThenMasksIt tests detection of unconfigured secrets.
+func TestRedact_GivenHighEntropyToken_ThenMasksIt(t *testing.T) {
+	oldCfg := cfg
+	cfg = nil
+	t.Cleanup(func() { cfg = oldCfg })
+
+	token := "Xk9pQ2mZ7rT4vW1yB8nL3cF6hJ0sD5gA2eR7tY9uI4oP1aS6dF3gH8jK0lZ5xC2v"
+	got := redact("echo '" + token + "' | base64 -d")
+	if strings.Contains(got, token) {
+		t.Errorf("redact() did not mask high-entropy token: %s", got)
+	}
+
+	// Ordinary long identifiers and hex hashes are left alone
+	for _, s := range []string{
+		"test -f /var/lib/cloud/instance/boot-finished",
+		"d41d8cd98f00b204e9800998ecf8427e",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	} {
+		if got := redact(s); got != s {
+			t.Errorf("redact(%q) = %q, want unchanged", s, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+var (
+	regionsProvider   string
+	regionsServerType string
+)
+
+var regionsCmd = &cobra.Command{
+	Use:   "regions",
+	Short: "List regions and server type availability",
+	Long: `List the regions new sessions can be created in, and whether a server type
+can currently be created in each.
+
+Some server types are temporarily unavailable in capacity-constrained
+regions. The server type checked defaults to the one 'sandctl new' would use.
+Pass a NAME to 'sandctl new --region'.`,
+	Example: `  # List regions and whether the default server type is available
+  sandctl regions
+
+  # Find where a larger server type is available
+  sandctl regions --server-type cpx41`,
+	Args: cobra.NoArgs,
+	RunE: runRegions,
+}
+
+func init() {
+	regionsCmd.Flags().StringVarP(&regionsProvider, "provider", "p", "", "provider to query (default: from config)")
+	regionsCmd.Flags().StringVar(&regionsServerType, "server-type", "", "server type to check availability of (default: from config)")
+
+	rootCmd.AddCommand(regionsCmd)
+}
+
+func runRegions(cmd *cobra.Command, args []string) error {
+	prov, err := getProvider(regionsProvider)
+	if err != nil {
+		return err
+	}
+
+	regions, err := listProviderRegions(context.Background(), prov)
+	if err != nil {
+		return err
+	}
+
+	serverType := resolveCreateOpts(prov, provider.CreateOpts{ServerType: regionsServerType}).ServerType
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\tLOCATION\tDESCRIPTION\t%s\n", strings.ToUpper(valueOrDash(serverType)))
+	for _, r := range regions {
+		available := "no"
+		if r.HasServerType(serverType) {
+			available = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, formatRegionLocation(r), r.Description, available)
+	}
+	return w.Flush()
+}
+
+// listProviderRegions lists regions for providers that support it.
+func listProviderRegions(ctx context.Context, prov provider.Provider) ([]provider.Region, error) {
+	lister, ok := prov.(provider.RegionLister)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support listing regions", prov.Name())
+	}
+	return lister.ListRegions(ctx)
+}
+
+// resolveCreateOpts returns opts with the provider's defaults filled in, if
+// the provider can resolve them.
+func resolveCreateOpts(prov provider.Provider, opts provider.CreateOpts) provider.CreateOpts {
+	if resolver, ok := prov.(provider.DefaultsResolver); ok {
+		return resolver.ResolveCreateOpts(opts)
+	}
+	return opts
+}
+
+// formatRegionLocation formats a region's city and country.
+func formatRegionLocation(r provider.Region) string {
+	switch {
+	case r.City != "" && r.Country != "":
+		return r.City + ", " + r.Country
+	case r.City != "" || r.Country != "":
+		return r.City + r.Country
+	default:
+		return "-"
+	}
+}
+
+// regionWarning returns a warning if the region and server type in opts,
+// after defaults, are known to be unavailable, or "" if they are available
+// or availability can't be checked.
+func regionWarning(ctx context.Context, prov provider.Provider, opts provider.CreateOpts) string {
+	lister, ok := prov.(provider.RegionLister)
+	if !ok {
+		return ""
+	}
+	opts = resolveCreateOpts(prov, opts)
+	if opts.Region == "" || opts.ServerType == "" {
+		return ""
+	}
+
+	regions, err := lister.ListRegions(ctx)
+	if err != nil {
+		verboseLog("Skipping region availability check: %v", err)
+		return ""
+	}
+
+	for _, r := range regions {
+		if r.Name != opts.Region {
+			continue
+		}
+		if r.HasServerType(opts.ServerType) {
+			return ""
+		}
+		return fmt.Sprintf("server type %s is currently unavailable in %s. Use 'sandctl regions --server-type %s' to see where it is available.",
+			opts.ServerType, opts.Region, opts.ServerType)
+	}
+	return fmt.Sprintf("region %s is not offered by %s. Use 'sandctl regions' to see available regions.", opts.Region, prov.Name())
+}
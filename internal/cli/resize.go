@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+// resizeTimeout bounds the whole resize, including the restart.
+const resizeTimeout = 15 * time.Minute
+
+// resizeReadyTimeout bounds the wait for the VM to accept SSH after resizing.
+const resizeReadyTimeout = 5 * time.Minute
+
+var resizeForce bool
+
+var resizeCmd = &cobra.Command{
+	Use:   "resize <name> <server-type>",
+	Short: "Change the server type of a session",
+	Long: `Change the server type of a session's VM without recreating it.
+
+The VM is powered off, rescaled, and started again, so running processes are
+stopped. The disk grows with an upgrade but can never shrink. Moving to a
+smaller server type keeps the current, larger disk and requires --force.`,
+	Example: `  # Upgrade a session
+  sandctl resize alice cpx31
+
+  # Downgrade, keeping the current disk
+  sandctl resize alice cpx21 --force`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSessionNames,
+	RunE:              runResize,
+}
+
+func init() {
+	resizeCmd.Flags().BoolVar(&resizeForce, "force", false, "allow resizing to a smaller server type")
+
+	rootCmd.AddCommand(resizeCmd)
+}
+
+func runResize(cmd *cobra.Command, args []string) error {
+	// Normalize the session name (case-insensitive)
+	sessionName := session.NormalizeName(args[0])
+	targetType := args[1]
+
+	// Validate session name format
+	if !session.ValidateID(sessionName) {
+		return fmt.Errorf("invalid session name format: %s", args[0])
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+
+	sess, err := store.Get(sessionName)
+	if err != nil {
+		var notFound *session.NotFoundError
+		if errors.As(err, &notFound) {
+			ui.PrintError(os.Stderr, "session '%s' not found", sessionName)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, "Run 'sandctl list' to see available sessions.")
+			return nil
+		}
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	if sess.IsLegacySession() || sess.ProviderID == "" {
+		ui.PrintError(os.Stderr, "session '%s' is from an old version and incompatible", sessionName)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Please destroy this session and create a new one.")
+		return nil
+	}
+
+	if sess.ServerType == targetType {
+		fmt.Printf("Session '%s' is already %s.\n", sessionName, targetType)
+		return nil
+	}
+
+	prov, err := getProviderFromSession(sess)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resizeTimeout)
+	defer cancel()
+
+	fmt.Printf("Resizing '%s' to %s. The VM will restart; its disk can grow but never shrink.\n", sessionName, targetType)
+	fmt.Println()
+
+	steps := []ui.ProgressStep{
+		{
+			Message: fmt.Sprintf("Resizing to %s", targetType),
+			Action: func() error {
+				return prov.Resize(ctx, sess.ProviderID, targetType, provider.ResizeOpts{AllowDowngrade: resizeForce})
+			},
+		},
+		{
+			Message: "Waiting for VM to be ready",
+			ActionWithStatus: func(setStatus func(string)) error {
+				readyCtx, cancel := context.WithTimeout(ctx, resizeReadyTimeout)
+				defer cancel()
+
+				return prov.WaitReady(readyCtx, sess.ProviderID, provider.WithSSHPort(sess.SSHPort), provider.WithIPv6(preferIPv6()), provider.WithProgress(func(state provider.ReadyState) {
+					setStatus(readyStatus(state))
+				}))
+			},
+		},
+	}
+
+	if err := ui.RunSteps(os.Stdout, steps); err != nil {
+		if errors.Is(err, provider.ErrDowngrade) {
+			return fmt.Errorf("%s is smaller than the current server type; the VM would keep its current disk. Use --force to downgrade anyway", targetType)
+		}
+		return fmt.Errorf("failed to resize session '%s': %w", sessionName, err)
+	}
+
+	getVMCache().Invalidate(vmCacheKey(sess.Profile, prov.Name()))
+
+	sess.ServerType = targetType
+	if err := store.UpdateSession(*sess); err != nil {
+		logger.Warn("failed to update session", "session", sessionName, "error", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Session '%s' is now %s.\n", sessionName, targetType)
+	return nil
+}
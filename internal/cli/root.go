@@ -2,19 +2,29 @@
 package cli
 
 import (
+	"cmp"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
 
 	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/datadir"
 	// Import hetzner to register the provider
 	_ "github.com/sandctl/sandctl/internal/hetzner"
 	"github.com/sandctl/sandctl/internal/provider"
 	"github.com/sandctl/sandctl/internal/session"
 	"github.com/sandctl/sandctl/internal/sshagent"
 	"github.com/sandctl/sandctl/internal/sshexec"
+	"github.com/sandctl/sandctl/internal/ui"
 )
 
 var (
@@ -24,12 +34,27 @@ var (
 	buildTime = "unknown"
 
 	// Global flags.
-	cfgFile string
-	verbose bool
-
-	// Shared resources (initialized on demand).
+	cfgFile     string
+	verbose     bool
+	logLevel    string
+	insecure    bool
+	useIPv6     bool
+	output      string
+	noColor     bool
+	refresh     bool
+	dataDir     string
+	profileName string
+
+	// logger records warnings and background failures to stderr.
+	// It is reconfigured from --log-level before each command runs.
+	logger = newLogger(slog.LevelWarn)
+
+	// Shared resources (initialized on demand). baseCfg is the config as
+	// loaded; cfg has the selected profile applied.
+	baseCfg      *config.Config
 	cfg          *config.Config
-	sessionStore *session.Store
+	sessionStore session.Store
+	vmCache      *provider.VMCache
 )
 
 // rootCmd represents the base command when called without any subcommands.
@@ -44,23 +69,69 @@ AI coding agents (Claude, OpenCode, Codex) can work on development tasks safely.
 Supported providers: Hetzner Cloud (default)
 
 Commands:
-  init     Initialize or update sandctl configuration
-  new      Create a new sandboxed agent session
-  list     List active sessions
-  console  Open an interactive console to a session (SSH-like)
-  exec     Execute commands in a running session
-  destroy  Terminate and remove a session
+  init        Initialize or update sandctl configuration
+  config      Manage sandctl configuration
+  profile     Switch between config profiles
+  new         Create a new sandboxed agent session
+  list        List active sessions
+  inspect     Show detailed information about a session
+  note        Show or set a session's note
+  console     Open an interactive console to a session (SSH-like)
+  exec        Execute commands in a running session
+  logs        Show provisioning logs for a session
+  opencode    Manage OpenCode on sessions
+  destroy     Terminate and remove a session
+  prune       Remove stopped and failed sessions from the local store
+  archive     Move a stopped or failed session into the history
+  import      Adopt an existing provider VM as a session
+  forget      Remove a stored SSH host key
+  doctor      Check configuration and connectivity
+  providers   List available providers
+  price       Show estimated server prices
+  images      List available OS images
+  regions     List regions and server type availability
+  keys        Manage SSH keys uploaded to the provider
+  resize      Change the server type of a session
+  watchdog    Stop sessions that have been idle too long
+  upgrade     Upgrade sandctl to the latest release
+  completion  Generate shell completion scripts
+
+Files:
+  Configuration, sessions, templates, and known host keys are kept in
+  ~/.sandctl. Set --data-dir or SANDCTL_DATA_DIR to use another directory;
+  the flag takes precedence over the variable.
 
 Get started:
   sandctl init
   sandctl new`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		datadir.Set(dataDir)
+		ui.SetColor(ui.ShouldUseColor(os.Stdout, noColor))
+		if err := ui.ValidateFormat(output); err != nil {
+			return err
+		}
+		return configureLogger(cmd)
+	},
+}
+
+// exitError makes a command exit with a specific status without printing an error.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
 }
 
 // Execute runs the root command.
 func Execute() int {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			return exitErr.code
+		}
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
@@ -76,25 +147,68 @@ func SetVersionInfo(v, c, b string) {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ~/.sandctl/config)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file, or - to read it from stdin (default: config in the data directory)")
+	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "directory for sandctl's config and state (default: $"+datadir.EnvVar+" or ~/.sandctl)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "config profile to use instead of active_profile")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "log level: debug, info, warn, error")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "skip SSH host key verification")
+	rootCmd.PersistentFlags().BoolVar(&useIPv6, "ipv6", false, "connect to sessions over IPv6 when available")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colors and Unicode status marks (also set by NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&refresh, "refresh", false, "query the provider instead of using VM state cached in the last few seconds")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", ui.FormatTable, "output format for commands that support it: table, json, yaml")
 
 	// Version command
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "output as JSON")
 	rootCmd.AddCommand(versionCmd)
 }
 
+var versionJSON bool
+
 // versionCmd shows version information.
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("sandctl version %s\n", version)
-		fmt.Printf("  commit: %s\n", commit)
-		fmt.Printf("  built:  %s\n", buildTime)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := currentVersionInfo()
+		if versionJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+
+		fmt.Printf("sandctl version %s\n", info.Version)
+		fmt.Printf("  commit: %s\n", info.Commit)
+		fmt.Printf("  built:  %s\n", info.BuildTime)
+		fmt.Printf("  go:     %s (%s/%s)\n", info.GoVersion, info.OS, info.Arch)
+		return nil
 	},
 }
 
-// loadConfig loads the configuration file.
+// versionInfo is the build metadata reported by the version command.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// currentVersionInfo returns the build metadata for this binary.
+func currentVersionInfo() versionInfo {
+	return versionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
+
+// loadConfig loads the configuration file and applies the profile selected
+// by --profile, or else the config's active_profile.
 func loadConfig() (*config.Config, error) {
 	if cfg != nil {
 		return cfg, nil
@@ -105,21 +219,90 @@ func loadConfig() (*config.Config, error) {
 		path = config.DefaultConfigPath()
 	}
 
+	var loaded *config.Config
 	var err error
-	cfg, err = config.Load(path)
+	if path == config.StdinPath {
+		loaded, err = loadConfigFromStdin()
+	} else {
+		loaded, err = config.Load(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := loaded.WithProfile(cmp.Or(profileName, loaded.ActiveProfile))
 	if err != nil {
 		return nil, err
 	}
 
+	baseCfg, cfg = loaded, selected
 	return cfg, nil
 }
 
+// profileConfig returns the config with the named profile applied, whatever
+// profile is selected for this command. An empty name means the top-level
+// settings.
+func profileConfig(name string) (*config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if name == cfg.ActiveProfile {
+		return cfg, nil
+	}
+	return baseCfg.WithProfile(name)
+}
+
+// loadConfigFromStdin reads the configuration for --config -. A terminal on
+// stdin almost certainly means the config was meant to be piped in.
+func loadConfigFromStdin() (*config.Config, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("--config - reads the config from stdin, but stdin is a terminal; pipe the config in, e.g. 'sandctl --config - list < config.yaml'")
+	}
+	return config.LoadReader(os.Stdin)
+}
+
+// configFileForWrite returns the config file path for commands that write
+// the config, which can't write to stdin.
+func configFileForWrite() (string, error) {
+	switch cfgFile {
+	case "":
+		return config.DefaultConfigPath(), nil
+	case config.StdinPath:
+		return "", fmt.Errorf("--config - reads the config from stdin and can't be written; pass a file path")
+	default:
+		return cfgFile, nil
+	}
+}
+
 // getSessionStore returns the session store, creating it if needed.
-func getSessionStore() *session.Store {
+// The backend is taken from SANDCTL_STORE, then the session_store config
+// setting, defaulting to the JSON file store. An unknown backend is an error
+// rather than a fallback, so sessions are never split across two stores.
+func getSessionStore() (session.Store, error) {
 	if sessionStore == nil {
-		sessionStore = session.NewStore("")
+		backend := os.Getenv("SANDCTL_STORE")
+		if backend == "" {
+			if cfg, err := loadConfig(); err == nil {
+				backend = cfg.SessionStore
+			}
+		}
+
+		store, err := session.OpenStore(backend, "")
+		if err != nil {
+			return nil, err
+		}
+		sessionStore = store
 	}
-	return sessionStore
+	return sessionStore, nil
+}
+
+// getVMCache returns the on-disk cache of provider VM listings.
+func getVMCache() *provider.VMCache {
+	if vmCache == nil {
+		vmCache = provider.NewVMCache(provider.DefaultVMCacheDir(), provider.DefaultVMCacheTTL)
+	}
+	return vmCache
 }
 
 // getProvider returns a provider by name, using the default if empty.
@@ -128,7 +311,12 @@ func getProvider(name string) (provider.Provider, error) {
 	if err != nil {
 		return nil, err
 	}
+	return providerFromConfig(cfg, name)
+}
 
+// providerFromConfig returns a provider by name configured from cfg, using
+// cfg's default if name is empty.
+func providerFromConfig(cfg *config.Config, name string) (provider.Provider, error) {
 	// Check for legacy config
 	if cfg.IsLegacyConfig() {
 		return nil, fmt.Errorf("legacy configuration detected\n\n%s", config.MigrationInstructions())
@@ -146,40 +334,264 @@ func getProvider(name string) (provider.Provider, error) {
 	return p, nil
 }
 
-// getProviderFromSession returns the provider for a specific session.
+// getProviderFromSession returns the provider for a specific session,
+// configured from the profile the session was created with.
 func getProviderFromSession(sess *session.Session) (provider.Provider, error) {
 	if sess.IsLegacySession() {
 		return nil, fmt.Errorf("session '%s' is from an old version and incompatible with current sandctl", sess.ID)
 	}
 
-	return getProvider(sess.Provider)
+	cfg, err := profileConfig(sess.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("session '%s': %w", sess.ID, err)
+	}
+	return providerFromConfig(cfg, sess.Provider)
+}
+
+// vmCacheKey returns the VM cache entry for a provider used with a profile,
+// so listings of different projects are kept apart.
+func vmCacheKey(profile, providerName string) string {
+	if profile == "" {
+		return providerName
+	}
+	return profile + "-" + providerName
+}
+
+// sessionAddress returns the address used to connect to a session, preferring
+// IPv6 if --ipv6 or the prefer_ipv6 config option is set.
+func sessionAddress(sess *session.Session) string {
+	return sess.SSHAddress(preferIPv6())
+}
+
+// preferIPv6 reports whether sessions are reached over IPv6, as set by --ipv6
+// or the prefer_ipv6 config option.
+func preferIPv6() bool {
+	if useIPv6 {
+		return true
+	}
+	cfg, err := loadConfig()
+	return err == nil && cfg.PreferIPv6
+}
+
+// providerSSHUser returns the SSH user configured for a provider, or "" for the default.
+func providerSSHUser(cfg *config.Config, providerName string) string {
+	if pc, ok := cfg.GetProviderConfig(providerName); ok {
+		return pc.SSHUser
+	}
+	return ""
+}
+
+// providerSSHPort returns the SSH port configured for a provider, or 0 for the default.
+func providerSSHPort(cfg *config.Config, providerName string) int {
+	if pc, ok := cfg.GetProviderConfig(providerName); ok {
+		return pc.SSHPort
+	}
+	return 0
+}
+
+// defaultSSHUser is the login user on images sandctl sets up itself.
+const defaultSSHUser = "agent"
+
+// sshUserOrDefault returns user, or the default login user if it is empty.
+func sshUserOrDefault(user string) string {
+	return cmp.Or(user, defaultSSHUser)
+}
+
+// remoteHomeDir returns the home directory of user on a sandbox VM.
+func remoteHomeDir(user string) string {
+	if user == "root" {
+		return "/root"
+	}
+	return "/home/" + user
+}
+
+// sshUserOptions returns the client options to log in as user.
+// An empty user keeps the sshexec default.
+func sshUserOptions(user string) []sshexec.ClientOption {
+	if user == "" {
+		return nil
+	}
+	return []sshexec.ClientOption{sshexec.WithUser(user)}
+}
+
+// sshPortOptions returns the client options to connect on port.
+// Zero keeps the sshexec default.
+func sshPortOptions(port int) []sshexec.ClientOption {
+	if port == 0 {
+		return nil
+	}
+	return []sshexec.ClientOption{sshexec.WithPort(port)}
+}
+
+// parseKeyValueFlags parses repeatable KEY=VALUE flag values into a map.
+// Later entries override earlier ones with the same key.
+func parseKeyValueFlags(flagName string, values []string) (map[string]string, error) {
+	result := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --%s value %q: expected KEY=VALUE", flagName, v)
+		}
+		result[key] = value
+	}
+	return result, nil
 }
 
 // createSSHClient creates an SSH client for the given host.
 // Handles both file mode (using private key file) and agent mode (using SSH agent).
-func createSSHClient(host string) (*sshexec.Client, error) {
+func createSSHClient(host string, opts ...sshexec.ClientOption) (*sshexec.Client, error) {
 	cfg, err := loadConfig()
 	if err != nil {
 		return nil, err
 	}
 
+	opts = withHostKeyVerification(withSSHConfig(cfg, opts))
+
 	if cfg.IsAgentMode() {
 		// Agent mode - get signer from SSH agent by fingerprint
 		signer, err := sshagent.GetSignerByFingerprint(cfg.SSHKeyFingerprint)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get SSH key from agent: %w", err)
 		}
-		return sshexec.NewClientWithSigner(host, signer), nil
+		return sshexec.NewClientWithSigner(host, signer, opts...), nil
 	}
 
-	// File mode - use private key file
+	// File mode - prefer the matching key from the agent so passphrase-protected
+	// keys work, then fall back to the private key file
 	pubKeyPath := cfg.ExpandSSHPublicKeyPath()
 	if pubKeyPath == "" {
 		return nil, fmt.Errorf("ssh_public_key not configured")
 	}
 
+	if signer, err := agentSignerForPublicKey(cfg); err == nil {
+		verboseLog("Using SSH agent key matching %s", pubKeyPath)
+		return sshexec.NewClientWithSigner(host, signer, opts...), nil
+	}
+
 	privateKeyPath := strings.TrimSuffix(pubKeyPath, ".pub")
-	return sshexec.NewClient(host, privateKeyPath)
+	return sshexec.NewClient(host, privateKeyPath, opts...)
+}
+
+// checkAgentKey returns an error if cfg uses the SSH agent and its key is no
+// longer loaded there, since sessions couldn't be connected to.
+func checkAgentKey(cfg *config.Config) error {
+	if !cfg.IsAgentMode() {
+		return nil
+	}
+	return sshagent.CheckKeyLoaded(cfg.SSHKeyFingerprint)
+}
+
+// createSessionSSHClient creates an SSH client for a session. Sessions created
+// with --ssh-key connect with that key instead of the configured one.
+func createSessionSSHClient(sess *session.Session, host string, opts ...sshexec.ClientOption) (*sshexec.Client, error) {
+	opts = append(append(sshUserOptions(sess.SSHUser), sshPortOptions(sess.SSHPort)...), opts...)
+	if sess.SSHKeyFingerprint == "" {
+		return createSSHClient(host, opts...)
+	}
+
+	if cfg, err := loadConfig(); err == nil {
+		opts = withSSHConfig(cfg, opts)
+	}
+	opts = withHostKeyVerification(opts)
+
+	signer, err := sshagent.GetSignerByFingerprint(sess.SSHKeyFingerprint)
+	if err == nil {
+		return sshexec.NewClientWithSigner(host, signer, opts...), nil
+	}
+	if sess.SSHKeyFile == "" {
+		return nil, fmt.Errorf("failed to get SSH key %s from agent: %w", sess.SSHKeyFingerprint, err)
+	}
+	return sshexec.NewClient(host, sess.SSHKeyFile, opts...)
+}
+
+// withHostKeyVerification prepends trust-on-first-use host key checking to
+// opts unless --insecure is set.
+func withHostKeyVerification(opts []sshexec.ClientOption) []sshexec.ClientOption {
+	if insecure {
+		return opts
+	}
+	return append([]sshexec.ClientOption{
+		sshexec.WithHostKeyCallback(sshexec.TOFUHostKeyCallback(sshexec.DefaultKnownHostsPath())),
+	}, opts...)
+}
+
+// withSSHConfig prepends ~/.ssh/config support to opts unless the
+// configuration disables it.
+func withSSHConfig(cfg *config.Config, opts []sshexec.ClientOption) []sshexec.ClientOption {
+	if cfg.IgnoreSSHConfig {
+		return opts
+	}
+	return append([]sshexec.ClientOption{sshexec.WithSSHConfig()}, opts...)
+}
+
+// agentSignerForPublicKey returns the agent signer whose fingerprint matches
+// the configured public key file.
+func agentSignerForPublicKey(cfg *config.Config) (ssh.Signer, error) {
+	pubKeyData, err := cfg.GetSSHPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pubKeyData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH public key: %w", err)
+	}
+
+	return sshagent.GetSignerByFingerprint(ssh.FingerprintSHA256(pubKey))
+}
+
+// forgetHostKey removes any recorded SSH host key for ip, logging failures.
+// Called when a VM is created or destroyed since provider IPs get recycled.
+func forgetHostKey(ip string) {
+	if ip == "" {
+		return
+	}
+	if _, err := sshexec.ForgetHost(sshexec.DefaultKnownHostsPath(), ip); err != nil {
+		logger.Warn("failed to forget host key", "host", ip, "error", err)
+	}
+}
+
+// configureLogger sets up the shared logger from the --log-level flag.
+// --verbose implies debug level unless --log-level is given explicitly.
+func configureLogger(cmd *cobra.Command) error {
+	level, err := parseLogLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	if verbose && !cmd.Flags().Changed("log-level") {
+		level = slog.LevelDebug
+	}
+	logger = newLogger(level)
+	// Packages without access to logger, such as providers, log through slog
+	slog.SetDefault(logger)
+	return nil
+}
+
+// parseLogLevel converts a --log-level value to a slog.Level.
+func parseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning", "":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %s (valid: debug, info, warn, error)", s)
+	}
+}
+
+// newLogger creates a text logger writing to stderr at the given level.
+func newLogger(level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, loggerOptions(level)))
+}
+
+// loggerOptions returns the handler options for the shared logger.
+// Secrets are masked in every message and attribute.
+func loggerOptions(level slog.Level) *slog.HandlerOptions {
+	return &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
 }
 
 // isVerbose returns true if verbose output is enabled.
@@ -188,8 +600,14 @@ func isVerbose() bool {
 }
 
 // verboseLog prints a message if verbose mode is enabled.
+// Secrets are masked before printing.
 func verboseLog(format string, args ...interface{}) {
 	if verbose {
-		fmt.Printf("[debug] "+format+"\n", args...)
+		writeVerboseLog(os.Stdout, format, args...)
 	}
 }
+
+// writeVerboseLog writes a redacted debug line to w.
+func writeVerboseLog(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprintln(w, "[debug] "+redact(fmt.Sprintf(format, args...)))
+}
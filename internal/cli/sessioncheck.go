@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+)
+
+// vmCheckTimeout bounds the provider lookup made before connecting to a session.
+const vmCheckTimeout = 10 * time.Second
+
+// ensureSessionVM confirms that a session's VM still exists at the provider
+// before connecting to it, so a VM deleted outside sandctl fails fast instead
+// of hanging on SSH. Provider errors other than not-found are logged and
+// ignored so an unreachable API doesn't block access to a working VM.
+func ensureSessionVM(store session.Store, sess *session.Session) error {
+	prov, err := getProviderFromSession(sess)
+	if err != nil {
+		verboseLog("Skipping provider check for %s: %v", sess.ID, err)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vmCheckTimeout)
+	defer cancel()
+
+	return checkSessionVM(ctx, prov, store, sess)
+}
+
+// checkSessionVM looks up the session's VM with prov. If the VM is gone the
+// session is marked stopped and an error is returned; if its addresses
+// changed they are updated in sess and the store.
+func checkSessionVM(ctx context.Context, prov provider.Provider, store session.Store, sess *session.Session) error {
+	vm, err := prov.Get(ctx, sess.ProviderID)
+	if errors.Is(err, provider.ErrNotFound) {
+		if err := store.Update(sess.ID, session.StatusStopped); err != nil {
+			logger.Warn("failed to update session", "session", sess.ID, "error", err)
+		}
+		return fmt.Errorf("session '%s' no longer exists on provider; run 'sandctl destroy %s' to clean up", sess.ID, sess.ID)
+	}
+	if err != nil {
+		verboseLog("Failed to check VM for %s: %v", sess.ID, err)
+		return nil
+	}
+
+	if (vm.IPAddress != "" && vm.IPAddress != sess.IPAddress) || (vm.IPv6 != "" && vm.IPv6 != sess.IPv6) {
+		if vm.IPAddress != "" {
+			sess.IPAddress = vm.IPAddress
+		}
+		if vm.IPv6 != "" {
+			sess.IPv6 = vm.IPv6
+		}
+		if err := store.UpdateSession(*sess); err != nil {
+			logger.Warn("failed to update session", "session", sess.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// markSessionActive records that the session is in use now, so the watchdog
+// doesn't consider it idle.
+func markSessionActive(store session.Store, sess *session.Session) {
+	now := time.Now()
+	sess.LastActiveAt = &now
+	if err := store.UpdateSession(*sess); err != nil {
+		verboseLog("Failed to record activity for %s: %v", sess.ID, err)
+	}
+}
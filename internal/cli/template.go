@@ -22,11 +22,11 @@ setting up development environments, installing dependencies, and configuring
 project-specific tools.
 
 Subcommands:
-  add     Create a new template configuration
+  add     Create a new template configuration (alias: create)
   list    List all configured templates
   show    Display the init script for a template
   edit    Open the init script in your editor
-  remove  Delete a template configuration
+  remove  Delete a template configuration (alias: delete)
 
 Example workflow:
   sandctl template add Ghost          # Create template
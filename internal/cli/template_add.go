@@ -11,8 +11,9 @@ import (
 )
 
 var templateAddCmd = &cobra.Command{
-	Use:   "add <name>",
-	Short: "Create a new template configuration",
+	Use:     "add <name>",
+	Aliases: []string{"create"},
+	Short:   "Create a new template configuration",
 	Long: `Create a new template configuration with an init script template.
 
 The init script will be stored at ~/.sandctl/templates/<name>/init.sh and will
@@ -46,8 +47,12 @@ func runTemplateAdd(cmd *cobra.Command, args []string) error {
 	config, err := store.Add(name)
 	if err != nil {
 		// T030: Error handling for duplicate template name
-		if _, ok := err.(*templateconfig.AlreadyExistsError); ok {
-			fmt.Fprintf(os.Stderr, "Error: Template '%s' already exists. Use 'sandctl template edit %s' to modify it.\n", name, name)
+		if existsErr, ok := err.(*templateconfig.AlreadyExistsError); ok {
+			existing := existsErr.Existing
+			if existing == "" {
+				existing = name
+			}
+			fmt.Fprintf(os.Stderr, "Error: Template '%s' already exists. Use 'sandctl template edit %s' to modify it.\n", existing, existing)
 			return nil
 		}
 		return fmt.Errorf("failed to create template: %w", err)
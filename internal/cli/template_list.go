@@ -2,10 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/ui"
 )
 
 var templateListCmd = &cobra.Command{
@@ -13,9 +17,13 @@ var templateListCmd = &cobra.Command{
 	Short: "List all configured templates",
 	Long: `List all configured templates.
 
-Displays a table of templates with their names and creation dates.`,
+Displays a table of templates with their names, whether they have an
+init script, and creation dates.`,
 	Example: `  # List all templates
-  sandctl template list`,
+  sandctl template list
+
+  # Output as YAML
+  sandctl template list -o yaml`,
 	Args: cobra.NoArgs,
 	RunE: runTemplateList,
 }
@@ -24,6 +32,14 @@ func init() {
 	templateCmd.AddCommand(templateListCmd)
 }
 
+// templateListItem is the JSON/YAML representation of a template.
+type templateListItem struct {
+	Name       string    `json:"name"`
+	Template   string    `json:"template"`
+	InitScript bool      `json:"init_script"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 func runTemplateList(cmd *cobra.Command, args []string) error {
 	store := getTemplateStore()
 
@@ -33,23 +49,41 @@ func runTemplateList(cmd *cobra.Command, args []string) error {
 	}
 
 	// T040: Handle empty list
-	if len(configs) == 0 {
+	if len(configs) == 0 && output == ui.FormatTable {
 		fmt.Println("No templates configured.")
 		fmt.Println()
 		fmt.Println("Create one with: sandctl template add <name>")
 		return nil
 	}
 
-	// T039: Tabular output with NAME and CREATED columns
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tCREATED")
-
+	items := make([]templateListItem, 0, len(configs))
 	for _, config := range configs {
-		fmt.Fprintf(w, "%s\t%s\n",
-			config.OriginalName,
-			config.CreatedAt.Format("2006-01-02 15:04:05"),
-		)
+		items = append(items, templateListItem{
+			Name:       config.OriginalName,
+			Template:   config.Template,
+			InitScript: store.HasInitScript(config.Template),
+			CreatedAt:  config.CreatedAt,
+		})
 	}
 
-	return w.Flush()
+	return ui.Render(os.Stdout, output, items, func(out io.Writer) error {
+		// T039: Tabular output with NAME, INIT SCRIPT and CREATED columns
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tINIT SCRIPT\tCREATED")
+
+		for _, item := range items {
+			initScript := "no"
+			if item.InitScript {
+				initScript = "yes"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n",
+				item.Name,
+				initScript,
+				item.CreatedAt.Format("2006-01-02 15:04:05"),
+			)
+		}
+
+		return w.Flush()
+	})
 }
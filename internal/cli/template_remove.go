@@ -17,8 +17,9 @@ var (
 )
 
 var templateRemoveCmd = &cobra.Command{
-	Use:   "remove <name>",
-	Short: "Delete a template",
+	Use:     "remove <name>",
+	Aliases: []string{"delete"},
+	Short:   "Delete a template",
 	Long: `Delete a template configuration and its init script.
 
 By default, this command prompts for confirmation before deleting.
@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/ui"
+)
+
+// latestReleaseURL is the GitHub API endpoint for the latest sandctl release.
+var latestReleaseURL = "https://api.github.com/repos/sandctl/sandctl/releases/latest"
+
+// checksumsAssetName is the release asset listing SHA-256 checksums of the binaries.
+const checksumsAssetName = "checksums.txt"
+
+// upgradeHTTPTimeout bounds release lookups and downloads.
+const upgradeHTTPTimeout = 5 * time.Minute
+
+var (
+	upgradeCheck bool
+	upgradeYes   bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade sandctl to the latest release",
+	Long: `Check GitHub for the latest sandctl release and replace the running
+binary with it.
+
+The downloaded binary is verified against the release's published SHA-256
+checksum before it is installed.
+
+Use --check to only report whether an update is available. It exits with
+status 1 when a newer release exists, for use in shell prompts and scripts.`,
+	Example: `  # Upgrade to the latest release
+  sandctl upgrade
+
+  # Only check whether an update is available
+  sandctl upgrade --check`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "only check for an update; exit 1 if one is available")
+	upgradeCmd.Flags().BoolVarP(&upgradeYes, "yes", "y", false, "skip confirmation prompt")
+
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+// githubRelease is the subset of the GitHub release API response used here.
+type githubRelease struct {
+	TagName string               `json:"tag_name"`
+	Assets  []githubReleaseAsset `json:"assets"`
+}
+
+// githubReleaseAsset is a downloadable file attached to a release.
+type githubReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// assetURL returns the download URL of the named asset.
+func (r *githubRelease) assetURL(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.BrowserDownloadURL, true
+		}
+	}
+	return "", false
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), upgradeHTTPTimeout)
+	defer cancel()
+
+	if _, ok := parseVersion(version); !ok {
+		return fmt.Errorf("cannot check for updates: running a development build (%s)", version)
+	}
+
+	release, err := fetchLatestRelease(ctx)
+	if err != nil {
+		return err
+	}
+
+	newer, err := isNewerVersion(release.TagName, version)
+	if err != nil {
+		return err
+	}
+	if !newer {
+		fmt.Printf("sandctl %s is up to date.\n", version)
+		return nil
+	}
+
+	if upgradeCheck {
+		fmt.Printf("Update available: %s -> %s\n", version, release.TagName)
+		return &exitError{code: 1}
+	}
+
+	if !upgradeYes {
+		confirmed, err := ui.Confirm(os.Stdin, os.Stdout,
+			fmt.Sprintf("Upgrade sandctl %s to %s?", version, release.TagName))
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Canceled.")
+			return nil
+		}
+	}
+
+	spin := ui.NewSpinner(os.Stdout)
+	spin.Start(fmt.Sprintf("Downloading sandctl %s", release.TagName))
+	if err := installRelease(ctx, release); err != nil {
+		spin.Fail("Upgrade failed")
+		return err
+	}
+	spin.Success(fmt.Sprintf("Upgraded sandctl to %s.", release.TagName))
+	return nil
+}
+
+// fetchLatestRelease queries GitHub for the latest release.
+func fetchLatestRelease(ctx context.Context) (*githubRelease, error) {
+	body, err := httpGet(ctx, latestReleaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer body.Close()
+
+	var release githubRelease
+	if err := json.NewDecoder(body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("failed to parse release: missing tag name")
+	}
+	return &release, nil
+}
+
+// installRelease downloads the binary for this platform, verifies its
+// checksum, and atomically replaces the running executable.
+func installRelease(ctx context.Context, release *githubRelease) error {
+	assetName := fmt.Sprintf("sandctl-%s-%s", runtime.GOOS, runtime.GOARCH)
+
+	binaryURL, ok := release.assetURL(assetName)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksumsURL, ok := release.assetURL(checksumsAssetName)
+	if !ok {
+		return fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, checksumsAssetName)
+	}
+
+	expected, err := fetchChecksum(ctx, checksumsURL, assetName)
+	if err != nil {
+		return err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate current binary: %w", err)
+	}
+	exePath, err = filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to locate current binary: %w", err)
+	}
+
+	// Download next to the current binary so the final rename is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(exePath), ".sandctl-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	body, err := httpGet(ctx, binaryURL)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download binary: %w", err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), body)
+	body.Close()
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != expected {
+		return fmt.Errorf("checksum mismatch for %s: got %s, expected %s", assetName, got, expected)
+	}
+
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+	return nil
+}
+
+// fetchChecksum downloads a checksums file and returns the SHA-256 for assetName.
+func fetchChecksum(ctx context.Context, url, assetName string) (string, error) {
+	body, err := httpGet(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums: %w", err)
+	}
+	defer body.Close()
+
+	return parseChecksum(body, assetName)
+}
+
+// parseChecksum finds assetName in sha256sum-formatted output ("<hash>  <name>").
+func parseChecksum(r io.Reader, assetName string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
+	return "", fmt.Errorf("no checksum published for %s", assetName)
+}
+
+// httpGet performs a GET request and returns the body of a 200 response.
+func httpGet(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// isNewerVersion reports whether latest is a higher version than current.
+func isNewerVersion(latest, current string) (bool, error) {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false, fmt.Errorf("unrecognized release version: %s", latest)
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false, fmt.Errorf("unrecognized current version: %s", current)
+	}
+
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersion parses a "v1.2.3" style version into its numeric parts.
+// Pre-release and build suffixes are ignored.
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
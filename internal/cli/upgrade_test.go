@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestIsNewerVersion_GivenVersions_ThenComparesNumerically tests version comparison.
+func TestIsNewerVersion_GivenVersions_ThenComparesNumerically(t *testing.T) {
+	tests := []struct {
+		latest   string
+		current  string
+		expected bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v2.0.0", "1.99.99", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.3.0", false},
+		{"v1.2", "v1.2.0", false},
+		{"v1.2.1", "v1.2.1-3-gabc123-dirty", false},
+		{"v1.2.2", "v1.2.1-3-gabc123-dirty", true},
+	}
+
+	for _, tt := range tests {
+		got, err := isNewerVersion(tt.latest, tt.current)
+		if err != nil {
+			t.Errorf("isNewerVersion(%q, %q) error = %v", tt.latest, tt.current, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("isNewerVersion(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.expected)
+		}
+	}
+}
+
+// TestIsNewerVersion_GivenUnparseableVersion_ThenReturnsError tests dev builds.
+func TestIsNewerVersion_GivenUnparseableVersion_ThenReturnsError(t *testing.T) {
+	if _, err := isNewerVersion("v1.0.0", "dev"); err == nil {
+		t.Error("expected error for dev version")
+	}
+	if _, err := isNewerVersion("nightly", "v1.0.0"); err == nil {
+		t.Error("expected error for non-semver release tag")
+	}
+}
+
+// TestParseChecksum_GivenChecksumsFile_ThenReturnsAssetHash tests checksum lookup.
+func TestParseChecksum_GivenChecksumsFile_ThenReturnsAssetHash(t *testing.T) {
+	checksums := "aaaa  sandctl-darwin-arm64\nBBBB *sandctl-linux-amd64\n"
+
+	got, err := parseChecksum(strings.NewReader(checksums), "sandctl-linux-amd64")
+	if err != nil {
+		t.Fatalf("parseChecksum() error = %v", err)
+	}
+	if got != "bbbb" {
+		t.Errorf("parseChecksum() = %q, want %q", got, "bbbb")
+	}
+
+	if _, err := parseChecksum(strings.NewReader(checksums), "sandctl-windows-amd64"); err == nil {
+		t.Error("expected error for missing asset")
+	}
+}
+
+// TestFetchLatestRelease_GivenAPIResponse_ThenParsesRelease tests release lookup.
+func TestFetchLatestRelease_GivenAPIResponse_ThenParsesRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name":"v1.4.0","assets":[{"name":"checksums.txt","browser_download_url":"https://example.com/checksums.txt"}]}`)
+	}))
+	defer server.Close()
+
+	oldURL := latestReleaseURL
+	latestReleaseURL = server.URL
+	t.Cleanup(func() { latestReleaseURL = oldURL })
+
+	release, err := fetchLatestRelease(context.Background())
+	if err != nil {
+		t.Fatalf("fetchLatestRelease() error = %v", err)
+	}
+	if release.TagName != "v1.4.0" {
+		t.Errorf("TagName = %q, want %q", release.TagName, "v1.4.0")
+	}
+	if url, ok := release.assetURL(checksumsAssetName); !ok || url != "https://example.com/checksums.txt" {
+		t.Errorf("assetURL(%q) = %q, %v", checksumsAssetName, url, ok)
+	}
+}
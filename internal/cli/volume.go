@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// volumeMountPoint is where cloud-init mounts a volume from --attach-volume.
+const volumeMountPoint = "/data"
+
+// deleteSessionVolume removes a session's data volume, if it has one.
+func deleteSessionVolume(ctx context.Context, prov provider.Provider, volumeID string) error {
+	if volumeID == "" {
+		return nil
+	}
+
+	vm, ok := prov.(provider.VolumeManager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support volumes", prov.Name())
+	}
+	return vm.DeleteVolume(ctx, volumeID)
+}
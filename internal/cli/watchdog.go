@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandctl/sandctl/internal/provider"
+	"github.com/sandctl/sandctl/internal/session"
+	"github.com/sandctl/sandctl/internal/sshexec"
+)
+
+const (
+	// watchdogConnectTimeout bounds the SSH connection used to probe a session.
+	watchdogConnectTimeout = 15 * time.Second
+
+	// watchdogStopTimeout bounds stopping a single idle session.
+	watchdogStopTimeout = 5 * time.Minute
+)
+
+// idleProbeCommand prints the number of logged-in users and the one-minute
+// load average, one per line.
+const idleProbeCommand = "who | wc -l; cut -d ' ' -f 1 /proc/loadavg"
+
+var (
+	watchdogIdleAfter time.Duration
+	watchdogMaxLoad   float64
+	watchdogDryRun    bool
+)
+
+var watchdogCmd = &cobra.Command{
+	Use:   "watchdog",
+	Short: "Stop sessions that have been idle too long",
+	Long: `Check every running session and stop the ones nobody is using.
+
+A session is busy if anyone is logged in (as reported by 'who') or its load
+average is at or above --max-load. Busy sessions, and sessions used through
+'sandctl exec' or 'sandctl console', have their last activity time recorded.
+Sessions idle for longer than --idle-after are shut down, keeping their disk.
+
+Stopped VMs may still be billed by the provider; use 'sandctl destroy' to
+remove them. The command is meant to be run periodically, for example from
+cron.`,
+	Example: `  # Stop sessions idle for over an hour
+  sandctl watchdog
+
+  # Show what would be stopped after 30 minutes of idleness
+  sandctl watchdog --idle-after 30m --dry-run
+
+  # Run every 10 minutes from cron
+  */10 * * * * sandctl watchdog`,
+	Args: cobra.NoArgs,
+	RunE: runWatchdog,
+}
+
+func init() {
+	watchdogCmd.Flags().DurationVar(&watchdogIdleAfter, "idle-after", time.Hour, "stop sessions idle for longer than this")
+	watchdogCmd.Flags().Float64Var(&watchdogMaxLoad, "max-load", 0.2, "load average below which a session counts as idle")
+	watchdogCmd.Flags().BoolVar(&watchdogDryRun, "dry-run", false, "report idle sessions without stopping them")
+
+	rootCmd.AddCommand(watchdogCmd)
+}
+
+func runWatchdog(cmd *cobra.Command, args []string) error {
+	if watchdogIdleAfter <= 0 {
+		return fmt.Errorf("--idle-after must be positive")
+	}
+	if watchdogMaxLoad < 0 {
+		return fmt.Errorf("--max-load cannot be negative")
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	// Skip sessions whose VMs were stopped or deleted outside sandctl
+	ctx, cancel := context.WithTimeout(context.Background(), vmCheckTimeout)
+	sessions = syncWithProviderAPI(ctx, sessions, store)
+	cancel()
+
+	var targets []session.Session
+	for _, sess := range sessions {
+		if sess.IsLegacySession() || sess.Status != session.StatusRunning || sessionAddress(&sess) == "" {
+			continue
+		}
+		targets = append(targets, sess)
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No running sessions.")
+		return nil
+	}
+
+	failed := 0
+	for i := range targets {
+		sess := &targets[i]
+
+		users, load, err := probeSessionIdle(sess)
+		if err != nil {
+			failed++
+			fmt.Printf("%-18s error: %v\n", sess.ID, err)
+			continue
+		}
+
+		if users > 0 || load >= watchdogMaxLoad {
+			markSessionActive(store, sess)
+			fmt.Printf("%-18s active (%d users, load %.2f)\n", sess.ID, users, load)
+			continue
+		}
+
+		idle := time.Since(sess.IdleSince()).Round(time.Minute)
+		switch {
+		case idle < watchdogIdleAfter:
+			fmt.Printf("%-18s idle for %s\n", sess.ID, idle)
+		case watchdogDryRun:
+			fmt.Printf("%-18s idle for %s, would stop\n", sess.ID, idle)
+		default:
+			if err := stopIdleSession(store, sess); err != nil {
+				failed++
+				fmt.Printf("%-18s idle for %s, stop failed: %v\n", sess.ID, idle, err)
+				continue
+			}
+			fmt.Printf("%-18s idle for %s, stopped\n", sess.ID, idle)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("watchdog failed on %d of %d sessions", failed, len(targets))
+	}
+	return nil
+}
+
+// probeSessionIdle connects to a session and returns its number of
+// logged-in users and one-minute load average.
+func probeSessionIdle(sess *session.Session) (int, float64, error) {
+	client, err := createSessionSSHClient(sess, sessionAddress(sess), sshexec.WithTimeout(watchdogConnectTimeout))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer client.Close()
+
+	output, err := client.Exec(idleProbeCommand)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseIdleProbe(output)
+}
+
+// parseIdleProbe parses the output of idleProbeCommand.
+func parseIdleProbe(output string) (int, float64, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected idle probe output: %q", output)
+	}
+
+	users, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid user count %q", fields[0])
+	}
+	load, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid load average %q", fields[1])
+	}
+	return users, load, nil
+}
+
+// stopIdleSession stops a session's VM and marks the session stopped.
+func stopIdleSession(store session.Store, sess *session.Session) error {
+	prov, err := getProviderFromSession(sess)
+	if err != nil {
+		return err
+	}
+
+	pm, ok := prov.(provider.PowerManager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support stopping VMs", prov.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), watchdogStopTimeout)
+	defer cancel()
+
+	if err := pm.Stop(ctx, sess.ProviderID); err != nil {
+		return err
+	}
+	getVMCache().Invalidate(vmCacheKey(sess.Profile, prov.Name()))
+
+	if err := store.Update(sess.ID, session.StatusStopped); err != nil {
+		logger.Warn("failed to mark session stopped", "session", sess.ID, "error", err)
+	}
+	return nil
+}
@@ -2,12 +2,22 @@
 package config
 
 import (
+	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/sandctl/sandctl/internal/datadir"
 )
 
 // GitConfig holds git configuration to apply in sandbox.
@@ -23,13 +33,38 @@ type GitConfig struct {
 	UserEmail string
 }
 
+// Prefixes that make a provider token a reference to a secret held elsewhere.
+const (
+	TokenEnvPrefix     = "env:" // env:NAME reads the environment variable NAME
+	TokenCommandPrefix = "cmd:" // cmd:COMMAND runs COMMAND and uses its output
+)
+
 // ProviderConfig holds provider-specific configuration.
 type ProviderConfig struct {
-	Token      string `yaml:"token"`
+	Token      string `yaml:"token"` // API token, or an env:/cmd: reference to one
 	Region     string `yaml:"region,omitempty"`
 	ServerType string `yaml:"server_type,omitempty"`
 	Image      string `yaml:"image,omitempty"`
 	SSHKeyID   int64  `yaml:"ssh_key_id,omitempty"` // Cached provider SSH key ID
+	SSHUser    string `yaml:"ssh_user,omitempty"`   // SSH login user (default: agent)
+	SSHPort    int    `yaml:"ssh_port,omitempty"`   // SSH port (default: 22)
+
+	// Network and PlacementGroup are the name or ID of a network and
+	// placement group to attach new servers to. A placement group that
+	// doesn't exist yet is created as a spread group
+	Network        string `yaml:"network,omitempty"`
+	PlacementGroup string `yaml:"placement_group,omitempty"`
+}
+
+// Profile is an alternative set of providers and defaults, such as a
+// separate provider project with its own token. Settings a profile leaves
+// unset are taken from the top level of the config.
+type Profile struct {
+	DefaultProvider string                    `yaml:"default_provider,omitempty"`
+	Providers       map[string]ProviderConfig `yaml:"providers,omitempty"`
+	SSHUser         string                    `yaml:"ssh_user,omitempty"`
+	Image           string                    `yaml:"image,omitempty"`
+	DefaultTimeout  string                    `yaml:"default_timeout,omitempty"`
 }
 
 // Config represents the sandctl configuration.
@@ -39,11 +74,36 @@ type Config struct {
 	SSHPublicKey    string                    `yaml:"ssh_public_key,omitempty"`
 	Providers       map[string]ProviderConfig `yaml:"providers,omitempty"`
 
+	// SSHUser and Image are defaults for every provider, used when the
+	// provider's own settings leave them unset
+	SSHUser string `yaml:"ssh_user,omitempty"`
+	Image   string `yaml:"image,omitempty"`
+
 	// SSH key agent mode fields
 	SSHKeySource       string `yaml:"ssh_key_source,omitempty"`        // "file" or "agent"
 	SSHPublicKeyInline string `yaml:"ssh_public_key_inline,omitempty"` // Agent mode: full public key
 	SSHKeyFingerprint  string `yaml:"ssh_key_fingerprint,omitempty"`   // Agent mode: SHA256 fingerprint
 
+	// PreferIPv6 connects to sessions over IPv6 when both addresses are known
+	PreferIPv6 bool `yaml:"prefer_ipv6,omitempty"`
+
+	// IgnoreSSHConfig connects to sessions directly instead of applying the
+	// Port, ProxyJump, and ProxyCommand of a matching ~/.ssh/config entry
+	IgnoreSSHConfig bool `yaml:"ignore_ssh_config,omitempty"`
+
+	// DefaultTimeout is the auto-destroy timeout applied to new sessions when
+	// none is given on the command line (e.g., "8h")
+	DefaultTimeout string `yaml:"default_timeout,omitempty"`
+
+	// SessionStore selects the local session store backend ("json" or "sqlite")
+	SessionStore string `yaml:"session_store,omitempty"`
+
+	// Profiles are named alternatives to the top-level providers and
+	// defaults. ActiveProfile selects the one used when --profile isn't
+	// given; empty means the top-level settings
+	Profiles      map[string]Profile `yaml:"profiles,omitempty"`
+	ActiveProfile string             `yaml:"active_profile,omitempty"`
+
 	// Legacy fields (for migration detection)
 	SpritesToken   string `yaml:"sprites_token,omitempty"`
 	OpencodeZenKey string `yaml:"opencode_zen_key,omitempty"`
@@ -57,12 +117,50 @@ type Config struct {
 	GitHubToken string `yaml:"github_token,omitempty"` // GitHub personal access token (optional)
 }
 
+// ResolveToken returns the provider API token, following env: and cmd:
+// references. Commands run through sh, and their output is trimmed. Any other
+// value is returned unchanged.
+func (p *ProviderConfig) ResolveToken() (string, error) {
+	switch {
+	case strings.HasPrefix(p.Token, TokenEnvPrefix):
+		name := strings.TrimPrefix(p.Token, TokenEnvPrefix)
+		token := os.Getenv(name)
+		if token == "" {
+			return "", fmt.Errorf("token environment variable %s is not set", name)
+		}
+		return token, nil
+
+	case strings.HasPrefix(p.Token, TokenCommandPrefix):
+		command := strings.TrimPrefix(p.Token, TokenCommandPrefix)
+		var stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("token command failed: %w: %s", err, msg)
+			}
+			return "", fmt.Errorf("token command failed: %w", err)
+		}
+		token := strings.TrimSpace(string(out))
+		if token == "" {
+			return "", fmt.Errorf("token command produced no output")
+		}
+		return token, nil
+
+	default:
+		return p.Token, nil
+	}
+}
+
 // IsLegacyConfig returns true if this is an old sprites-based config.
 func (c *Config) IsLegacyConfig() bool {
 	return c.SpritesToken != "" && c.DefaultProvider == ""
 }
 
-// GetProviderConfig returns the configuration for a specific provider.
+// GetProviderConfig returns a copy of the configuration for a specific
+// provider. SSH user and image fall back to the top-level defaults when the
+// provider doesn't set them.
 func (c *Config) GetProviderConfig(name string) (*ProviderConfig, bool) {
 	if c.Providers == nil {
 		return nil, false
@@ -71,9 +169,47 @@ func (c *Config) GetProviderConfig(name string) (*ProviderConfig, bool) {
 	if !ok {
 		return nil, false
 	}
+	cfg.SSHUser = cmp.Or(cfg.SSHUser, c.SSHUser)
+	cfg.Image = cmp.Or(cfg.Image, c.Image)
 	return &cfg, true
 }
 
+// DefaultProfile names the top-level providers and defaults, used when no
+// profile is selected.
+const DefaultProfile = "default"
+
+// WithProfile returns a copy of the config with the named profile's providers
+// and defaults in place of the top-level ones, and ActiveProfile set to name.
+// An empty name or DefaultProfile keeps the top-level settings and clears
+// ActiveProfile.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	overlaid := *c
+	overlaid.ActiveProfile = ""
+	if name == "" || name == DefaultProfile {
+		return &overlaid, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile '%s' is not configured", name)
+	}
+
+	if len(profile.Providers) > 0 {
+		overlaid.Providers = profile.Providers
+	}
+	overlaid.DefaultProvider = cmp.Or(profile.DefaultProvider, c.DefaultProvider)
+	overlaid.SSHUser = cmp.Or(profile.SSHUser, c.SSHUser)
+	overlaid.Image = cmp.Or(profile.Image, c.Image)
+	overlaid.DefaultTimeout = cmp.Or(profile.DefaultTimeout, c.DefaultTimeout)
+	overlaid.ActiveProfile = name
+	return &overlaid, nil
+}
+
+// ProfileNames returns the names of the configured profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
 // SetProviderSSHKeyID updates the cached SSH key ID for a provider.
 func (c *Config) SetProviderSSHKeyID(providerName string, keyID int64) {
 	if c.Providers == nil {
@@ -240,15 +376,15 @@ func isValidGitEmail(email string) bool {
 	return len(parts) == 2 && len(parts[0]) > 0 && len(parts[1]) > 0
 }
 
-// DefaultConfigPath returns the default config file path.
+// DefaultConfigPath returns the default config file path (config in the data
+// directory).
 func DefaultConfigPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ".sandctl/config"
-	}
-	return filepath.Join(home, ".sandctl", "config")
+	return datadir.Path("config")
 }
 
+// StdinPath is the config path that means the config is read from stdin.
+const StdinPath = "-"
+
 // Load reads and parses the config file from the given path.
 func Load(path string) (*Config, error) {
 	if path == "" {
@@ -280,9 +416,30 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse YAML
+	return parse(data, path)
+}
+
+// LoadReader reads and parses a config from r, such as stdin. There is no
+// file, so its permissions aren't checked; the config is validated as usual.
+func LoadReader(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return parse(data, "<stdin>")
+}
+
+// parse decodes and validates config YAML read from path.
+func parse(data []byte, path string) (*Config, error) {
+	// Parse YAML, rejecting unknown keys so typos don't go unnoticed.
+	// Legacy keys such as sprites_token are still fields of Config.
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		if unknown := unknownFieldError(path, err); unknown != nil {
+			return nil, unknown
+		}
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
@@ -332,7 +489,21 @@ func (c *Config) validateProviderConfig() error {
 		return err
 	}
 
-	// Validate each provider config
+	if c.DefaultTimeout != "" {
+		if d, err := time.ParseDuration(c.DefaultTimeout); err != nil || d < 0 {
+			return &ValidationError{Field: "default_timeout", Message: "must be a duration like 8h or 30m"}
+		}
+	}
+
+	if err := c.validateProviders(); err != nil {
+		return err
+	}
+
+	return c.validateProfiles()
+}
+
+// validateProviders validates each provider config.
+func (c *Config) validateProviders() error {
 	for name, provCfg := range c.Providers {
 		if provCfg.Token == "" {
 			return &ValidationError{
@@ -340,6 +511,62 @@ func (c *Config) validateProviderConfig() error {
 				Message: "is required",
 			}
 		}
+		if provCfg.Token == TokenEnvPrefix || provCfg.Token == TokenCommandPrefix {
+			return &ValidationError{
+				Field:   fmt.Sprintf("providers.%s.token", name),
+				Message: fmt.Sprintf("%q must be followed by a variable name or command", provCfg.Token),
+			}
+		}
+		if provCfg.SSHPort < 0 || provCfg.SSHPort > 65535 {
+			return &ValidationError{
+				Field:   fmt.Sprintf("providers.%s.ssh_port", name),
+				Message: "must be between 1 and 65535",
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateProfiles checks that the active profile exists and that each
+// profile, overlaid on the top-level settings, names a configured default
+// provider with valid settings.
+func (c *Config) validateProfiles() error {
+	if c.ActiveProfile != "" && c.ActiveProfile != DefaultProfile {
+		if _, ok := c.Profiles[c.ActiveProfile]; !ok {
+			return &ValidationError{
+				Field:   "active_profile",
+				Message: fmt.Sprintf("'%s' is not configured in profiles", c.ActiveProfile),
+			}
+		}
+	}
+
+	for _, name := range c.ProfileNames() {
+		field := "profiles." + name
+		if name == DefaultProfile {
+			return &ValidationError{Field: field, Message: "is reserved for the top-level settings"}
+		}
+
+		p, _ := c.WithProfile(name)
+
+		if _, ok := p.Providers[p.DefaultProvider]; !ok {
+			return &ValidationError{
+				Field:   field + ".default_provider",
+				Message: fmt.Sprintf("'%s' is not configured in providers", p.DefaultProvider),
+			}
+		}
+		if p.DefaultTimeout != "" {
+			if d, err := time.ParseDuration(p.DefaultTimeout); err != nil || d < 0 {
+				return &ValidationError{Field: field + ".default_timeout", Message: "must be a duration like 8h or 30m"}
+			}
+		}
+		if err := p.validateProviders(); err != nil {
+			var ve *ValidationError
+			if errors.As(err, &ve) {
+				return &ValidationError{Field: field + "." + ve.Field, Message: ve.Message}
+			}
+			return err
+		}
 	}
 
 	return nil
@@ -428,6 +655,23 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("config validation failed: %s %s", e.Field, e.Message)
 }
 
+// UnknownFieldError is returned when the config file contains a key that
+// sandctl doesn't recognize, usually a typo.
+type UnknownFieldError struct {
+	Path       string
+	Line       int
+	Field      string
+	Suggestion string // Closest known key, if any
+}
+
+func (e *UnknownFieldError) Error() string {
+	msg := fmt.Sprintf("unknown config key %q on line %d of %s", e.Field, e.Line, e.Path)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", e.Suggestion)
+	}
+	return msg
+}
+
 // SetupInstructions returns instructions for setting up the config file.
 func SetupInstructions() string {
 	return fmt.Sprintf(`Configuration required.
@@ -454,12 +698,57 @@ After creating the file, set secure permissions:
 `, DefaultConfigPath(), DefaultConfigPath())
 }
 
+// Template returns a commented configuration file with placeholder values,
+// for filling in by hand. It is valid YAML using only keys Load accepts.
+func Template() string {
+	return `# sandctl configuration
+# Fill in the placeholders, save as ~/.sandctl/config, and restrict access:
+#   chmod 600 ~/.sandctl/config
+
+default_provider: hetzner
+
+# SSH public key installed on new VMs. To use a key held by an SSH agent
+# (1Password, ssh-agent) instead, remove ssh_public_key and set:
+#   ssh_key_source: agent
+#   ssh_public_key_inline: "ssh-ed25519 AAAA... you@example.com"
+#   ssh_key_fingerprint: "SHA256:..."
+ssh_public_key: ~/.ssh/id_ed25519.pub
+
+# Optional: defaults for every provider, unless the provider sets its own
+# image: ubuntu-24.04
+# ssh_user: agent
+
+providers:
+  hetzner:
+    # API token from https://console.hetzner.cloud. To keep it out of this
+    # file, use env:VARIABLE or cmd:command instead.
+    token: "your-hetzner-api-token"
+    # Region: ash, hel1, fsn1, nbg1
+    region: ash
+    # Server type: cpx21, cpx31, cpx41
+    server_type: cpx31
+    image: ubuntu-24.04
+
+# Git identity for commits made in sandboxes. Either copy a gitconfig file:
+#   git_config_path: ~/.gitconfig
+# or set a name and email:
+git_user_name: "Your Name"
+git_user_email: "you@example.com"
+
+# Optional: Opencode Zen key from https://opencode.ai/settings
+# opencode_zen_key: "your-opencode-zen-key"
+
+# Optional: GitHub token for creating pull requests from sandboxes
+# github_token: "your-github-token"
+`
+}
+
 // MigrationInstructions returns instructions for migrating from old config.
 func MigrationInstructions() string {
 	return `Your configuration uses the old Sprites format.
 
-Sprites has been replaced with pluggable VM providers. Run 'sandctl init'
-to configure your new provider (Hetzner Cloud).
+Sprites has been replaced with pluggable VM providers. Run
+'sandctl config migrate' to configure your new provider (Hetzner Cloud).
 
 Your existing opencode_zen_key will be preserved.
 `
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -50,6 +52,22 @@ opencode_zen_key: "zen-key-456"
 	}
 }
 
+// TestLoadReader_GivenYAML_ThenParsesAndValidates tests reading config from stdin.
+func TestLoadReader_GivenYAML_ThenParsesAndValidates(t *testing.T) {
+	cfg, err := LoadReader(strings.NewReader("sprites_token: \"test-token-123\"\nopencode_zen_key: \"zen-key-456\"\n"))
+	if err != nil {
+		t.Fatalf("LoadReader() error = %v", err)
+	}
+	if cfg.SpritesToken != "test-token-123" {
+		t.Errorf("SpritesToken = %q, want %q", cfg.SpritesToken, "test-token-123")
+	}
+
+	var unknown *UnknownFieldError
+	if _, err := LoadReader(strings.NewReader("sprites_tokn: x\n")); !errors.As(err, &unknown) {
+		t.Errorf("LoadReader() error = %v, want UnknownFieldError", err)
+	}
+}
+
 // TestLoad_GivenMissingFile_ThenReturnsNotFoundError tests missing file error.
 func TestLoad_GivenMissingFile_ThenReturnsNotFoundError(t *testing.T) {
 	_, err := Load("/nonexistent/path/config")
@@ -114,6 +132,69 @@ func TestLoad_GivenInvalidYAML_ThenReturnsParseError(t *testing.T) {
 	}
 }
 
+// TestLoad_GivenUnknownKey_ThenReturnsUnknownFieldError tests strict decoding.
+func TestLoad_GivenUnknownKey_ThenReturnsUnknownFieldError(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		wantField      string
+		wantLine       int
+		wantSuggestion string
+	}{
+		{
+			name: "misspelled top-level key",
+			content: `defalt_provider: hetzner
+providers:
+  hetzner:
+    token: test-token
+`,
+			wantField:      "defalt_provider",
+			wantLine:       1,
+			wantSuggestion: "default_provider",
+		},
+		{
+			name: "misspelled provider key",
+			content: `default_provider: hetzner
+providers:
+  hetzner:
+    token: test-token
+    servertype: cpx31
+`,
+			wantField:      "servertype",
+			wantLine:       5,
+			wantSuggestion: "server_type",
+		},
+		{
+			name: "unrelated key",
+			content: `default_provider: hetzner
+favorite_color: blue
+`,
+			wantField: "favorite_color",
+			wantLine:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			_, err := Load(configPath)
+
+			ufe, ok := err.(*UnknownFieldError)
+			if !ok {
+				t.Fatalf("expected UnknownFieldError, got %T: %v", err, err)
+			}
+			if ufe.Field != tt.wantField || ufe.Line != tt.wantLine || ufe.Suggestion != tt.wantSuggestion {
+				t.Errorf("UnknownFieldError = %+v, want field %q on line %d, suggestion %q",
+					ufe, tt.wantField, tt.wantLine, tt.wantSuggestion)
+			}
+		})
+	}
+}
+
 // TestLoad_GivenEmptyPath_ThenUsesDefaultPath tests empty path handling.
 func TestLoad_GivenEmptyPath_ThenUsesDefaultPath(t *testing.T) {
 	// This will fail since default path likely doesn't exist, but verifies the code path
@@ -196,6 +277,33 @@ func TestValidate_GivenValidConfig_ThenReturnsNoError(t *testing.T) {
 	}
 }
 
+// TestValidate_GivenInvalidDefaultTimeout_ThenReturnsValidationError tests default_timeout validation.
+func TestValidate_GivenInvalidDefaultTimeout_ThenReturnsValidationError(t *testing.T) {
+	cfg := &Config{
+		DefaultProvider:    "hetzner",
+		SSHKeySource:       "agent",
+		SSHPublicKeyInline: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIExample",
+		SSHKeyFingerprint:  "SHA256:example",
+		Providers:          map[string]ProviderConfig{"hetzner": {Token: "token"}},
+		DefaultTimeout:     "overnight",
+	}
+
+	err := cfg.Validate()
+
+	valErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %T: %v", err, err)
+	}
+	if valErr.Field != "default_timeout" {
+		t.Errorf("Field = %q, want %q", valErr.Field, "default_timeout")
+	}
+
+	cfg.DefaultTimeout = "8h"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with valid default_timeout error = %v", err)
+	}
+}
+
 // TestNotFoundError_Error_GivenPath_ThenReturnsFormattedMessage tests error message.
 func TestNotFoundError_Error_GivenPath_ThenReturnsFormattedMessage(t *testing.T) {
 	err := &NotFoundError{Path: "/some/path/config"}
@@ -270,3 +378,219 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// TestResolveToken_GivenTokenForms_ThenResolvesReferences tests plain, env: and cmd: tokens.
+func TestResolveToken_GivenTokenForms_ThenResolvesReferences(t *testing.T) {
+	t.Setenv("SANDCTL_TEST_TOKEN", "from-env")
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"plain-token", "plain-token"},
+		{"env:SANDCTL_TEST_TOKEN", "from-env"},
+		{"cmd:echo from-cmd", "from-cmd"},
+		{"cmd:printf '  padded\n\n'", "padded"},
+	}
+
+	for _, tt := range tests {
+		pc := &ProviderConfig{Token: tt.token}
+		got, err := pc.ResolveToken()
+		if err != nil {
+			t.Errorf("ResolveToken(%q) error = %v", tt.token, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
+
+// TestResolveToken_GivenFailingReference_ThenReturnsError tests resolution failures.
+func TestResolveToken_GivenFailingReference_ThenReturnsError(t *testing.T) {
+	os.Unsetenv("SANDCTL_TEST_UNSET_TOKEN")
+
+	for _, token := range []string{
+		"env:SANDCTL_TEST_UNSET_TOKEN",
+		"cmd:echo denied >&2; exit 1",
+		"cmd:true",
+	} {
+		pc := &ProviderConfig{Token: token}
+		if _, err := pc.ResolveToken(); err == nil {
+			t.Errorf("ResolveToken(%q) expected error", token)
+		}
+	}
+
+	pc := &ProviderConfig{Token: "cmd:echo denied >&2; exit 1"}
+	if _, err := pc.ResolveToken(); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("ResolveToken() error = %v, want command stderr included", err)
+	}
+}
+
+// TestProviderConfigSetField_GivenKeys_ThenCoercesValues tests setting fields by key.
+func TestProviderConfigSetField_GivenKeys_ThenCoercesValues(t *testing.T) {
+	var pc ProviderConfig
+
+	if err := pc.SetField("network", "internal"); err != nil {
+		t.Fatalf("SetField(network) error = %v", err)
+	}
+	if err := pc.SetField("ssh_key_id", "1234"); err != nil {
+		t.Fatalf("SetField(ssh_key_id) error = %v", err)
+	}
+	if pc.Network != "internal" || pc.SSHKeyID != 1234 {
+		t.Errorf("ProviderConfig = %+v", pc)
+	}
+
+	err := pc.SetField("regoin", "hel1")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "region"?`) {
+		t.Errorf("SetField(regoin) error = %v, want a suggestion", err)
+	}
+	if err := pc.SetField("ssh_key_id", "twelve"); err == nil {
+		t.Error("SetField(ssh_key_id) should reject a non-integer")
+	}
+}
+
+// TestGetProviderConfig_GivenTopLevelDefaults_ThenProviderValuesTakePrecedence tests the fallback order.
+func TestGetProviderConfig_GivenTopLevelDefaults_ThenProviderValuesTakePrecedence(t *testing.T) {
+	cfg := &Config{
+		SSHUser: "ubuntu",
+		Image:   "debian-12",
+		Providers: map[string]ProviderConfig{
+			"hetzner": {Token: "token", Image: "ubuntu-24.04"},
+		},
+	}
+
+	pc, ok := cfg.GetProviderConfig("hetzner")
+	if !ok {
+		t.Fatal("GetProviderConfig() found no hetzner config")
+	}
+	if pc.Image != "ubuntu-24.04" {
+		t.Errorf("Image = %q, want provider value %q", pc.Image, "ubuntu-24.04")
+	}
+	if pc.SSHUser != "ubuntu" {
+		t.Errorf("SSHUser = %q, want top-level value %q", pc.SSHUser, "ubuntu")
+	}
+
+	// The stored provider config is unchanged, so saving doesn't copy defaults
+	if stored := cfg.Providers["hetzner"]; stored.SSHUser != "" {
+		t.Errorf("stored SSHUser = %q, want empty", stored.SSHUser)
+	}
+}
+
+// profileTestConfig returns a valid agent-mode config with a work profile.
+func profileTestConfig() *Config {
+	return &Config{
+		DefaultProvider:    "hetzner",
+		SSHKeySource:       "agent",
+		SSHPublicKeyInline: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIExample",
+		SSHKeyFingerprint:  "SHA256:example",
+		Image:              "ubuntu-24.04",
+		Providers:          map[string]ProviderConfig{"hetzner": {Token: "personal"}},
+		Profiles: map[string]Profile{
+			"work": {
+				Providers:      map[string]ProviderConfig{"hetzner": {Token: "work", Region: "fsn1"}},
+				DefaultTimeout: "8h",
+			},
+		},
+	}
+}
+
+// TestWithProfile_GivenProfile_ThenOverlaysTopLevelSettings tests profile selection.
+func TestWithProfile_GivenProfile_ThenOverlaysTopLevelSettings(t *testing.T) {
+	cfg := profileTestConfig()
+
+	work, err := cfg.WithProfile("work")
+	if err != nil {
+		t.Fatalf("WithProfile(work) error = %v", err)
+	}
+	if work.ActiveProfile != "work" || work.DefaultProvider != "hetzner" || work.DefaultTimeout != "8h" {
+		t.Errorf("WithProfile(work) = %+v", work)
+	}
+	pc, ok := work.GetProviderConfig("hetzner")
+	if !ok || pc.Token != "work" || pc.Region != "fsn1" || pc.Image != "ubuntu-24.04" {
+		t.Errorf("work hetzner config = %+v, want the profile's token with the top-level image", pc)
+	}
+	if cfg.Providers["hetzner"].Token != "personal" {
+		t.Error("WithProfile() modified the original config")
+	}
+
+	cfg.ActiveProfile = "work"
+	def, err := cfg.WithProfile(DefaultProfile)
+	if err != nil {
+		t.Fatalf("WithProfile(default) error = %v", err)
+	}
+	if def.ActiveProfile != "" || def.Providers["hetzner"].Token != "personal" {
+		t.Errorf("WithProfile(default) = %+v, want the top-level settings", def)
+	}
+
+	if _, err := cfg.WithProfile("nope"); err == nil {
+		t.Error("WithProfile(nope) should fail")
+	}
+}
+
+// TestValidate_GivenInvalidProfiles_ThenReturnsValidationError tests profile validation.
+func TestValidate_GivenInvalidProfiles_ThenReturnsValidationError(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(*Config)
+		wantField string
+	}{
+		{"unknown active profile", func(c *Config) { c.ActiveProfile = "home" }, "active_profile"},
+		{"missing token", func(c *Config) {
+			c.Profiles["work"] = Profile{Providers: map[string]ProviderConfig{"hetzner": {}}}
+		}, "profiles.work.providers.hetzner.token"},
+		{"unconfigured default provider", func(c *Config) {
+			c.Profiles["work"] = Profile{DefaultProvider: "aws"}
+		}, "profiles.work.default_provider"},
+		{"reserved name", func(c *Config) { c.Profiles[DefaultProfile] = Profile{} }, "profiles.default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := profileTestConfig()
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate() of valid profiles error = %v", err)
+			}
+
+			tt.modify(cfg)
+			var ve *ValidationError
+			if err := cfg.Validate(); !errors.As(err, &ve) || ve.Field != tt.wantField {
+				t.Errorf("Validate() error = %v, want field %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+// TestTemplate_GivenTemplate_ThenLoadsAsValidConfig tests the init --print template.
+func TestTemplate_GivenTemplate_ThenLoadsAsValidConfig(t *testing.T) {
+	// The template refers to ~/.ssh/id_ed25519.pub, which must exist
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatalf("failed to create .ssh: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "id_ed25519.pub"), []byte("ssh-ed25519 AAAA test"), 0600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(Template()), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.DefaultProvider != "hetzner" {
+		t.Errorf("DefaultProvider = %q, want %q", cfg.DefaultProvider, "hetzner")
+	}
+	pc, ok := cfg.GetProviderConfig("hetzner")
+	if !ok || pc.Token == "" || pc.Region == "" || pc.ServerType == "" {
+		t.Errorf("hetzner provider config = %+v, want token, region and server type", pc)
+	}
+	if cfg.SSHPublicKey == "" || !cfg.HasGitConfig() {
+		t.Error("template should include an SSH key and git identity")
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unknownFieldPattern matches the yaml.v3 error for a key with no matching
+// field, e.g. "line 2: field defalt_provider not found in type config.Config".
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+// unknownFieldError converts a strict decoding error about an unknown key
+// into an UnknownFieldError. Returns nil for other errors.
+func unknownFieldError(path string, err error) *UnknownFieldError {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	for _, msg := range typeErr.Errors {
+		m := unknownFieldPattern.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[1])
+
+		var known []string
+		switch m[3] {
+		case "config.ProviderConfig":
+			known = yamlKeys(reflect.TypeOf(ProviderConfig{}))
+		case "config.Profile":
+			known = yamlKeys(reflect.TypeOf(Profile{}))
+		default:
+			known = yamlKeys(reflect.TypeOf(Config{}))
+		}
+
+		return &UnknownFieldError{
+			Path:       path,
+			Line:       line,
+			Field:      m[2],
+			Suggestion: closestKey(m[2], known),
+		}
+	}
+	return nil
+}
+
+// SetField sets the provider setting with the given YAML key, converting
+// value to the field's type. The token can't be set this way, so it doesn't
+// end up in shell history.
+func (p *ProviderConfig) SetField(key, value string) error {
+	if key == "token" {
+		return fmt.Errorf("token can't be overridden; use an env: or cmd: reference in the config file")
+	}
+
+	v := reflect.ValueOf(p).Elem()
+	field, ok := fieldByYAMLKey(v, key)
+	if !ok {
+		var known []string
+		for _, k := range yamlKeys(v.Type()) {
+			if k != "token" {
+				known = append(known, k)
+			}
+		}
+		if suggestion := closestKey(key, known); suggestion != "" {
+			return fmt.Errorf("unknown provider setting %q (did you mean %q?)", key, suggestion)
+		}
+		return fmt.Errorf("unknown provider setting %q (valid: %s)", key, strings.Join(known, ", "))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: must be an integer", value, key)
+		}
+		field.SetInt(n)
+	default:
+		return fmt.Errorf("provider setting %s can't be set from the command line", key)
+	}
+	return nil
+}
+
+// fieldByYAMLKey returns the field of struct value v with the given YAML key.
+func fieldByYAMLKey(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+		if name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// yamlKeys returns the YAML keys of a struct type's fields.
+func yamlKeys(t reflect.Type) []string {
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// closestKey returns the known key nearest to key by edit distance, or ""
+// if none is close enough to be a likely typo.
+func closestKey(key string, known []string) string {
+	best, bestDist := "", len(key)/3+2
+	for _, k := range known {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
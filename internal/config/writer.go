@@ -54,6 +54,12 @@ func Save(path string, cfg *Config) error {
 		return fmt.Errorf("failed to close encoder: %w", err)
 	}
 
+	// Flush to disk so a crash can't leave a renamed but empty file
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+
 	if err := tmp.Close(); err != nil {
 		return fmt.Errorf("failed to close temporary file: %w", err)
 	}
@@ -100,6 +100,45 @@ func TestSave_GivenExistingConfig_ThenUpdatesAtomically(t *testing.T) {
 	}
 }
 
+// TestSave_GivenInterruptedWrite_ThenOriginalUntouched tests that a partial temp
+// file left behind by a killed process doesn't affect the existing config.
+func TestSave_GivenInterruptedWrite_ThenOriginalUntouched(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+
+	cfg := &Config{SpritesToken: "original-token", OpencodeZenKey: "zen"}
+	if err := Save(configPath, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Simulate a write that was interrupted before the rename
+	partial := filepath.Join(tmpDir, ".config.tmp.partial")
+	if err := os.WriteFile(partial, []byte("sprites_tok"), 0600); err != nil {
+		t.Fatalf("failed to write partial file: %v", err)
+	}
+
+	loaded, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.SpritesToken != "original-token" {
+		t.Errorf("SpritesToken = %q, want %q", loaded.SpritesToken, "original-token")
+	}
+
+	// A later save still succeeds and leaves no new temp files behind
+	cfg.SpritesToken = "new-token"
+	if err := Save(configPath, cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected config and partial file only, got %d entries", len(entries))
+	}
+}
+
 // TestSave_GivenEmptyPath_ThenUsesDefaultPath tests default path behavior.
 func TestSave_GivenEmptyPath_ThenUsesDefaultPath(t *testing.T) {
 	// This test would modify the user's home directory, so we skip it
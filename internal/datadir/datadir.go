@@ -0,0 +1,42 @@
+// Package datadir resolves the directory sandctl keeps its files in: the
+// config, sessions, templates, caches, and known hosts.
+package datadir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// EnvVar is the environment variable that relocates the data directory.
+const EnvVar = "SANDCTL_DATA_DIR"
+
+// override is the directory given with --data-dir, if any.
+var override string
+
+// Set makes Dir return dir, taking precedence over EnvVar. An empty dir
+// clears the override.
+func Set(dir string) {
+	override = dir
+}
+
+// Dir returns the data directory. The precedence is the directory passed to
+// Set (the --data-dir flag), then $SANDCTL_DATA_DIR, then ~/.sandctl.
+func Dir() string {
+	if override != "" {
+		return override
+	}
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".sandctl"
+	}
+	return filepath.Join(home, ".sandctl")
+}
+
+// Path joins elem onto the data directory.
+func Path(elem ...string) string {
+	return filepath.Join(append([]string{Dir()}, elem...)...)
+}
@@ -0,0 +1,31 @@
+package datadir
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestDir_GivenFlagEnvAndHome_ThenAppliesPrecedence tests data directory resolution.
+func TestDir_GivenFlagEnvAndHome_ThenAppliesPrecedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvVar, "")
+	t.Cleanup(func() { Set("") })
+
+	if got, want := Dir(), filepath.Join(home, ".sandctl"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+
+	t.Setenv(EnvVar, "/srv/sandctl-env")
+	if got := Dir(); got != "/srv/sandctl-env" {
+		t.Errorf("Dir() with %s = %q, want %q", EnvVar, got, "/srv/sandctl-env")
+	}
+
+	Set("/srv/sandctl-flag")
+	if got := Dir(); got != "/srv/sandctl-flag" {
+		t.Errorf("Dir() with Set = %q, want %q", got, "/srv/sandctl-flag")
+	}
+	if got, want := Path("templates", "ghost"), "/srv/sandctl-flag/templates/ghost"; got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
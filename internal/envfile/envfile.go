@@ -0,0 +1,86 @@
+// Package envfile reads environment variables from .env-style files.
+package envfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sandctl/sandctl/internal/sshexec"
+)
+
+// Read parses the env file at path, expanding a leading ~ to the home
+// directory. Errors name the file and, for malformed lines, the line number.
+func Read(path string) (map[string]string, error) {
+	path = expandHome(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	defer f.Close()
+
+	env, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return env, nil
+}
+
+// Parse reads KEY=VALUE lines from r. Blank lines and lines starting with #
+// are skipped, an optional "export " prefix is allowed, and a value wrapped
+// in matching single or double quotes has them removed. Later lines override
+// earlier ones with the same key.
+func Parse(r io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		if !sshexec.ValidEnvName(key) {
+			return nil, fmt.Errorf("line %d: %q is not a valid variable name", lineNum, key)
+		}
+		env[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	return env, nil
+}
+
+// unquote removes matching single or double quotes around value.
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if first == last && (first == '"' || first == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// expandHome expands a leading ~/ in path to the user's home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
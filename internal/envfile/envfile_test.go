@@ -0,0 +1,80 @@
+package envfile
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParse_GivenEnvFile_ThenSkipsCommentsAndUnquotes tests env file parsing.
+func TestParse_GivenEnvFile_ThenSkipsCommentsAndUnquotes(t *testing.T) {
+	input := `# database
+DB_HOST=localhost
+
+export DB_USER = "admin"
+DB_PASS='it''s'
+EMPTY=
+URL=https://example.com/?a=b
+DB_HOST=db.internal
+`
+
+	env, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{
+		"DB_HOST": "db.internal",
+		"DB_USER": "admin",
+		"DB_PASS": "it''s",
+		"EMPTY":   "",
+		"URL":     "https://example.com/?a=b",
+	}
+	if !maps.Equal(env, want) {
+		t.Errorf("Parse() = %v, want %v", env, want)
+	}
+}
+
+// TestParse_GivenMalformedLine_ThenReportsLineNumber tests parse errors.
+func TestParse_GivenMalformedLine_ThenReportsLineNumber(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing equals", "A=1\n\nnot a pair\n", "line 3: expected KEY=VALUE"},
+		{"invalid name", "# comment\n1BAD=x\n", `line 2: "1BAD" is not a valid variable name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestRead_GivenHomePath_ThenExpandsTilde tests ~ expansion and missing files.
+func TestRead_GivenHomePath_ThenExpandsTilde(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".env"), []byte("TOKEN=abc\n"), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+
+	env, err := Read("~/.env")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if env["TOKEN"] != "abc" {
+		t.Errorf("Read() = %v, want TOKEN=abc", env)
+	}
+
+	if _, err := Read("~/missing.env"); err == nil {
+		t.Error("Read() of a missing file should fail")
+	}
+}
@@ -32,10 +32,10 @@ func (c *Client) HCloudClient() *hcloud.Client {
 
 // ValidateCredentials checks if the API token is valid.
 func (c *Client) ValidateCredentials(ctx context.Context) error {
-	// Try to list datacenters as a simple credential check
-	_, err := c.hc.Datacenter.All(ctx)
+	// List SSH keys as a simple, read-only credential check
+	_, err := c.hc.SSHKey.All(ctx)
 	if err != nil {
-		return fmt.Errorf("invalid Hetzner credentials: %w", err)
+		return fmt.Errorf("invalid Hetzner credentials: %w", apiError(err))
 	}
 	return nil
 }
@@ -0,0 +1,57 @@
+package hetzner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// apiError wraps an error from the Hetzner API with the matching provider
+// sentinel error, so callers can use errors.Is. The original error stays in
+// the chain for hcloud.IsError checks. Unrecognized errors are returned as is.
+func apiError(err error) error {
+	if sentinel := classifyError(err); sentinel != nil {
+		return fmt.Errorf("%w: %w", sentinel, err)
+	}
+	return err
+}
+
+// classifyError returns the provider sentinel error matching a Hetzner API
+// or action error, or nil if there is none.
+func classifyError(err error) error {
+	var actionErr hcloud.ActionError
+	if errors.As(err, &actionErr) {
+		return provider.ErrProvisionFailed
+	}
+
+	var apiErr hcloud.Error
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+
+	switch apiErr.Code {
+	case hcloud.ErrorCodeUnauthorized, hcloud.ErrorCodeForbidden:
+		return provider.ErrAuthFailed
+	case hcloud.ErrorCodeResourceLimitExceeded, hcloud.ErrorCodeRateLimitExceeded:
+		return provider.ErrQuotaExceeded
+	case hcloud.ErrorCodeNotFound:
+		return provider.ErrNotFound
+	}
+
+	// Fall back to the HTTP status for codes not listed above
+	if resp := apiErr.Response(); resp != nil && resp.Response != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return provider.ErrAuthFailed
+		case http.StatusTooManyRequests:
+			return provider.ErrQuotaExceeded
+		case http.StatusNotFound:
+			return provider.ErrNotFound
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// newTestProvider returns a provider that talks to a mock Hetzner API served by handler.
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	hc := hcloud.NewClient(
+		hcloud.WithToken("test-token"),
+		hcloud.WithEndpoint(srv.URL),
+		hcloud.WithRetryOpts(hcloud.RetryOpts{MaxRetries: 0}),
+		hcloud.WithPollOpts(hcloud.PollOpts{BackoffFunc: hcloud.ConstantBackoff(time.Millisecond)}),
+	)
+	cfg := &config.ProviderConfig{}
+	return &Provider{
+		client:  &Client{hc: hc, config: cfg},
+		config:  cfg,
+		backoff: provider.Backoff{Initial: time.Millisecond, Max: time.Millisecond},
+	}
+}
+
+// writeAPIError writes a Hetzner API error response.
+func writeAPIError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"error":{"code":%q,"message":"mock error"}}`, code)
+}
+
+// TestAPIError_GivenErrorResponses_ThenMapsToProviderErrors tests API error mapping.
+func TestAPIError_GivenErrorResponses_ThenMapsToProviderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		want   error
+	}{
+		{"unauthorized", http.StatusUnauthorized, "unauthorized", provider.ErrAuthFailed},
+		{"forbidden", http.StatusForbidden, "forbidden", provider.ErrAuthFailed},
+		{"rate limited", http.StatusTooManyRequests, "rate_limit_exceeded", provider.ErrQuotaExceeded},
+		{"resource limit", http.StatusForbidden, "resource_limit_exceeded", provider.ErrQuotaExceeded},
+		{"not found", http.StatusNotFound, "not_found", provider.ErrNotFound},
+		{"unknown code, 401", http.StatusUnauthorized, "token_expired", provider.ErrAuthFailed},
+		{"unknown code, 429", http.StatusTooManyRequests, "slow_down", provider.ErrQuotaExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				writeAPIError(w, tt.status, tt.code)
+			})
+
+			_, err := p.List(context.Background())
+			if !errors.Is(err, tt.want) {
+				t.Errorf("List() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestAPIError_GivenServerError_ThenLeavesErrorUnmapped tests unrelated errors.
+func TestAPIError_GivenServerError_ThenLeavesErrorUnmapped(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusInternalServerError, "service_error")
+	})
+
+	_, err := p.List(context.Background())
+	if err == nil {
+		t.Fatal("List() expected error")
+	}
+	for _, sentinel := range []error{provider.ErrAuthFailed, provider.ErrQuotaExceeded, provider.ErrNotFound, provider.ErrProvisionFailed} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("List() error = %v, should not match %v", err, sentinel)
+		}
+	}
+	if !hcloud.IsError(err, hcloud.ErrorCodeServiceError) {
+		t.Errorf("List() error = %v, should keep the hcloud error", err)
+	}
+}
+
+// TestCreate_GivenQuotaResponse_ThenReturnsQuotaExceeded tests server creation errors.
+func TestCreate_GivenQuotaResponse_ThenReturnsQuotaExceeded(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		want   error
+	}{
+		{"quota", http.StatusForbidden, "resource_limit_exceeded", provider.ErrQuotaExceeded},
+		{"other", http.StatusUnprocessableEntity, "invalid_input", provider.ErrProvisionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/ssh_keys/1":
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprint(w, `{"ssh_key":{"id":1,"name":"sandctl"}}`)
+				case "/servers":
+					writeAPIError(w, tt.status, tt.code)
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			_, err := p.Create(context.Background(), provider.CreateOpts{Name: "alice", SSHKeyID: "1"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestStop_GivenFailedAction_ThenReturnsProvisionFailed tests action error mapping.
+func TestStop_GivenFailedAction_ThenReturnsProvisionFailed(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/servers/1":
+			fmt.Fprint(w, `{"server":{"id":1,"name":"alice","status":"running"}}`)
+		case "/servers/1/actions/shutdown":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"action":{"id":7,"command":"shutdown_server","status":"running"}}`)
+		case "/actions":
+			fmt.Fprint(w, `{"actions":[{"id":7,"command":"shutdown_server","status":"error",`+
+				`"error":{"code":"action_failed","message":"shutdown failed"}}],`+
+				`"meta":{"pagination":{"page":1,"per_page":50,"total_entries":1}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	err := p.Stop(context.Background(), "1")
+	if !errors.Is(err, provider.ErrProvisionFailed) {
+		t.Errorf("Stop() error = %v, want %v", err, provider.ErrProvisionFailed)
+	}
+}
@@ -0,0 +1,115 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// anySource allows traffic from every IPv4 and IPv6 address.
+var anySource = []string{"0.0.0.0/0", "::/0"}
+
+// CreateFirewall creates a firewall that allows SSH on sshPort (22 if zero)
+// plus the given inbound TCP ports and applies it to the server. Returns the
+// Hetzner firewall ID.
+func (c *Client) CreateFirewall(ctx context.Context, name string, serverID int64, sshPort int, ports []int, sourceCIDRs []string) (string, error) {
+	rules, err := firewallRules(sshPort, ports, sourceCIDRs)
+	if err != nil {
+		return "", err
+	}
+
+	result, _, err := c.hc.Firewall.Create(ctx, hcloud.FirewallCreateOpts{
+		Name:   name,
+		Labels: map[string]string{"managed-by": "sandctl"},
+		Rules:  rules,
+		ApplyTo: []hcloud.FirewallResource{{
+			Type:   hcloud.FirewallResourceTypeServer,
+			Server: &hcloud.FirewallResourceServer{ID: serverID},
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create firewall: %w", apiError(err))
+	}
+
+	return fmt.Sprintf("%d", result.Firewall.ID), nil
+}
+
+// DeleteFirewall deletes a firewall by ID.
+// Returns an hcloud resource_in_use error while it is still applied to a server.
+func (c *Client) DeleteFirewall(ctx context.Context, id int64) error {
+	_, err := c.hc.Firewall.Delete(ctx, &hcloud.Firewall{ID: id})
+	if err != nil && !hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+		return err
+	}
+	return nil
+}
+
+// firewallRules builds the inbound rules for a session firewall. SSH on
+// sshPort (22 if zero) is always open so sandctl can reach the VM; other ports
+// are restricted to sourceCIDRs when given.
+func firewallRules(sshPort int, ports []int, sourceCIDRs []string) ([]hcloud.FirewallRule, error) {
+	if sshPort == 0 {
+		sshPort = 22
+	}
+
+	anyNets, err := parseCIDRs(anySource)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := anyNets
+	if len(sourceCIDRs) > 0 {
+		sources, err = parseCIDRs(sourceCIDRs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rules := []hcloud.FirewallRule{tcpRule(sshPort, anyNets, "SSH")}
+	for _, port := range ports {
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %d: must be between 1 and 65535", port)
+		}
+		if port == sshPort {
+			continue
+		}
+		rules = append(rules, tcpRule(port, sources, "sandctl --open-port"))
+	}
+	return rules, nil
+}
+
+// tcpRule returns an inbound TCP rule for a single port.
+func tcpRule(port int, sources []net.IPNet, description string) hcloud.FirewallRule {
+	return hcloud.FirewallRule{
+		Direction:   hcloud.FirewallRuleDirectionIn,
+		Protocol:    hcloud.FirewallRuleProtocolTCP,
+		Port:        hcloud.Ptr(strconv.Itoa(port)),
+		SourceIPs:   sources,
+		Description: hcloud.Ptr(description),
+	}
+}
+
+// parseCIDRs parses CIDR strings. Bare IP addresses are treated as single hosts.
+func parseCIDRs(values []string) ([]net.IPNet, error) {
+	nets := make([]net.IPNet, 0, len(values))
+	for _, v := range values {
+		if ip := net.ParseIP(v); ip != nil {
+			bits := 128
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			nets = append(nets, net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source address %q: %w", v, err)
+		}
+		nets = append(nets, *n)
+	}
+	return nets, nil
+}
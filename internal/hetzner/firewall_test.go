@@ -0,0 +1,78 @@
+package hetzner
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestFirewallRules_GivenPorts_ThenOpensSSHAndPorts tests rule generation.
+func TestFirewallRules_GivenPorts_ThenOpensSSHAndPorts(t *testing.T) {
+	rules, err := firewallRules(0, []int{3000, 22, 8080}, nil)
+	if err != nil {
+		t.Fatalf("firewallRules() error = %v", err)
+	}
+
+	var ports []string
+	for _, r := range rules {
+		ports = append(ports, *r.Port)
+		if len(r.SourceIPs) != 2 {
+			t.Errorf("port %s has %d sources, want any IPv4 and IPv6", *r.Port, len(r.SourceIPs))
+		}
+	}
+
+	want := []string{"22", "3000", "8080"}
+	if len(ports) != len(want) {
+		t.Fatalf("rule ports = %v, want %v", ports, want)
+	}
+	for i := range want {
+		if ports[i] != want[i] {
+			t.Errorf("rule ports = %v, want %v", ports, want)
+			break
+		}
+	}
+}
+
+// TestFirewallRules_GivenSSHPort_ThenOpensItInsteadOf22 tests sessions with a
+// custom SSH port.
+func TestFirewallRules_GivenSSHPort_ThenOpensItInsteadOf22(t *testing.T) {
+	rules, err := firewallRules(2222, []int{3000, 2222}, []string{"203.0.113.7"})
+	if err != nil {
+		t.Fatalf("firewallRules() error = %v", err)
+	}
+
+	var ports []string
+	for _, r := range rules {
+		ports = append(ports, *r.Port)
+	}
+	if want := []string{"2222", "3000"}; !slices.Equal(ports, want) {
+		t.Fatalf("rule ports = %v, want %v", ports, want)
+	}
+	if len(rules[0].SourceIPs) != 2 {
+		t.Errorf("SSH rule should stay open to any source, got %v", rules[0].SourceIPs)
+	}
+}
+
+// TestFirewallRules_GivenSourceIP_ThenRestrictsOpenedPorts tests source scoping.
+func TestFirewallRules_GivenSourceIP_ThenRestrictsOpenedPorts(t *testing.T) {
+	rules, err := firewallRules(0, []int{3000}, []string{"203.0.113.7"})
+	if err != nil {
+		t.Fatalf("firewallRules() error = %v", err)
+	}
+
+	if len(rules[0].SourceIPs) != 2 {
+		t.Errorf("SSH rule should stay open to any source, got %v", rules[0].SourceIPs)
+	}
+	if got := rules[1].SourceIPs; len(got) != 1 || got[0].String() != "203.0.113.7/32" {
+		t.Errorf("port 3000 sources = %v, want [203.0.113.7/32]", got)
+	}
+}
+
+// TestFirewallRules_GivenInvalidInput_ThenReturnsError tests validation.
+func TestFirewallRules_GivenInvalidInput_ThenReturnsError(t *testing.T) {
+	if _, err := firewallRules(0, []int{70000}, nil); err == nil {
+		t.Error("expected error for out of range port")
+	}
+	if _, err := firewallRules(0, []int{3000}, []string{"not-an-ip"}); err == nil {
+		t.Error("expected error for invalid source address")
+	}
+}
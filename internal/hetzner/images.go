@@ -0,0 +1,33 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// ListImages returns the available, non-deprecated system images.
+func (c *Client) ListImages(ctx context.Context) ([]provider.Image, error) {
+	images, err := c.hc.Image.AllWithOpts(ctx, hcloud.ImageListOpts{
+		Type:   []hcloud.ImageType{hcloud.ImageTypeSystem},
+		Status: []hcloud.ImageStatus{hcloud.ImageStatusAvailable},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", apiError(err))
+	}
+
+	result := make([]provider.Image, 0, len(images))
+	for _, img := range images {
+		result = append(result, provider.Image{
+			Name:         img.Name,
+			Description:  img.Description,
+			OSFlavor:     img.OSFlavor,
+			OSVersion:    img.OSVersion,
+			Architecture: string(img.Architecture),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// GetNetwork returns the private network with the given name or ID.
+func (c *Client) GetNetwork(ctx context.Context, idOrName string) (*hcloud.Network, error) {
+	network, _, err := c.hc.Network.Get(ctx, idOrName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network %s: %w", idOrName, apiError(err))
+	}
+	if network == nil {
+		return nil, fmt.Errorf("network %s: %w", idOrName, provider.ErrNotFound)
+	}
+	return network, nil
+}
+
+// EnsurePlacementGroup returns the placement group with the given name or ID,
+// creating a spread group with that name if none exists. IDs are never
+// created, since Hetzner assigns them.
+func (c *Client) EnsurePlacementGroup(ctx context.Context, idOrName string) (*hcloud.PlacementGroup, error) {
+	group, _, err := c.hc.PlacementGroup.Get(ctx, idOrName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placement group %s: %w", idOrName, apiError(err))
+	}
+	if group != nil {
+		return group, nil
+	}
+	if _, err := strconv.ParseInt(idOrName, 10, 64); err == nil {
+		return nil, fmt.Errorf("placement group %s: %w", idOrName, provider.ErrNotFound)
+	}
+
+	result, _, err := c.hc.PlacementGroup.Create(ctx, hcloud.PlacementGroupCreateOpts{
+		Name:   idOrName,
+		Labels: map[string]string{"managed-by": "sandctl"},
+		Type:   hcloud.PlacementGroupTypeSpread,
+	})
+	if err != nil {
+		// Handle race condition: a parallel create (e.g. new --count) may have
+		// made the group between lookup and create, so use that one instead.
+		if hcloud.IsError(err, hcloud.ErrorCodeUniquenessError) {
+			existing, _, lookupErr := c.hc.PlacementGroup.GetByName(ctx, idOrName)
+			if lookupErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
+		return nil, fmt.Errorf("failed to create placement group %s: %w", idOrName, apiError(err))
+	}
+	return result.PlacementGroup, nil
+}
+
+// DeletePlacementGroup deletes a placement group by ID.
+func (c *Client) DeletePlacementGroup(ctx context.Context, id int64) error {
+	_, err := c.hc.PlacementGroup.Delete(ctx, &hcloud.PlacementGroup{ID: id})
+	if err != nil && !hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+		return err
+	}
+	return nil
+}
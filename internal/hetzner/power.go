@@ -0,0 +1,63 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// How long to wait for a graceful shutdown before powering off
+const shutdownTimeout = 2 * time.Minute
+
+// Stop implements provider.PowerManager.
+// The server is asked to shut down gracefully and is powered off if it is
+// still running after shutdownTimeout.
+func (p *Provider) Stop(ctx context.Context, id string) error {
+	serverID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid server ID: %w", err)
+	}
+
+	hc := p.client.HCloudClient()
+
+	server, _, err := hc.Server.GetByID(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get server: %w", apiError(err))
+	}
+	if server == nil {
+		return provider.ErrNotFound
+	}
+	if server.Status == hcloud.ServerStatusOff {
+		return nil
+	}
+
+	if err := waitAction(ctx, hc, "shut down server", func() (*hcloud.Action, *hcloud.Response, error) {
+		return hc.Server.Shutdown(ctx, server)
+	}); err != nil {
+		return err
+	}
+
+	err = p.backoff.Poll(ctx, shutdownTimeout, func() (bool, error) {
+		server, _, err := hc.Server.GetByID(ctx, serverID)
+		if err != nil {
+			return false, err
+		}
+		return server == nil || server.Status == hcloud.ServerStatusOff, nil
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, provider.ErrTimeout) {
+		return fmt.Errorf("failed to wait for shutdown: %w", apiError(err))
+	}
+
+	return waitAction(ctx, hc, "power off server", func() (*hcloud.Action, *hcloud.Response, error) {
+		return hc.Server.Poweroff(ctx, server)
+	})
+}
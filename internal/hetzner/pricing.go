@@ -0,0 +1,44 @@
+package hetzner
+
+import "sort"
+
+// ServerTypePrice describes a Hetzner server type and its approximate cost.
+type ServerTypePrice struct {
+	Name     string
+	VCPUs    int
+	MemoryGB int
+	// HourlyEUR is the approximate hourly price in euros, excluding VAT.
+	HourlyEUR float64
+}
+
+// serverTypePrices is a static table of approximate prices for common server types.
+// Prices change over time; these are estimates for display only.
+var serverTypePrices = map[string]ServerTypePrice{
+	"cpx11": {Name: "cpx11", VCPUs: 2, MemoryGB: 2, HourlyEUR: 0.0075},
+	"cpx21": {Name: "cpx21", VCPUs: 3, MemoryGB: 4, HourlyEUR: 0.0125},
+	"cpx31": {Name: "cpx31", VCPUs: 4, MemoryGB: 8, HourlyEUR: 0.0225},
+	"cpx41": {Name: "cpx41", VCPUs: 8, MemoryGB: 16, HourlyEUR: 0.0425},
+	"cpx51": {Name: "cpx51", VCPUs: 16, MemoryGB: 32, HourlyEUR: 0.0725},
+}
+
+// EstimatedHourlyPrice returns the approximate hourly price in euros for a server type.
+// The second return value is false if the server type is not in the price table.
+func EstimatedHourlyPrice(serverType string) (float64, bool) {
+	p, ok := serverTypePrices[serverType]
+	if !ok {
+		return 0, false
+	}
+	return p.HourlyEUR, true
+}
+
+// ServerTypePrices returns the price table sorted by hourly price.
+func ServerTypePrices() []ServerTypePrice {
+	prices := make([]ServerTypePrice, 0, len(serverTypePrices))
+	for _, p := range serverTypePrices {
+		prices = append(prices, p)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].HourlyEUR < prices[j].HourlyEUR
+	})
+	return prices
+}
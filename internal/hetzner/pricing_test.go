@@ -0,0 +1,36 @@
+package hetzner
+
+import "testing"
+
+// TestEstimatedHourlyPrice_GivenKnownType_ThenReturnsPrice tests price lookup.
+func TestEstimatedHourlyPrice_GivenKnownType_ThenReturnsPrice(t *testing.T) {
+	price, ok := EstimatedHourlyPrice(DefaultServerType)
+	if !ok {
+		t.Fatalf("EstimatedHourlyPrice(%q) not found", DefaultServerType)
+	}
+	if price <= 0 {
+		t.Errorf("EstimatedHourlyPrice(%q) = %v, want positive price", DefaultServerType, price)
+	}
+}
+
+// TestEstimatedHourlyPrice_GivenUnknownType_ThenReturnsFalse tests unknown types.
+func TestEstimatedHourlyPrice_GivenUnknownType_ThenReturnsFalse(t *testing.T) {
+	if _, ok := EstimatedHourlyPrice("cx9000"); ok {
+		t.Error("expected unknown server type to return false")
+	}
+}
+
+// TestServerTypePrices_GivenTable_ThenSortedByPrice tests table ordering.
+func TestServerTypePrices_GivenTable_ThenSortedByPrice(t *testing.T) {
+	prices := ServerTypePrices()
+	if len(prices) == 0 {
+		t.Fatal("expected non-empty price table")
+	}
+
+	for i := 1; i < len(prices); i++ {
+		if prices[i].HourlyEUR < prices[i-1].HourlyEUR {
+			t.Errorf("prices not sorted: %s (%v) after %s (%v)",
+				prices[i].Name, prices[i].HourlyEUR, prices[i-1].Name, prices[i-1].HourlyEUR)
+		}
+	}
+}
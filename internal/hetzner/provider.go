@@ -1,9 +1,12 @@
 package hetzner
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"strconv"
 	"time"
 
@@ -17,15 +20,31 @@ import (
 const (
 	providerName = "hetzner"
 
-	// Polling intervals for WaitReady
-	pollInterval    = 5 * time.Second
+	// SSH port check timeout for WaitReady
 	sshCheckTimeout = 5 * time.Second
+
+	// How long WaitReady waits when its context has no deadline
+	defaultReadyTimeout = 5 * time.Minute
+
+	// How long to retry deleting a volume that is still being detached
+	volumeDeleteTimeout = 2 * time.Minute
+
+	// How long to retry deleting a firewall that is still applied to a server
+	firewallDeleteTimeout = 1 * time.Minute
+
+	// How long Resize tries to power a server back on after a failed resize
+	resizePowerOnTimeout = 2 * time.Minute
+
+	// How long Delete waits for a deleted server to leave its placement group
+	// before giving up on removing the group
+	placementGroupDeleteTimeout = 15 * time.Second
 )
 
 // Provider implements the provider.Provider interface for Hetzner Cloud.
 type Provider struct {
-	client *Client
-	config *config.ProviderConfig
+	client  *Client
+	config  *config.ProviderConfig
+	backoff provider.Backoff
 }
 
 // NewProvider creates a new Hetzner provider from configuration.
@@ -35,9 +54,17 @@ func NewProvider(cfg *config.Config) (provider.Provider, error) {
 		return nil, fmt.Errorf("hetzner provider not configured")
 	}
 
+	// provCfg is a copy, so the resolved token never reaches the saved config
+	token, err := provCfg.ResolveToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve hetzner token: %w", err)
+	}
+	provCfg.Token = token
+
 	return &Provider{
-		client: NewClient(provCfg),
-		config: provCfg,
+		client:  NewClient(provCfg),
+		config:  provCfg,
+		backoff: provider.DefaultBackoff(),
 	}, nil
 }
 
@@ -49,20 +76,10 @@ func (p *Provider) Name() string {
 // Create provisions a new VM with the given options.
 func (p *Provider) Create(ctx context.Context, opts provider.CreateOpts) (*provider.VM, error) {
 	// Determine region, server type, and image
+	opts = p.ResolveCreateOpts(opts)
 	region := opts.Region
-	if region == "" {
-		region = p.client.GetDefaultRegion()
-	}
-
 	serverType := opts.ServerType
-	if serverType == "" {
-		serverType = p.client.GetDefaultServerType()
-	}
-
 	image := opts.Image
-	if image == "" {
-		image = p.client.GetDefaultImage()
-	}
 
 	// Get SSH key
 	sshKeyID, err := strconv.ParseInt(opts.SSHKeyID, 10, 64)
@@ -72,7 +89,7 @@ func (p *Provider) Create(ctx context.Context, opts provider.CreateOpts) (*provi
 
 	sshKey, err := p.client.GetSSHKeyByID(ctx, sshKeyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get SSH key: %w", err)
+		return nil, fmt.Errorf("failed to get SSH key: %w", apiError(err))
 	}
 
 	// Use cloud-init script
@@ -81,6 +98,13 @@ func (p *Provider) Create(ctx context.Context, opts provider.CreateOpts) (*provi
 		userData = CloudInitScript()
 	}
 
+	// User labels first so the managed-by label can't be overridden
+	labels := make(map[string]string, len(opts.Labels)+1)
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+	labels["managed-by"] = "sandctl"
+
 	// Create server options
 	createOpts := hcloud.ServerCreateOpts{
 		Name:       opts.Name,
@@ -89,40 +113,60 @@ func (p *Provider) Create(ctx context.Context, opts provider.CreateOpts) (*provi
 		Location:   &hcloud.Location{Name: region},
 		SSHKeys:    []*hcloud.SSHKey{sshKey},
 		UserData:   userData,
-		Labels: map[string]string{
-			"managed-by": "sandctl",
-		},
+		Labels:     labels,
+	}
+
+	if p.config.Network != "" {
+		network, err := p.client.GetNetwork(ctx, p.config.Network)
+		if err != nil {
+			return nil, err
+		}
+		createOpts.Networks = []*hcloud.Network{network}
+	}
+	if name := cmp.Or(opts.PlacementGroup, p.config.PlacementGroup); name != "" {
+		group, err := p.client.EnsurePlacementGroup(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		createOpts.PlacementGroup = group
 	}
 
 	// Create server
 	result, _, err := p.client.HCloudClient().Server.Create(ctx, createOpts)
 	if err != nil {
-		if isQuotaError(err) {
-			return nil, fmt.Errorf("%w: %v", provider.ErrQuotaExceeded, err)
+		if sentinel := classifyError(err); sentinel != nil {
+			return nil, fmt.Errorf("%w: %w", sentinel, err)
 		}
-		if isAuthError(err) {
-			return nil, fmt.Errorf("%w: %v", provider.ErrAuthFailed, err)
-		}
-		return nil, fmt.Errorf("%w: %v", provider.ErrProvisionFailed, err)
+		return nil, fmt.Errorf("%w: %w", provider.ErrProvisionFailed, err)
 	}
 
-	server := result.Server
-
-	// Get IP address
-	ipAddress := ""
-	if server.PublicNet.IPv4.IP != nil {
-		ipAddress = server.PublicNet.IPv4.IP.String()
+	vm := serverToVM(result.Server)
+	// The create response may not include expanded location and type details
+	if vm.Region == "" {
+		vm.Region = region
+	}
+	if vm.ServerType == "" {
+		vm.ServerType = serverType
+	}
+	if vm.Image == "" {
+		vm.Image = image
 	}
 
-	return &provider.VM{
-		ID:         fmt.Sprintf("%d", server.ID),
-		Name:       server.Name,
-		Status:     mapServerStatus(server.Status),
-		IPAddress:  ipAddress,
-		CreatedAt:  server.Created,
-		Region:     region,
-		ServerType: serverType,
-	}, nil
+	return vm, nil
+}
+
+// ResolveCreateOpts implements provider.DefaultsResolver.
+func (p *Provider) ResolveCreateOpts(opts provider.CreateOpts) provider.CreateOpts {
+	if opts.Region == "" {
+		opts.Region = p.client.GetDefaultRegion()
+	}
+	if opts.ServerType == "" {
+		opts.ServerType = p.client.GetDefaultServerType()
+	}
+	if opts.Image == "" {
+		opts.Image = p.client.GetDefaultImage()
+	}
+	return opts
 }
 
 // Get retrieves a VM by its provider-specific ID.
@@ -134,27 +178,14 @@ func (p *Provider) Get(ctx context.Context, id string) (*provider.VM, error) {
 
 	server, _, err := p.client.HCloudClient().Server.GetByID(ctx, serverID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get server: %w", err)
+		return nil, fmt.Errorf("failed to get server: %w", apiError(err))
 	}
 
 	if server == nil {
 		return nil, provider.ErrNotFound
 	}
 
-	ipAddress := ""
-	if server.PublicNet.IPv4.IP != nil {
-		ipAddress = server.PublicNet.IPv4.IP.String()
-	}
-
-	return &provider.VM{
-		ID:         fmt.Sprintf("%d", server.ID),
-		Name:       server.Name,
-		Status:     mapServerStatus(server.Status),
-		IPAddress:  ipAddress,
-		CreatedAt:  server.Created,
-		Region:     server.Location.Name,
-		ServerType: server.ServerType.Name,
-	}, nil
+	return serverToVM(server), nil
 }
 
 // Delete terminates and removes a VM.
@@ -166,7 +197,7 @@ func (p *Provider) Delete(ctx context.Context, id string) error {
 
 	server, _, err := p.client.HCloudClient().Server.GetByID(ctx, serverID)
 	if err != nil {
-		return fmt.Errorf("failed to get server: %w", err)
+		return fmt.Errorf("failed to get server: %w", apiError(err))
 	}
 
 	if server == nil {
@@ -176,12 +207,53 @@ func (p *Provider) Delete(ctx context.Context, id string) error {
 
 	_, _, err = p.client.HCloudClient().Server.DeleteWithResult(ctx, server)
 	if err != nil {
-		return fmt.Errorf("failed to delete server: %w", err)
+		return fmt.Errorf("failed to delete server: %w", apiError(err))
+	}
+
+	if server.PlacementGroup != nil {
+		// Best effort: a leftover group costs nothing and doesn't affect the VM
+		if err := p.deletePlacementGroupIfUnused(ctx, server.PlacementGroup.ID, serverID); err != nil {
+			slog.Warn("failed to delete placement group", "placement_group", server.PlacementGroup.ID, "error", err)
+		}
 	}
 
 	return nil
 }
 
+// deletePlacementGroupIfUnused deletes a placement group created by sandctl
+// once the deleted server has left it and no other server uses it. Groups
+// that sandctl didn't create are never deleted.
+func (p *Provider) deletePlacementGroupIfUnused(ctx context.Context, groupID, deletedServerID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, placementGroupDeleteTimeout)
+	defer cancel()
+
+	return p.backoff.Poll(ctx, placementGroupDeleteTimeout, func() (bool, error) {
+		group, _, err := p.client.HCloudClient().PlacementGroup.GetByID(ctx, groupID)
+		if err != nil {
+			return false, err
+		}
+		if group == nil || group.Labels["managed-by"] != "sandctl" {
+			return true, nil
+		}
+
+		for _, id := range group.Servers {
+			if id != deletedServerID {
+				// Still in use by another server
+				return true, nil
+			}
+		}
+		if len(group.Servers) > 0 {
+			// The deleted server hasn't left the group yet
+			return false, nil
+		}
+
+		if err := p.client.DeletePlacementGroup(ctx, groupID); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
 // List returns all VMs managed by this provider.
 func (p *Provider) List(ctx context.Context) ([]*provider.VM, error) {
 	opts := hcloud.ServerListOpts{
@@ -192,73 +264,98 @@ func (p *Provider) List(ctx context.Context) ([]*provider.VM, error) {
 
 	servers, err := p.client.HCloudClient().Server.AllWithOpts(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list servers: %w", err)
+		return nil, fmt.Errorf("failed to list servers: %w", apiError(err))
 	}
 
 	vms := make([]*provider.VM, 0, len(servers))
 	for _, server := range servers {
-		ipAddress := ""
-		if server.PublicNet.IPv4.IP != nil {
-			ipAddress = server.PublicNet.IPv4.IP.String()
-		}
-
-		vms = append(vms, &provider.VM{
-			ID:         fmt.Sprintf("%d", server.ID),
-			Name:       server.Name,
-			Status:     mapServerStatus(server.Status),
-			IPAddress:  ipAddress,
-			CreatedAt:  server.Created,
-			Region:     server.Location.Name,
-			ServerType: server.ServerType.Name,
-		})
+		vms = append(vms, serverToVM(server))
 	}
 
 	return vms, nil
 }
 
 // WaitReady blocks until the VM is ready for SSH access.
-func (p *Provider) WaitReady(ctx context.Context, id string, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
-
-	for {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+// The VM is polled with exponential backoff until ctx's deadline, or
+// defaultReadyTimeout if it has none. Canceling ctx stops the wait.
+func (p *Provider) WaitReady(ctx context.Context, id string, opts ...provider.WaitOption) error {
+	wait := provider.NewWaitOptions(opts)
+	var lastState provider.ReadyState
+
+	sshPort := wait.SSHPort
+	if sshPort == 0 {
+		sshPort = 22
+	}
 
-		// Check timeout
-		if time.Now().After(deadline) {
-			return provider.ErrTimeout
-		}
+	timeout := defaultReadyTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
 
+	err := p.backoff.Poll(ctx, timeout, func() (bool, error) {
 		// Get current VM state
 		vm, err := p.Get(ctx, id)
 		if err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
 			if errors.Is(err, provider.ErrNotFound) {
-				return provider.ErrProvisionFailed
+				return false, provider.ErrProvisionFailed
+			}
+			if errors.Is(err, provider.ErrAuthFailed) {
+				return false, err
 			}
 			// Transient error, retry
-			time.Sleep(pollInterval)
-			continue
+			return false, nil
 		}
 
 		// Check for failed state
 		if vm.Status == provider.StatusFailed {
-			return provider.ErrProvisionFailed
+			return false, provider.ErrProvisionFailed
+		}
+
+		if state, ok := readyState(vm.Status); ok && state != lastState {
+			lastState = state
+			wait.Report(state)
 		}
 
 		// Check if running and SSH is available
-		if vm.Status == provider.StatusRunning && vm.IPAddress != "" {
-			// Try SSH connection
-			if sshexec.CheckConnection(vm.IPAddress, 22, sshCheckTimeout) {
-				return nil
-			}
+		addr := vm.IPAddress
+		if vm.IPv6 != "" && (wait.PreferIPv6 || addr == "") {
+			addr = vm.IPv6
+		}
+		if vm.Status == provider.StatusRunning && addr != "" {
+			return sshexec.CheckConnection(addr, sshPort, sshCheckTimeout), nil
 		}
 
-		time.Sleep(pollInterval)
+		return false, nil
+	})
+
+	// Reaching ctx's deadline is a timeout like any other
+	if errors.Is(err, context.DeadlineExceeded) {
+		return provider.ErrTimeout
 	}
+	return err
+}
+
+// readyState maps a VM status seen while waiting to the state reported to
+// WaitReady's progress callback.
+func readyState(status provider.VMStatus) (provider.ReadyState, bool) {
+	switch status {
+	case provider.StatusProvisioning:
+		return provider.ReadyInitializing, true
+	case provider.StatusStarting:
+		return provider.ReadyStarting, true
+	case provider.StatusRunning:
+		return provider.ReadyRunning, true
+	default:
+		return "", false
+	}
+}
+
+// VerifyCredentials checks that the configured API token is valid.
+func (p *Provider) VerifyCredentials(ctx context.Context) error {
+	return p.client.ValidateCredentials(ctx)
 }
 
 // EnsureSSHKey implements provider.SSHKeyManager.
@@ -266,6 +363,155 @@ func (p *Provider) EnsureSSHKey(ctx context.Context, name, publicKey string) (st
 	return p.client.EnsureSSHKey(ctx, name, publicKey)
 }
 
+// ListKeys implements provider.SSHKeyManager.
+func (p *Provider) ListKeys(ctx context.Context) ([]provider.SSHKey, error) {
+	return p.client.ListSSHKeys(ctx)
+}
+
+// DeleteKey implements provider.SSHKeyManager.
+func (p *Provider) DeleteKey(ctx context.Context, keyID string) error {
+	return p.client.DeleteSSHKey(ctx, keyID)
+}
+
+// ListImages implements provider.ImageLister.
+func (p *Provider) ListImages(ctx context.Context) ([]provider.Image, error) {
+	return p.client.ListImages(ctx)
+}
+
+// ListRegions implements provider.RegionLister.
+func (p *Provider) ListRegions(ctx context.Context) ([]provider.Region, error) {
+	return p.client.ListRegions(ctx)
+}
+
+// CreateFirewall implements provider.FirewallManager.
+func (p *Provider) CreateFirewall(ctx context.Context, vmID string, sshPort int, ports []int, sourceCIDRs []string) (string, error) {
+	serverID, err := strconv.ParseInt(vmID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid server ID: %w", err)
+	}
+	return p.client.CreateFirewall(ctx, fmt.Sprintf("sandctl-%s", vmID), serverID, sshPort, ports, sourceCIDRs)
+}
+
+// DeleteFirewall implements provider.FirewallManager.
+// Hetzner refuses to delete a firewall that is still applied, which briefly
+// remains the case after its server is deleted, so in-use errors are retried.
+func (p *Provider) DeleteFirewall(ctx context.Context, id string) error {
+	firewallID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid firewall ID: %w", err)
+	}
+
+	var lastErr error
+	err = p.backoff.Poll(ctx, firewallDeleteTimeout, func() (bool, error) {
+		lastErr = p.client.DeleteFirewall(ctx, firewallID)
+		if lastErr == nil {
+			return true, nil
+		}
+		if hcloud.IsError(lastErr, hcloud.ErrorCodeResourceInUse) {
+			return false, nil
+		}
+		return false, lastErr
+	})
+	if errors.Is(err, provider.ErrTimeout) && lastErr != nil {
+		return fmt.Errorf("failed to delete firewall: %w", apiError(lastErr))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete firewall: %w", apiError(err))
+	}
+	return nil
+}
+
+// CreateVolume implements provider.VolumeManager.
+func (p *Provider) CreateVolume(ctx context.Context, vmID string, sizeGB int) (string, error) {
+	serverID, err := strconv.ParseInt(vmID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid server ID: %w", err)
+	}
+	return p.client.CreateVolume(ctx, fmt.Sprintf("sandctl-%s", vmID), serverID, sizeGB)
+}
+
+// AttachVolume implements provider.VolumeManager.
+func (p *Provider) AttachVolume(ctx context.Context, vmID, volumeID string) (int, error) {
+	serverID, err := strconv.ParseInt(vmID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid server ID: %w", err)
+	}
+	id, err := strconv.ParseInt(volumeID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid volume ID: %w", err)
+	}
+	return p.client.AttachVolume(ctx, id, serverID)
+}
+
+// DeleteVolume implements provider.VolumeManager.
+// A volume stays locked while Hetzner detaches it from a deleted server, so
+// locked errors are retried.
+func (p *Provider) DeleteVolume(ctx context.Context, id string) error {
+	volumeID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid volume ID: %w", err)
+	}
+
+	var lastErr error
+	err = p.backoff.Poll(ctx, volumeDeleteTimeout, func() (bool, error) {
+		lastErr = p.client.DeleteVolume(ctx, volumeID)
+		if lastErr == nil {
+			return true, nil
+		}
+		if hcloud.IsError(lastErr, hcloud.ErrorCodeLocked) || hcloud.IsError(lastErr, hcloud.ErrorCodeResourceInUse) {
+			return false, nil
+		}
+		return false, lastErr
+	})
+	if errors.Is(err, provider.ErrTimeout) && lastErr != nil {
+		return fmt.Errorf("failed to delete volume: %w", apiError(lastErr))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete volume: %w", apiError(err))
+	}
+	return nil
+}
+
+// serverToVM converts an hcloud server to a provider-agnostic VM.
+func serverToVM(server *hcloud.Server) *provider.VM {
+	vm := &provider.VM{
+		ID:        fmt.Sprintf("%d", server.ID),
+		Name:      server.Name,
+		Status:    mapServerStatus(server.Status),
+		CreatedAt: server.Created,
+	}
+
+	if server.PublicNet.IPv4.IP != nil {
+		vm.IPAddress = server.PublicNet.IPv4.IP.String()
+	}
+	if network := server.PublicNet.IPv6.IP; network != nil {
+		vm.IPv6 = primaryIPv6(network)
+	}
+	if server.Location != nil {
+		vm.Region = server.Location.Name
+	}
+	if server.Datacenter != nil {
+		vm.Datacenter = server.Datacenter.Name
+	}
+	if server.ServerType != nil {
+		vm.ServerType = server.ServerType.Name
+	}
+	if server.Image != nil {
+		vm.Image = server.Image.Name
+	}
+
+	return vm
+}
+
+// primaryIPv6 returns the address Hetzner assigns to the server's primary
+// interface, which is the first host (::1) in its /64 network.
+func primaryIPv6(network net.IP) string {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, network.To16())
+	ip[net.IPv6len-1] = 1
+	return ip.String()
+}
+
 // mapServerStatus converts Hetzner server status to provider.VMStatus.
 func mapServerStatus(status hcloud.ServerStatus) provider.VMStatus {
 	switch status {
@@ -286,24 +532,6 @@ func mapServerStatus(status hcloud.ServerStatus) provider.VMStatus {
 	}
 }
 
-// isQuotaError checks if the error is a quota exceeded error.
-func isQuotaError(err error) bool {
-	var hcloudErr hcloud.Error
-	if errors.As(err, &hcloudErr) {
-		return hcloudErr.Code == hcloud.ErrorCodeResourceLimitExceeded
-	}
-	return false
-}
-
-// isAuthError checks if the error is an authentication error.
-func isAuthError(err error) bool {
-	var hcloudErr hcloud.Error
-	if errors.As(err, &hcloudErr) {
-		return hcloudErr.Code == hcloud.ErrorCodeUnauthorized
-	}
-	return false
-}
-
 // init registers the Hetzner provider.
 func init() {
 	provider.Register(providerName, NewProvider)
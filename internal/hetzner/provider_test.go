@@ -0,0 +1,491 @@
+package hetzner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/config"
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// TestServerToVM_GivenFullServer_ThenMapsAllFields tests the server mapping.
+func TestServerToVM_GivenFullServer_ThenMapsAllFields(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := &hcloud.Server{
+		ID:      42,
+		Name:    "alice",
+		Status:  hcloud.ServerStatusRunning,
+		Created: created,
+		PublicNet: hcloud.ServerPublicNet{
+			IPv4: hcloud.ServerPublicNetIPv4{IP: net.ParseIP("203.0.113.10")},
+			IPv6: hcloud.ServerPublicNetIPv6{IP: net.ParseIP("2001:db8:1234::")},
+		},
+		Location:   &hcloud.Location{Name: "ash"},
+		Datacenter: &hcloud.Datacenter{Name: "ash-dc1"},
+		ServerType: &hcloud.ServerType{Name: "cpx31"},
+		Image:      &hcloud.Image{Name: "ubuntu-24.04"},
+	}
+
+	vm := serverToVM(server)
+
+	want := provider.VM{
+		ID:         "42",
+		Name:       "alice",
+		Status:     provider.StatusRunning,
+		IPAddress:  "203.0.113.10",
+		IPv6:       "2001:db8:1234::1",
+		CreatedAt:  created,
+		Region:     "ash",
+		Datacenter: "ash-dc1",
+		ServerType: "cpx31",
+		Image:      "ubuntu-24.04",
+	}
+	if *vm != want {
+		t.Errorf("serverToVM() = %+v, want %+v", *vm, want)
+	}
+}
+
+// TestServerToVM_GivenMinimalServer_ThenLeavesOptionalFieldsEmpty tests nil handling.
+func TestServerToVM_GivenMinimalServer_ThenLeavesOptionalFieldsEmpty(t *testing.T) {
+	vm := serverToVM(&hcloud.Server{ID: 7, Name: "bob", Status: hcloud.ServerStatusInitializing})
+
+	if vm.IPAddress != "" || vm.IPv6 != "" || vm.Region != "" || vm.Datacenter != "" || vm.ServerType != "" || vm.Image != "" {
+		t.Errorf("serverToVM() = %+v, want optional fields empty", *vm)
+	}
+}
+
+// TestResolveCreateOpts_GivenPartialOpts_ThenFillsDefaults tests default resolution.
+func TestResolveCreateOpts_GivenPartialOpts_ThenFillsDefaults(t *testing.T) {
+	provCfg := &config.ProviderConfig{Token: "token", ServerType: "cpx41"}
+	p := &Provider{client: NewClient(provCfg), config: provCfg}
+
+	opts := p.ResolveCreateOpts(provider.CreateOpts{Name: "alice", Image: "debian-12"})
+
+	if opts.Region != DefaultRegion {
+		t.Errorf("Region = %q, want default %q", opts.Region, DefaultRegion)
+	}
+	if opts.ServerType != "cpx41" {
+		t.Errorf("ServerType = %q, want configured %q", opts.ServerType, "cpx41")
+	}
+	if opts.Image != "debian-12" {
+		t.Errorf("Image = %q, want explicit %q", opts.Image, "debian-12")
+	}
+}
+
+// TestIsDowngrade_GivenServerTypes_ThenDetectsSmallerTarget tests downgrade detection.
+func TestIsDowngrade_GivenServerTypes_ThenDetectsSmallerTarget(t *testing.T) {
+	current := &hcloud.ServerType{Cores: 4, Memory: 8, Disk: 160}
+
+	tests := []struct {
+		name   string
+		target *hcloud.ServerType
+		want   bool
+	}{
+		{"larger", &hcloud.ServerType{Cores: 8, Memory: 16, Disk: 240}, false},
+		{"same", &hcloud.ServerType{Cores: 4, Memory: 8, Disk: 160}, false},
+		{"smaller disk", &hcloud.ServerType{Cores: 8, Memory: 16, Disk: 80}, true},
+		{"fewer cores", &hcloud.ServerType{Cores: 2, Memory: 8, Disk: 160}, true},
+		{"less memory", &hcloud.ServerType{Cores: 4, Memory: 4, Disk: 160}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDowngrade(current, tt.target); got != tt.want {
+				t.Errorf("isDowngrade() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCloudInitScriptWithVolume_GivenMountPoint_ThenMountsBeforeCompletion tests the volume mount step.
+func TestCloudInitScriptWithVolume_GivenMountPoint_ThenMountsBeforeCompletion(t *testing.T) {
+	script := CloudInitScriptWithVolume("/data")
+
+	mount := strings.Index(script, "mount /data")
+	done := strings.Index(script, "# Signal completion")
+	if mount < 0 {
+		t.Fatalf("script does not mount /data:\n%s", script)
+	}
+	if done < mount {
+		t.Error("volume must be mounted before setup signals completion")
+	}
+	if !strings.HasPrefix(script, cloudInitSetup) {
+		t.Error("script should start with the standard setup")
+	}
+	if strings.Contains(CloudInitScript(), "/data") {
+		t.Error("standard script should not mount a volume")
+	}
+}
+
+// TestResize_GivenChangeTypeFails_ThenPowersServerBackOn tests that a failed
+// resize doesn't leave the server powered off.
+func TestResize_GivenChangeTypeFails_ThenPowersServerBackOn(t *testing.T) {
+	var calls []string
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/servers/1":
+			fmt.Fprint(w, `{"server":{"id":1,"name":"alice","status":"running","server_type":{"id":1,"name":"cpx21","cores":3,"memory":4,"disk":80}}}`)
+		case "/server_types":
+			fmt.Fprint(w, `{"server_types":[{"id":2,"name":"cpx31","cores":4,"memory":8,"disk":160}],"meta":{"pagination":{"page":1,"per_page":25,"total_entries":1}}}`)
+		case "/servers/1/actions/poweroff", "/servers/1/actions/poweron":
+			calls = append(calls, r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"action":{"id":7,"command":"power","status":"success"}}`)
+		case "/servers/1/actions/change_type":
+			calls = append(calls, r.URL.Path)
+			writeAPIError(w, http.StatusConflict, "conflict")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	err := p.Resize(context.Background(), "1", "cpx31", provider.ResizeOpts{})
+	if err == nil || !strings.Contains(err.Error(), "change server type") || !strings.Contains(err.Error(), "powered back on") {
+		t.Errorf("Resize() error = %v, want the change type failure and the recovered power state", err)
+	}
+
+	want := []string{"/servers/1/actions/poweroff", "/servers/1/actions/change_type", "/servers/1/actions/poweron"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("actions = %v, want %v", calls, want)
+	}
+}
+
+// TestWaitReady_GivenProgress_ThenReportsEachStateOnce tests the progress callback.
+func TestWaitReady_GivenProgress_ThenReportsEachStateOnce(t *testing.T) {
+	statuses := []string{"initializing", "initializing", "starting", "running"}
+	calls := 0
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls >= len(statuses) {
+			writeAPIError(w, http.StatusNotFound, "not_found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"server":{"id":1,"name":"alice","status":%q}}`, statuses[calls])
+		calls++
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var states []provider.ReadyState
+	err := p.WaitReady(ctx, "1", provider.WithProgress(func(state provider.ReadyState) {
+		states = append(states, state)
+	}))
+
+	// The mock deletes the server once it is running, ending the wait
+	if !errors.Is(err, provider.ErrProvisionFailed) {
+		t.Fatalf("WaitReady() error = %v, want %v", err, provider.ErrProvisionFailed)
+	}
+	want := []provider.ReadyState{provider.ReadyInitializing, provider.ReadyStarting, provider.ReadyRunning}
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("reported states %v, want %v", states, want)
+	}
+}
+
+// TestWaitReady_GivenContextDeadlineOrCancel_ThenStopsWaiting tests that the wait is bounded by ctx.
+func TestWaitReady_GivenContextDeadlineOrCancel_ThenStopsWaiting(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"server":{"id":1,"name":"alice","status":"initializing"}}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.WaitReady(ctx, "1"); !errors.Is(err, provider.ErrTimeout) {
+		t.Errorf("WaitReady() past the deadline error = %v, want %v", err, provider.ErrTimeout)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := p.WaitReady(ctx, "1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitReady() after cancel error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// TestCreate_GivenMissingPlacementGroup_ThenCreatesSpreadGroup tests placement group creation.
+func TestCreate_GivenMissingPlacementGroup_ThenCreatesSpreadGroup(t *testing.T) {
+	var groupBody, serverBody map[string]any
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/ssh_keys/1":
+			fmt.Fprint(w, `{"ssh_key":{"id":1,"name":"sandctl"}}`)
+		case r.URL.Path == "/placement_groups" && r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"placement_groups":[],"meta":{"pagination":{"page":1,"per_page":25,"total_entries":0}}}`)
+		case r.URL.Path == "/placement_groups" && r.Method == http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&groupBody)
+			fmt.Fprint(w, `{"placement_group":{"id":7,"name":"workers","type":"spread"}}`)
+		case r.URL.Path == "/servers":
+			_ = json.NewDecoder(r.Body).Decode(&serverBody)
+			fmt.Fprint(w, `{"server":{"id":42,"name":"alice","status":"initializing"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	opts := provider.CreateOpts{Name: "alice", SSHKeyID: "1", PlacementGroup: "workers"}
+	if _, err := p.Create(context.Background(), opts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if groupBody["name"] != "workers" || groupBody["type"] != "spread" {
+		t.Errorf("placement group request = %v, want spread group named workers", groupBody)
+	}
+	if labels, _ := groupBody["labels"].(map[string]any); labels["managed-by"] != "sandctl" {
+		t.Errorf("placement group labels = %v, want managed-by=sandctl", groupBody["labels"])
+	}
+	if serverBody["placement_group"] != float64(7) {
+		t.Errorf("server placement_group = %v, want 7", serverBody["placement_group"])
+	}
+}
+
+// TestDelete_GivenManagedPlacementGroup_ThenDeletesGroupOnceEmpty tests placement group cleanup.
+func TestDelete_GivenManagedPlacementGroup_ThenDeletesGroupOnceEmpty(t *testing.T) {
+	tests := []struct {
+		name       string
+		labels     string
+		servers    string
+		wantDelete bool
+	}{
+		{"managed and empty", `{"managed-by":"sandctl"}`, `[]`, true},
+		{"managed and in use", `{"managed-by":"sandctl"}`, `[43]`, false},
+		{"not managed", `{}`, `[]`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var groupGets int
+			var deleted bool
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/servers/42" && r.Method == http.MethodGet:
+					fmt.Fprint(w, `{"server":{"id":42,"name":"alice","status":"running","placement_group":{"id":7,"name":"workers","type":"spread","servers":[42]}}}`)
+				case r.URL.Path == "/servers/42" && r.Method == http.MethodDelete:
+					fmt.Fprint(w, `{"action":{"id":1,"command":"delete_server","status":"success"}}`)
+				case r.URL.Path == "/placement_groups/7" && r.Method == http.MethodGet:
+					// The deleted server is still listed on the first lookup
+					servers := tt.servers
+					if groupGets++; groupGets == 1 {
+						servers = `[42]`
+					}
+					fmt.Fprintf(w, `{"placement_group":{"id":7,"name":"workers","type":"spread","labels":%s,"servers":%s}}`, tt.labels, servers)
+				case r.URL.Path == "/placement_groups/7" && r.Method == http.MethodDelete:
+					deleted = true
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			if err := p.Delete(context.Background(), "42"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if deleted != tt.wantDelete {
+				t.Errorf("placement group deleted = %v, want %v", deleted, tt.wantDelete)
+			}
+		})
+	}
+}
+
+// fakeClock advances instantly when waited on.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// TestDelete_GivenServerStaysInPlacementGroup_ThenGivesUpAfterTimeout tests
+// that the placement group cleanup stops polling once its timeout has passed.
+func TestDelete_GivenServerStaysInPlacementGroup_ThenGivesUpAfterTimeout(t *testing.T) {
+	var groupGets int
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/servers/42" && r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"server":{"id":42,"name":"alice","status":"running","placement_group":{"id":7,"name":"workers","type":"spread","servers":[42]}}}`)
+		case r.URL.Path == "/servers/42" && r.Method == http.MethodDelete:
+			fmt.Fprint(w, `{"action":{"id":1,"command":"delete_server","status":"success"}}`)
+		case r.URL.Path == "/placement_groups/7" && r.Method == http.MethodGet:
+			groupGets++
+			fmt.Fprint(w, `{"placement_group":{"id":7,"name":"workers","type":"spread","labels":{"managed-by":"sandctl"},"servers":[42]}}`)
+		case r.URL.Path == "/placement_groups/7" && r.Method == http.MethodDelete:
+			t.Error("placement group deleted while the server is still in it")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	p.backoff = provider.Backoff{Initial: time.Second, Max: 10 * time.Second, Clock: &fakeClock{now: time.Unix(0, 0)}}
+
+	if err := p.Delete(context.Background(), "42"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// Polls at 0, 1, 3, 7 and 15s
+	if groupGets != 5 {
+		t.Errorf("placement group lookups = %d, want 5", groupGets)
+	}
+}
+
+// TestDelete_GivenPlacementGroupCleanupFails_ThenLogsAndSucceeds tests that a
+// placement group that can't be removed doesn't fail the delete but is logged.
+func TestDelete_GivenPlacementGroupCleanupFails_ThenLogsAndSucceeds(t *testing.T) {
+	var logs bytes.Buffer
+	oldLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(oldLogger) })
+
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/servers/42" && r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"server":{"id":42,"name":"alice","status":"running","placement_group":{"id":7,"name":"workers","type":"spread","servers":[42]}}}`)
+		case r.URL.Path == "/servers/42" && r.Method == http.MethodDelete:
+			fmt.Fprint(w, `{"action":{"id":1,"command":"delete_server","status":"success"}}`)
+		case r.URL.Path == "/placement_groups/7":
+			writeAPIError(w, http.StatusForbidden, "forbidden")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	if err := p.Delete(context.Background(), "42"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "failed to delete placement group") || !strings.Contains(logs.String(), "placement_group=7") {
+		t.Errorf("logs = %q, want a warning naming placement group 7", logs.String())
+	}
+}
+
+// TestEnsurePlacementGroup_GivenParallelCreate_ThenUsesExistingGroup tests
+// that losing the create race to another worker reuses the winner's group.
+func TestEnsurePlacementGroup_GivenParallelCreate_ThenUsesExistingGroup(t *testing.T) {
+	var lookups int
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/placement_groups" && r.Method == http.MethodGet:
+			lookups++
+			if lookups == 1 {
+				fmt.Fprint(w, `{"placement_groups":[],"meta":{"pagination":{"page":1,"per_page":25,"total_entries":0}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"placement_groups":[{"id":7,"name":"workers","type":"spread"}],"meta":{"pagination":{"page":1,"per_page":25,"total_entries":1}}}`)
+		case r.URL.Path == "/placement_groups" && r.Method == http.MethodPost:
+			writeAPIError(w, http.StatusConflict, "uniqueness_error")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	group, err := p.client.EnsurePlacementGroup(context.Background(), "workers")
+	if err != nil {
+		t.Fatalf("EnsurePlacementGroup() error = %v", err)
+	}
+	if group.ID != 7 {
+		t.Errorf("group ID = %d, want 7", group.ID)
+	}
+}
+
+// TestAttachVolume_GivenDetachedVolume_ThenAttachesWithoutAutomount tests
+// reattaching a kept volume.
+func TestAttachVolume_GivenDetachedVolume_ThenAttachesWithoutAutomount(t *testing.T) {
+	var attachBody map[string]any
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/volumes/5":
+			fmt.Fprint(w, `{"volume":{"id":5,"name":"sandctl-1","size":50,"server":null}}`)
+		case r.URL.Path == "/volumes/5/actions/attach":
+			_ = json.NewDecoder(r.Body).Decode(&attachBody)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"action":{"id":7,"command":"attach_volume","status":"success"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	size, err := p.AttachVolume(context.Background(), "42", "5")
+	if err != nil {
+		t.Fatalf("AttachVolume() error = %v", err)
+	}
+	if size != 50 {
+		t.Errorf("size = %d, want 50", size)
+	}
+	if attachBody["server"] != float64(42) || attachBody["automount"] != false {
+		t.Errorf("attach request = %v, want server 42 without automount", attachBody)
+	}
+}
+
+// TestAttachVolume_GivenAttachedVolume_ThenReturnsError tests that a volume
+// in use by another server is not taken from it.
+func TestAttachVolume_GivenAttachedVolume_ThenReturnsError(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/volumes/5":
+			fmt.Fprint(w, `{"volume":{"id":5,"name":"sandctl-1","size":50,"server":9}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	if _, err := p.AttachVolume(context.Background(), "42", "5"); err == nil {
+		t.Error("expected error for a volume attached to another server")
+	}
+}
+
+// TestWaitReady_GivenPreferIPv6_ThenChecksSSHOverIPv6 tests that the
+// readiness check dials the address the session will be reached on.
+func TestWaitReady_GivenPreferIPv6_ThenChecksSSHOverIPv6(t *testing.T) {
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// 192.0.2.1 is reserved for documentation, so an IPv4 check never succeeds
+		fmt.Fprint(w, `{"server":{"id":1,"name":"alice","status":"running","public_net":{"ipv4":{"ip":"192.0.2.1"},"ipv6":{"ip":"::/64"}}}}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if err := p.WaitReady(ctx, "1", provider.WithSSHPort(port), provider.WithIPv6(true)); err != nil {
+		t.Errorf("WaitReady() error = %v", err)
+	}
+}
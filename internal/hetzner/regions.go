@@ -0,0 +1,61 @@
+package hetzner
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// ListRegions returns each location with the server types that can currently
+// be created in any of its datacenters.
+func (c *Client) ListRegions(ctx context.Context) ([]provider.Region, error) {
+	// Datacenters only reference server types by ID
+	serverTypes, err := c.hc.ServerType.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server types: %w", apiError(err))
+	}
+	typeNames := make(map[int64]string, len(serverTypes))
+	for _, st := range serverTypes {
+		typeNames[st.ID] = st.Name
+	}
+
+	datacenters, err := c.hc.Datacenter.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datacenters: %w", apiError(err))
+	}
+
+	var regions []provider.Region
+	index := make(map[string]int)
+	for _, dc := range datacenters {
+		if dc.Location == nil {
+			continue
+		}
+		i, ok := index[dc.Location.Name]
+		if !ok {
+			i = len(regions)
+			index[dc.Location.Name] = i
+			regions = append(regions, provider.Region{
+				Name:        dc.Location.Name,
+				Description: dc.Location.Description,
+				City:        dc.Location.City,
+				Country:     dc.Location.Country,
+			})
+		}
+		for _, st := range dc.ServerTypes.Available {
+			if name, ok := typeNames[st.ID]; ok && !regions[i].HasServerType(name) {
+				regions[i].ServerTypes = append(regions[i].ServerTypes, name)
+			}
+		}
+	}
+
+	for i := range regions {
+		slices.Sort(regions[i].ServerTypes)
+	}
+	slices.SortFunc(regions, func(a, b provider.Region) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return regions, nil
+}
@@ -0,0 +1,49 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestListRegions_GivenDatacenters_ThenGroupsAvailableTypesByLocation tests region listing.
+func TestListRegions_GivenDatacenters_ThenGroupsAvailableTypesByLocation(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/server_types":
+			fmt.Fprint(w, `{"server_types":[{"id":1,"name":"cpx11"},{"id":2,"name":"cpx31"},{"id":3,"name":"cpx41"}]}`)
+		case "/datacenters":
+			fmt.Fprint(w, `{"datacenters":[
+				{"id":1,"name":"nbg1-dc3","location":{"id":2,"name":"nbg1","description":"Nuremberg DC Park 1","city":"Nuremberg","country":"DE"},
+				 "server_types":{"supported":[1,2,3],"available":[2],"available_for_migration":[]}},
+				{"id":2,"name":"fsn1-dc14","location":{"id":1,"name":"fsn1","description":"Falkenstein DC Park 1","city":"Falkenstein","country":"DE"},
+				 "server_types":{"supported":[1,2,3],"available":[3,1],"available_for_migration":[]}},
+				{"id":3,"name":"nbg1-dc4","location":{"id":2,"name":"nbg1","description":"Nuremberg DC Park 1","city":"Nuremberg","country":"DE"},
+				 "server_types":{"supported":[1,2,3],"available":[1,2],"available_for_migration":[]}}
+			]}`)
+		default:
+			writeAPIError(w, http.StatusNotFound, "not_found")
+		}
+	})
+
+	regions, err := p.ListRegions(context.Background())
+	if err != nil {
+		t.Fatalf("ListRegions() error = %v", err)
+	}
+
+	if len(regions) != 2 {
+		t.Fatalf("got %d regions, want 2: %+v", len(regions), regions)
+	}
+	if regions[0].Name != "fsn1" || regions[0].City != "Falkenstein" || regions[0].Description != "Falkenstein DC Park 1" {
+		t.Errorf("regions[0] = %+v, want fsn1 in Falkenstein", regions[0])
+	}
+	if want := []string{"cpx11", "cpx41"}; !reflect.DeepEqual(regions[0].ServerTypes, want) {
+		t.Errorf("fsn1 server types = %v, want %v", regions[0].ServerTypes, want)
+	}
+	if want := []string{"cpx11", "cpx31"}; !reflect.DeepEqual(regions[1].ServerTypes, want) {
+		t.Errorf("nbg1 server types = %v, want %v", regions[1].ServerTypes, want)
+	}
+}
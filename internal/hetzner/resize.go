@@ -0,0 +1,110 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// Resize implements provider.Provider.
+// Hetzner can only change the type of a stopped server, so a running server
+// is powered off, rescaled, and powered back on. The disk is grown along with
+// an upgrade; it can't shrink, so a downgrade keeps the current disk.
+func (p *Provider) Resize(ctx context.Context, id, serverType string, opts provider.ResizeOpts) error {
+	serverID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid server ID: %w", err)
+	}
+
+	hc := p.client.HCloudClient()
+
+	server, _, err := hc.Server.GetByID(ctx, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get server: %w", apiError(err))
+	}
+	if server == nil {
+		return provider.ErrNotFound
+	}
+
+	target, _, err := hc.ServerType.GetByName(ctx, serverType)
+	if err != nil {
+		return fmt.Errorf("failed to get server type: %w", apiError(err))
+	}
+	if target == nil {
+		return fmt.Errorf("unknown server type: %s", serverType)
+	}
+
+	if server.ServerType != nil && server.ServerType.Name == target.Name {
+		return nil
+	}
+
+	downgrade := isDowngrade(server.ServerType, target)
+	if downgrade && !opts.AllowDowngrade {
+		return provider.ErrDowngrade
+	}
+
+	if server.Status != hcloud.ServerStatusOff {
+		if err := waitAction(ctx, hc, "power off server", func() (*hcloud.Action, *hcloud.Response, error) {
+			return hc.Server.Poweroff(ctx, server)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := waitAction(ctx, hc, "change server type", func() (*hcloud.Action, *hcloud.Response, error) {
+		return hc.Server.ChangeType(ctx, server, hcloud.ServerChangeTypeOpts{
+			ServerType:  target,
+			UpgradeDisk: !downgrade,
+		})
+	}); err != nil {
+		return powerOnAfterFailure(ctx, hc, server, err)
+	}
+
+	if err := waitAction(ctx, hc, "power on server", func() (*hcloud.Action, *hcloud.Response, error) {
+		return hc.Server.Poweron(ctx, server)
+	}); err != nil {
+		return fmt.Errorf("%w; the server was resized but may be left powered off", err)
+	}
+	return nil
+}
+
+// powerOnAfterFailure powers server back on, on a best-effort basis, after a
+// resize failed with err while the server was off. The returned error wraps
+// err and says if the server may still be off.
+func powerOnAfterFailure(ctx context.Context, hc *hcloud.Client, server *hcloud.Server, err error) error {
+	// Power on even if ctx was canceled, so the VM isn't left off
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resizePowerOnTimeout)
+	defer cancel()
+
+	if onErr := waitAction(ctx, hc, "power on server", func() (*hcloud.Action, *hcloud.Response, error) {
+		return hc.Server.Poweron(ctx, server)
+	}); onErr != nil {
+		return fmt.Errorf("%w; the server may be left powered off (%v)", err, onErr)
+	}
+	return fmt.Errorf("%w; the server was powered back on with its previous type", err)
+}
+
+// isDowngrade reports whether moving from current to target would need a
+// smaller disk, or reduce CPU or memory.
+func isDowngrade(current, target *hcloud.ServerType) bool {
+	if current == nil {
+		return false
+	}
+	return target.Disk < current.Disk || target.Cores < current.Cores || target.Memory < current.Memory
+}
+
+// waitAction starts an action and waits for it to finish.
+func waitAction(ctx context.Context, hc *hcloud.Client, desc string, start func() (*hcloud.Action, *hcloud.Response, error)) error {
+	action, _, err := start()
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", desc, apiError(err))
+	}
+	if err := hc.Action.WaitFor(ctx, action); err != nil {
+		return fmt.Errorf("failed to %s: %w", desc, apiError(err))
+	}
+	return nil
+}
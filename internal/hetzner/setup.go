@@ -1,5 +1,7 @@
 package hetzner
 
+import "fmt"
+
 // Default configuration values for Hetzner Cloud.
 const (
 	// DefaultRegion is Ashburn, Virginia (US East).
@@ -15,7 +17,19 @@ const (
 // CloudInitScript returns the cloud-init user-data script for VM setup.
 // This script runs during first boot to install Docker and development tools.
 func CloudInitScript() string {
-	return `#!/bin/bash
+	return cloudInitSetup + cloudInitDone
+}
+
+// CloudInitScriptWithVolume returns the cloud-init script with an extra step
+// that waits for the session's data volume to be attached and mounts it at
+// mountPoint. The volume is attached after the server is created, so the
+// step polls for the device for up to five minutes.
+func CloudInitScriptWithVolume(mountPoint string) string {
+	return cloudInitSetup + fmt.Sprintf(volumeMountScript, mountPoint) + cloudInitDone
+}
+
+// cloudInitSetup installs packages and creates the agent user.
+const cloudInitSetup = `#!/bin/bash
 set -e
 
 # Update package lists and install prerequisites
@@ -68,8 +82,28 @@ apt-get install -y gh
 apt-get autoremove -y
 apt-get clean
 
-# Signal completion
+`
+
+// volumeMountScript mounts the first Hetzner volume at the given path.
+const volumeMountScript = `# Mount the data volume once it is attached
+for i in $(seq 1 60); do
+  dev=$(ls /dev/disk/by-id/scsi-0HC_Volume_* 2>/dev/null | head -n 1)
+  [ -n "$dev" ] && break
+  sleep 5
+done
+if [ -n "$dev" ]; then
+  mkdir -p %[1]s
+  echo "$dev %[1]s ext4 discard,nofail,defaults 0 0" >> /etc/fstab
+  mount %[1]s
+  chown agent:agent %[1]s
+else
+  echo "sandctl: data volume not found" >> /var/log/cloud-init-output.log
+fi
+
+`
+
+// cloudInitDone signals that setup finished; it must run last.
+const cloudInitDone = `# Signal completion
 touch /var/lib/cloud/instance/boot-finished
 echo "sandctl setup complete" >> /var/log/cloud-init-output.log
 `
-}
@@ -6,9 +6,12 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
 )
 
 // EnsureSSHKey ensures the given public key exists in Hetzner.
@@ -25,7 +28,7 @@ func (c *Client) EnsureSSHKey(ctx context.Context, name, publicKey string) (stri
 	// Check if key already exists by fingerprint
 	existingKey, _, err := c.hc.SSHKey.GetByFingerprint(ctx, fingerprint)
 	if err != nil {
-		return "", fmt.Errorf("failed to check existing SSH keys: %w", err)
+		return "", fmt.Errorf("failed to check existing SSH keys: %w", apiError(err))
 	}
 
 	if existingKey != nil {
@@ -49,17 +52,48 @@ func (c *Client) EnsureSSHKey(ctx context.Context, name, publicKey string) (stri
 				return fmt.Sprintf("%d", existingKey.ID), nil
 			}
 		}
-		return "", fmt.Errorf("failed to create SSH key: %w", err)
+		return "", fmt.Errorf("failed to create SSH key: %w", apiError(err))
 	}
 
 	return fmt.Sprintf("%d", newKey.ID), nil
 }
 
+// ListSSHKeys returns all SSH keys in the project.
+func (c *Client) ListSSHKeys(ctx context.Context) ([]provider.SSHKey, error) {
+	keys, err := c.hc.SSHKey.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SSH keys: %w", apiError(err))
+	}
+
+	result := make([]provider.SSHKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, provider.SSHKey{
+			ID:        strconv.FormatInt(key.ID, 10),
+			Name:      key.Name,
+			PublicKey: key.PublicKey,
+		})
+	}
+	return result, nil
+}
+
+// DeleteSSHKey deletes an SSH key by its ID.
+func (c *Client) DeleteSSHKey(ctx context.Context, id string) error {
+	keyID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid SSH key ID: %s", id)
+	}
+
+	if _, err := c.hc.SSHKey.Delete(ctx, &hcloud.SSHKey{ID: keyID}); err != nil {
+		return fmt.Errorf("failed to delete SSH key: %w", apiError(err))
+	}
+	return nil
+}
+
 // GetSSHKeyByID retrieves an SSH key by its ID.
 func (c *Client) GetSSHKeyByID(ctx context.Context, id int64) (*hcloud.SSHKey, error) {
 	key, _, err := c.hc.SSHKey.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get SSH key: %w", err)
+		return nil, fmt.Errorf("failed to get SSH key: %w", apiError(err))
 	}
 	return key, nil
 }
@@ -0,0 +1,100 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/sandctl/sandctl/internal/provider"
+)
+
+// Volume size limits enforced by Hetzner Cloud, in GB.
+const (
+	minVolumeSizeGB = 10
+	maxVolumeSizeGB = 10240
+)
+
+// CreateVolume creates an ext4-formatted volume attached to the server and
+// waits until it is attached. Returns the Hetzner volume ID.
+func (c *Client) CreateVolume(ctx context.Context, name string, serverID int64, sizeGB int) (string, error) {
+	if sizeGB < minVolumeSizeGB || sizeGB > maxVolumeSizeGB {
+		return "", fmt.Errorf("invalid volume size %d GB: must be between %d and %d", sizeGB, minVolumeSizeGB, maxVolumeSizeGB)
+	}
+
+	result, _, err := c.hc.Volume.Create(ctx, hcloud.VolumeCreateOpts{
+		Name:      name,
+		Size:      sizeGB,
+		Server:    &hcloud.Server{ID: serverID},
+		Labels:    map[string]string{"managed-by": "sandctl"},
+		Automount: hcloud.Ptr(false),
+		Format:    hcloud.Ptr(hcloud.VolumeFormatExt4),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create volume: %w", apiError(err))
+	}
+	volumeID := fmt.Sprintf("%d", result.Volume.ID)
+
+	actions := append([]*hcloud.Action{result.Action}, result.NextActions...)
+	for _, action := range actions {
+		if action == nil {
+			continue
+		}
+		if err := c.hc.Action.WaitFor(ctx, action); err != nil {
+			return volumeID, fmt.Errorf("failed to attach volume: %w", apiError(err))
+		}
+	}
+
+	return volumeID, nil
+}
+
+// AttachVolume attaches an existing, unattached volume to the server and
+// waits until it is attached. Returns the volume's size in GB.
+func (c *Client) AttachVolume(ctx context.Context, id, serverID int64) (int, error) {
+	volume, _, err := c.hc.Volume.GetByID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get volume %d: %w", id, apiError(err))
+	}
+	if volume == nil {
+		return 0, fmt.Errorf("volume %d: %w", id, provider.ErrNotFound)
+	}
+	if volume.Server != nil {
+		return 0, fmt.Errorf("volume %d is already attached to server %d", id, volume.Server.ID)
+	}
+
+	if err := waitAction(ctx, c.hc, "attach volume", func() (*hcloud.Action, *hcloud.Response, error) {
+		return c.hc.Volume.AttachWithOpts(ctx, volume, hcloud.VolumeAttachOpts{
+			Server:    &hcloud.Server{ID: serverID},
+			Automount: hcloud.Ptr(false),
+		})
+	}); err != nil {
+		return 0, err
+	}
+	return volume.Size, nil
+}
+
+// DeleteVolume detaches a volume if needed and deletes it.
+// Returns an hcloud locked error while the volume is still being detached.
+func (c *Client) DeleteVolume(ctx context.Context, id int64) error {
+	volume, _, err := c.hc.Volume.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if volume == nil {
+		return nil
+	}
+
+	if volume.Server != nil {
+		if err := waitAction(ctx, c.hc, "detach volume", func() (*hcloud.Action, *hcloud.Response, error) {
+			return c.hc.Volume.Detach(ctx, volume)
+		}); err != nil {
+			return err
+		}
+	}
+
+	_, err = c.hc.Volume.Delete(ctx, volume)
+	if err != nil && !hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+		return err
+	}
+	return nil
+}
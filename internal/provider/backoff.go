@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// Default polling schedule used while waiting for VMs.
+const (
+	DefaultPollInitial = 1 * time.Second
+	DefaultPollMax     = 10 * time.Second
+)
+
+// Backoff is an exponential polling schedule. The delay starts at Initial and
+// doubles after each attempt, up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+
+	// Clock is the time source for polling; nil means the system clock.
+	// Tests set it to simulate the passage of time.
+	Clock Clock
+}
+
+// Clock abstracts time so polling can be tested without real sleeps.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock uses the system time.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// DefaultBackoff returns the default polling schedule (1s doubling to 10s).
+func DefaultBackoff() Backoff {
+	return Backoff{Initial: DefaultPollInitial, Max: DefaultPollMax}
+}
+
+// Delay returns the wait before the next poll after the given zero-based attempt.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 0; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// Poll calls check until it reports done, returns an error, the timeout
+// expires (ErrTimeout), or ctx is canceled. Errors from check stop polling;
+// checks that should be retried must return false and a nil error.
+func (b Backoff) Poll(ctx context.Context, timeout time.Duration, check func() (bool, error)) error {
+	clk := b.Clock
+	if clk == nil {
+		clk = realClock{}
+	}
+
+	deadline := clk.Now().Add(timeout)
+	for attempt := 0; ; attempt++ {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		remaining := deadline.Sub(clk.Now())
+		if remaining <= 0 {
+			return ErrTimeout
+		}
+
+		delay := b.Delay(attempt)
+		if delay > remaining {
+			delay = remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clk.After(delay):
+		}
+	}
+}
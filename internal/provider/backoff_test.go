@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock advances instantly when waited on.
+type fakeClock struct {
+	now    time.Time
+	delays []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// TestBackoff_Delay_GivenAttempts_ThenDoublesUpToMax tests the schedule.
+func TestBackoff_Delay_GivenAttempts_ThenDoublesUpToMax(t *testing.T) {
+	b := DefaultBackoff()
+	expected := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+
+	for attempt, want := range expected {
+		if got := b.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+// TestBackoff_Poll_GivenNeverDone_ThenTimesOutAfterExpectedAttempts tests timeout handling.
+func TestBackoff_Poll_GivenNeverDone_ThenTimesOutAfterExpectedAttempts(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second, Clock: clk}
+
+	attempts := 0
+	err := b.Poll(context.Background(), 30*time.Second, func() (bool, error) {
+		attempts++
+		return false, nil
+	})
+
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Poll() error = %v, want ErrTimeout", err)
+	}
+
+	// Polls at 0, 1, 3, 7, 15, 25, 30s
+	if attempts != 7 {
+		t.Errorf("attempts = %d, want 7 (delays: %v)", attempts, clk.delays)
+	}
+}
+
+// TestBackoff_Poll_GivenDoneOnThirdAttempt_ThenReturnsNil tests early success.
+func TestBackoff_Poll_GivenDoneOnThirdAttempt_ThenReturnsNil(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second, Clock: clk}
+
+	attempts := 0
+	err := b.Poll(context.Background(), time.Minute, func() (bool, error) {
+		attempts++
+		return attempts == 3, nil
+	})
+
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+// TestBackoff_Poll_GivenCheckError_ThenStopsPolling tests terminal errors.
+func TestBackoff_Poll_GivenCheckError_ThenStopsPolling(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second, Clock: clk}
+
+	err := b.Poll(context.Background(), time.Minute, func() (bool, error) {
+		return false, ErrProvisionFailed
+	})
+
+	if !errors.Is(err, ErrProvisionFailed) {
+		t.Errorf("Poll() error = %v, want ErrProvisionFailed", err)
+	}
+	if len(clk.delays) != 0 {
+		t.Errorf("expected no waits, got %v", clk.delays)
+	}
+}
+
+// TestBackoff_Poll_GivenCanceledContext_ThenReturnsContextError tests cancellation.
+func TestBackoff_Poll_GivenCanceledContext_ThenReturnsContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := Backoff{Initial: time.Hour, Max: time.Hour}
+	err := b.Poll(ctx, 2*time.Hour, func() (bool, error) {
+		return false, nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Poll() error = %v, want context.Canceled", err)
+	}
+}
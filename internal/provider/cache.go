@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sandctl/sandctl/internal/datadir"
+)
+
+// DefaultVMCacheTTL is how long a cached VM listing is used before the
+// provider API is called again.
+const DefaultVMCacheTTL = 10 * time.Second
+
+// VMCache stores each provider's VM listing on disk for a short time, so
+// commands run in quick succession don't each call the provider API.
+type VMCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// vmCacheFile is the on-disk form of a cached listing.
+type vmCacheFile struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	VMs       []*VM     `json:"vms"`
+}
+
+// NewVMCache creates a cache that keeps listings in dir for ttl.
+func NewVMCache(dir string, ttl time.Duration) *VMCache {
+	return &VMCache{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// DefaultVMCacheDir returns the default cache directory (cache in the data
+// directory).
+func DefaultVMCacheDir() string {
+	return datadir.Path("cache")
+}
+
+// Get returns the cached VMs of the named provider. It returns false if
+// there is no listing or it is older than the TTL.
+func (c *VMCache) Get(name string) ([]*VM, bool) {
+	data, err := os.ReadFile(c.path(name))
+	if err != nil {
+		return nil, false
+	}
+
+	var cached vmCacheFile
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+
+	age := c.now().Sub(cached.FetchedAt)
+	if age < 0 || age >= c.ttl {
+		return nil, false
+	}
+	return cached.VMs, true
+}
+
+// Put stores vms as the named provider's current listing. The file is
+// replaced atomically so concurrent commands never read a partial listing.
+func (c *VMCache) Put(name string, vms []*VM) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(vmCacheFile{FetchedAt: c.now(), VMs: vms})
+	if err != nil {
+		return fmt.Errorf("failed to encode VM cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".vms.tmp.*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write VM cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, c.path(name)); err != nil {
+		return fmt.Errorf("failed to save VM cache: %w", err)
+	}
+	return nil
+}
+
+// Invalidate removes the named provider's cached listing, so the next
+// lookup calls the API.
+func (c *VMCache) Invalidate(name string) {
+	_ = os.Remove(c.path(name))
+}
+
+// path returns the cache file of the named provider.
+func (c *VMCache) path(name string) string {
+	return filepath.Join(c.dir, name+"-vms.json")
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestVMCache_GivenFreshListing_ThenReturnsIt tests reading back a listing within the TTL.
+func TestVMCache_GivenFreshListing_ThenReturnsIt(t *testing.T) {
+	cache := NewVMCache(t.TempDir(), 10*time.Second)
+
+	vms := []*VM{{ID: "1", Name: "alice", Status: StatusRunning, IPAddress: "192.0.2.1"}}
+	if err := cache.Put("hetzner", vms); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, ok := cache.Get("hetzner")
+	if !ok {
+		t.Fatal("Get() found no listing")
+	}
+	if len(got) != 1 || got[0].ID != "1" || got[0].Status != StatusRunning || got[0].IPAddress != "192.0.2.1" {
+		t.Errorf("Get() = %+v, want %+v", got, vms)
+	}
+
+	if _, ok := cache.Get("other"); ok {
+		t.Error("Get() should not return another provider's listing")
+	}
+}
+
+// TestVMCache_GivenExpiredListing_ThenMisses tests the TTL.
+func TestVMCache_GivenExpiredListing_ThenMisses(t *testing.T) {
+	cache := NewVMCache(t.TempDir(), 10*time.Second)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Put("hetzner", []*VM{{ID: "1"}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if _, ok := cache.Get("hetzner"); ok {
+		t.Error("Get() should miss once the TTL has passed")
+	}
+}
+
+// TestVMCache_GivenInvalidate_ThenRemovesListing tests invalidation.
+func TestVMCache_GivenInvalidate_ThenRemovesListing(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewVMCache(dir, 10*time.Second)
+
+	if err := cache.Put("hetzner", []*VM{{ID: "1"}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	cache.Invalidate("hetzner")
+
+	if _, ok := cache.Get("hetzner"); ok {
+		t.Error("Get() should miss after Invalidate")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hetzner-vms.json")); !os.IsNotExist(err) {
+		t.Errorf("cache file should be removed, stat error = %v", err)
+	}
+}
@@ -20,4 +20,8 @@ var (
 
 	// ErrTimeout indicates an operation exceeded its time limit.
 	ErrTimeout = errors.New("operation timed out")
+
+	// ErrDowngrade indicates a resize to a smaller server type was refused
+	// because ResizeOpts.AllowDowngrade was not set.
+	ErrDowngrade = errors.New("target server type is smaller than the current one")
 )
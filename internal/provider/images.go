@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsImageAlias reports whether name looks like a distribution alias such as
+// "ubuntu" rather than a versioned image name such as "ubuntu-24.04".
+func IsImageAlias(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "0123456789")
+}
+
+// ResolveImageAlias returns the name of the newest image whose OS flavor
+// matches alias. Returns false if no image matches.
+func ResolveImageAlias(images []Image, alias string) (string, bool) {
+	alias = strings.ToLower(alias)
+
+	var best *Image
+	for i := range images {
+		img := &images[i]
+		if strings.ToLower(img.OSFlavor) != alias {
+			continue
+		}
+		if best == nil || CompareOSVersions(img.OSVersion, best.OSVersion) > 0 {
+			best = img
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+	return best.Name, true
+}
+
+// CompareOSVersions compares dotted version strings numerically, returning
+// -1, 0, or 1. Non-numeric parts sort before numeric ones.
+func CompareOSVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		an, aErr := versionPart(as, i)
+		bn, bErr := versionPart(bs, i)
+		switch {
+		case aErr != nil && bErr != nil:
+			continue
+		case aErr != nil:
+			return -1
+		case bErr != nil:
+			return 1
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionPart returns the numeric value of parts[i], or 0 if i is out of range.
+func versionPart(parts []string, i int) (int, error) {
+	if i >= len(parts) {
+		return 0, nil
+	}
+	return strconv.Atoi(parts[i])
+}
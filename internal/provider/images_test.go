@@ -0,0 +1,73 @@
+package provider
+
+import "testing"
+
+// TestResolveImageAlias_GivenImages_ThenReturnsNewestMatch tests alias resolution.
+func TestResolveImageAlias_GivenImages_ThenReturnsNewestMatch(t *testing.T) {
+	images := []Image{
+		{Name: "ubuntu-22.04", OSFlavor: "ubuntu", OSVersion: "22.04"},
+		{Name: "ubuntu-24.04", OSFlavor: "ubuntu", OSVersion: "24.04"},
+		{Name: "ubuntu-20.04", OSFlavor: "ubuntu", OSVersion: "20.04"},
+		{Name: "debian-11", OSFlavor: "debian", OSVersion: "11"},
+		{Name: "debian-12", OSFlavor: "debian", OSVersion: "12"},
+		{Name: "fedora-9", OSFlavor: "fedora", OSVersion: "9"},
+		{Name: "fedora-10", OSFlavor: "fedora", OSVersion: "10"},
+	}
+
+	tests := []struct {
+		alias    string
+		expected string
+	}{
+		{"ubuntu", "ubuntu-24.04"},
+		{"Debian", "debian-12"},
+		{"fedora", "fedora-10"},
+	}
+
+	for _, tt := range tests {
+		got, ok := ResolveImageAlias(images, tt.alias)
+		if !ok || got != tt.expected {
+			t.Errorf("ResolveImageAlias(%q) = %q, %v; want %q", tt.alias, got, ok, tt.expected)
+		}
+	}
+
+	if _, ok := ResolveImageAlias(images, "arch"); ok {
+		t.Error("expected no match for unknown alias")
+	}
+}
+
+// TestIsImageAlias_GivenNames_ThenDetectsUnversionedNames tests alias detection.
+func TestIsImageAlias_GivenNames_ThenDetectsUnversionedNames(t *testing.T) {
+	tests := map[string]bool{
+		"ubuntu":       true,
+		"debian":       true,
+		"ubuntu-24.04": false,
+		"debian-12":    false,
+		"":             false,
+	}
+
+	for name, expected := range tests {
+		if got := IsImageAlias(name); got != expected {
+			t.Errorf("IsImageAlias(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
+
+// TestCompareOSVersions_GivenVersions_ThenComparesNumerically tests version ordering.
+func TestCompareOSVersions_GivenVersions_ThenComparesNumerically(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"24.04", "22.04", 1},
+		{"10", "9", 1},
+		{"12", "12", 0},
+		{"22.04", "22.04.1", -1},
+		{"unknown", "1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareOSVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("CompareOSVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
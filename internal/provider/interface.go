@@ -1,9 +1,6 @@
 package provider
 
-import (
-	"context"
-	"time"
-)
+import "context"
 
 // Provider defines the contract for VM providers.
 // Each provider implementation (Hetzner, AWS, GCP) must satisfy this interface.
@@ -29,10 +26,82 @@ type Provider interface {
 	// Used for syncing local session state with provider state.
 	List(ctx context.Context) ([]*VM, error)
 
-	// WaitReady blocks until the VM is ready for SSH access or timeout expires.
-	// Returns ErrTimeout if the VM is not ready within the timeout.
+	// WaitReady blocks until the VM is ready for SSH access. The wait is
+	// bounded by ctx's deadline, or a provider default if it has none.
+	// Returns ErrTimeout if the VM is not ready by the deadline, or ctx.Err()
+	// if ctx is canceled.
 	// Returns ErrProvisionFailed if the VM enters a failed state.
-	WaitReady(ctx context.Context, id string, timeout time.Duration) error
+	// Use WithProgress to be told about intermediate states.
+	WaitReady(ctx context.Context, id string, opts ...WaitOption) error
+
+	// VerifyCredentials checks that the configured credentials are accepted
+	// by the provider API using a cheap, read-only request.
+	VerifyCredentials(ctx context.Context) error
+
+	// Resize changes the VM to another server type. The VM may be restarted.
+	// Returns ErrNotFound if the VM does not exist, and ErrDowngrade if the
+	// target type is smaller and opts.AllowDowngrade is not set.
+	// The VM may still be booting when Resize returns; use WaitReady.
+	Resize(ctx context.Context, id, serverType string, opts ResizeOpts) error
+}
+
+// DefaultsResolver fills in the provider's defaults for unset creation options.
+// Providers implement it so callers can preview what Create would provision.
+type DefaultsResolver interface {
+	// ResolveCreateOpts returns opts with region, server type, and image set.
+	ResolveCreateOpts(opts CreateOpts) CreateOpts
+}
+
+// FirewallManager manages per-session firewalls for providers that support them.
+type FirewallManager interface {
+	// CreateFirewall creates a firewall that allows SSH on sshPort (22 if
+	// zero) and inbound TCP on ports, and applies it to the VM. If sourceCIDRs
+	// is non-empty, ports other than SSH are only reachable from those
+	// addresses.
+	// Returns the provider's firewall identifier.
+	CreateFirewall(ctx context.Context, vmID string, sshPort int, ports []int, sourceCIDRs []string) (firewallID string, err error)
+
+	// DeleteFirewall removes a firewall.
+	// Deleting an already-deleted firewall is not an error.
+	DeleteFirewall(ctx context.Context, firewallID string) error
+}
+
+// VolumeManager manages persistent block storage volumes for providers that
+// support them.
+type VolumeManager interface {
+	// CreateVolume creates an ext4-formatted volume of sizeGB and attaches it
+	// to the VM. The volume is not mounted.
+	// Returns the provider's volume identifier.
+	CreateVolume(ctx context.Context, vmID string, sizeGB int) (volumeID string, err error)
+
+	// AttachVolume attaches an existing, unattached volume to the VM, such as
+	// one kept by destroy --keep-volume. The volume is not mounted.
+	// Returns the volume's size in GB, or ErrNotFound if it does not exist.
+	AttachVolume(ctx context.Context, vmID, volumeID string) (sizeGB int, err error)
+
+	// DeleteVolume detaches a volume if needed and removes it.
+	// Deleting an already-deleted volume is not an error.
+	DeleteVolume(ctx context.Context, volumeID string) error
+}
+
+// PowerManager stops VMs without deleting them, for providers that support it.
+type PowerManager interface {
+	// Stop shuts the VM down, keeping its disk and addresses.
+	// Returns ErrNotFound if the VM does not exist.
+	Stop(ctx context.Context, id string) error
+}
+
+// ImageLister lists the OS images available for new VMs.
+type ImageLister interface {
+	// ListImages returns the available, non-deprecated OS images.
+	ListImages(ctx context.Context) ([]Image, error)
+}
+
+// RegionLister lists the regions VMs can be created in.
+type RegionLister interface {
+	// ListRegions returns each region with the server types currently
+	// available there.
+	ListRegions(ctx context.Context) ([]Region, error)
 }
 
 // SSHKeyManager handles SSH key lifecycle for a provider.
@@ -43,4 +112,10 @@ type SSHKeyManager interface {
 	// If a key with the same fingerprint exists, returns its ID.
 	// Otherwise, creates a new key and returns its ID.
 	EnsureSSHKey(ctx context.Context, name, publicKey string) (keyID string, err error)
+
+	// ListKeys returns all SSH keys registered with the provider.
+	ListKeys(ctx context.Context) ([]SSHKey, error)
+
+	// DeleteKey removes an SSH key from the provider.
+	DeleteKey(ctx context.Context, keyID string) error
 }
@@ -2,6 +2,8 @@ package provider
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/sandctl/sandctl/internal/config"
 )
@@ -23,16 +25,60 @@ func Register(name string, factory Factory) {
 func Get(name string, cfg *config.Config) (Provider, error) {
 	factory, ok := providers[name]
 	if !ok {
-		return nil, fmt.Errorf("unknown provider: %s", name)
+		return nil, fmt.Errorf("unknown provider: %s (available: %s)", name, strings.Join(Available(), ", "))
 	}
-	return factory(cfg)
+	p, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if p.Name() != name {
+		return nil, fmt.Errorf("provider registered as %s reports name %q", name, p.Name())
+	}
+	return p, nil
 }
 
-// Available returns a list of registered provider names.
+// Available returns the registered provider names, sorted.
 func Available() []string {
 	names := make([]string, 0, len(providers))
 	for name := range providers {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
+
+// Probe returns an instance of the named provider created with a placeholder
+// configuration, for inspecting what it supports. The instance must not be
+// used to call the provider's API.
+func Probe(name string) (Provider, error) {
+	cfg := &config.Config{
+		Providers: map[string]config.ProviderConfig{
+			name: {Token: "placeholder"},
+		},
+	}
+	return Get(name, cfg)
+}
+
+// Capabilities returns the names of the optional interfaces p implements.
+func Capabilities(p Provider) []string {
+	var caps []string
+	if _, ok := p.(SSHKeyManager); ok {
+		caps = append(caps, "SSHKeyManager")
+	}
+	if _, ok := p.(FirewallManager); ok {
+		caps = append(caps, "FirewallManager")
+	}
+	if _, ok := p.(VolumeManager); ok {
+		caps = append(caps, "VolumeManager")
+	}
+	if _, ok := p.(PowerManager); ok {
+		caps = append(caps, "PowerManager")
+	}
+	if _, ok := p.(ImageLister); ok {
+		caps = append(caps, "ImageLister")
+	}
+	if _, ok := p.(DefaultsResolver); ok {
+		caps = append(caps, "DefaultsResolver")
+	}
+	return caps
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sandctl/sandctl/internal/config"
+)
+
+// fakeProvider is a minimal Provider that reports a fixed name.
+type fakeProvider struct {
+	Provider
+	name string
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+// fakePowerProvider adds PowerManager to fakeProvider.
+type fakePowerProvider struct {
+	fakeProvider
+}
+
+func (p *fakePowerProvider) Stop(ctx context.Context, id string) error { return nil }
+
+// registerTestProvider registers factory under name for the duration of the test.
+func registerTestProvider(t *testing.T, name string, factory Factory) {
+	t.Helper()
+	Register(name, factory)
+	t.Cleanup(func() { delete(providers, name) })
+}
+
+// TestGet_GivenMismatchedName_ThenReturnsError tests provider name validation.
+func TestGet_GivenMismatchedName_ThenReturnsError(t *testing.T) {
+	registerTestProvider(t, "fake", func(cfg *config.Config) (Provider, error) {
+		return &fakeProvider{name: "other"}, nil
+	})
+
+	if _, err := Get("fake", &config.Config{}); err == nil || !strings.Contains(err.Error(), `"other"`) {
+		t.Errorf("Get() error = %v, want name mismatch", err)
+	}
+}
+
+// TestGet_GivenUnknownName_ThenListsAvailable tests the unknown provider error.
+func TestGet_GivenUnknownName_ThenListsAvailable(t *testing.T) {
+	registerTestProvider(t, "fake", func(cfg *config.Config) (Provider, error) {
+		return &fakeProvider{name: "fake"}, nil
+	})
+
+	_, err := Get("missing", &config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "fake") {
+		t.Errorf("Get() error = %v, want available providers listed", err)
+	}
+}
+
+// TestProbe_GivenRegisteredProvider_ThenReportsCapabilities tests capability discovery.
+func TestProbe_GivenRegisteredProvider_ThenReportsCapabilities(t *testing.T) {
+	registerTestProvider(t, "fake", func(cfg *config.Config) (Provider, error) {
+		if _, ok := cfg.GetProviderConfig("fake"); !ok {
+			t.Error("Probe() config has no section for the provider")
+		}
+		return &fakePowerProvider{fakeProvider{name: "fake"}}, nil
+	})
+
+	p, err := Probe("fake")
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if got, want := Capabilities(p), []string{"PowerManager"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Capabilities() = %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,9 @@
 package provider
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // VMStatus represents the state of a virtual machine.
 type VMStatus string
@@ -29,14 +32,147 @@ type VM struct {
 	// IPAddress is the public IPv4 address for SSH access.
 	IPAddress string
 
+	// IPv6 is the public IPv6 address, if assigned.
+	IPv6 string
+
 	// CreatedAt is when the VM was created.
 	CreatedAt time.Time
 
 	// Region is the datacenter location.
 	Region string
 
+	// Datacenter is the specific datacenter within the region.
+	Datacenter string
+
 	// ServerType is the hardware configuration.
 	ServerType string
+
+	// Image is the OS image the VM was created from.
+	Image string
+}
+
+// ResizeOpts configures a VM resize.
+type ResizeOpts struct {
+	// AllowDowngrade permits moving to a smaller server type. The VM keeps
+	// its current, larger disk.
+	AllowDowngrade bool
+}
+
+// ReadyState is an intermediate state reported while waiting for a VM.
+type ReadyState string
+
+const (
+	// ReadyInitializing means the VM is being created.
+	ReadyInitializing ReadyState = "initializing"
+	// ReadyStarting means the VM is booting.
+	ReadyStarting ReadyState = "starting"
+	// ReadyRunning means the VM is running and sshd is not up yet.
+	ReadyRunning ReadyState = "running"
+)
+
+// WaitOption configures WaitReady.
+type WaitOption func(*WaitOptions)
+
+// WaitOptions holds the settings set by WaitOption functions.
+type WaitOptions struct {
+	// Progress, if set, is called each time the VM moves to a new state.
+	Progress func(ReadyState)
+
+	// SSHPort is the port checked for SSH readiness. Zero means 22.
+	SSHPort int
+
+	// PreferIPv6 checks for SSH over the VM's IPv6 address when it has one.
+	PreferIPv6 bool
+}
+
+// WithProgress calls fn each time the VM moves to a new state while waiting.
+func WithProgress(fn func(ReadyState)) WaitOption {
+	return func(o *WaitOptions) {
+		o.Progress = fn
+	}
+}
+
+// WithSSHPort checks for SSH on port instead of 22 while waiting.
+func WithSSHPort(port int) WaitOption {
+	return func(o *WaitOptions) {
+		o.SSHPort = port
+	}
+}
+
+// WithIPv6 checks for SSH over IPv6 instead of IPv4 while waiting, if prefer
+// is set and the VM has an IPv6 address.
+func WithIPv6(prefer bool) WaitOption {
+	return func(o *WaitOptions) {
+		o.PreferIPv6 = prefer
+	}
+}
+
+// NewWaitOptions applies opts to the zero WaitOptions.
+func NewWaitOptions(opts []WaitOption) WaitOptions {
+	var o WaitOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Report calls the progress callback with state if it is set.
+func (o WaitOptions) Report(state ReadyState) {
+	if o.Progress != nil {
+		o.Progress(state)
+	}
+}
+
+// SSHKey is an SSH public key registered with a provider.
+type SSHKey struct {
+	// ID is the provider's key identifier.
+	ID string
+
+	// Name is the key's display name (e.g., "sandctl-1a2b3c4d").
+	Name string
+
+	// PublicKey is the key in authorized_keys format.
+	PublicKey string
+}
+
+// Image describes an OS image a VM can be created from.
+type Image struct {
+	// Name is the identifier passed as CreateOpts.Image (e.g., "ubuntu-24.04").
+	Name string
+
+	// Description is a human-readable summary.
+	Description string
+
+	// OSFlavor is the distribution family (e.g., "ubuntu", "debian").
+	OSFlavor string
+
+	// OSVersion is the distribution version (e.g., "24.04").
+	OSVersion string
+
+	// Architecture is the CPU architecture (e.g., "x86", "arm").
+	Architecture string
+}
+
+// Region describes a location VMs can be created in.
+type Region struct {
+	// Name is the identifier passed as CreateOpts.Region (e.g., "fsn1").
+	Name string
+
+	// Description is a human-readable summary (e.g., "Falkenstein DC Park 1").
+	Description string
+
+	// City and Country say where the region is.
+	City    string
+	Country string
+
+	// ServerTypes are the server types that can currently be created in
+	// the region.
+	ServerTypes []string
+}
+
+// HasServerType returns true if serverType can currently be created in the region.
+func (r Region) HasServerType(serverType string) bool {
+	return slices.Contains(r.ServerTypes, serverType)
 }
 
 // CreateOpts specifies options for creating a new VM.
@@ -58,4 +194,11 @@ type CreateOpts struct {
 
 	// UserData is an optional cloud-init script.
 	UserData string
+
+	// Labels are user-defined key/value pairs to attach to the VM.
+	Labels map[string]string
+
+	// PlacementGroup overrides the configured placement group. Providers
+	// that support it create the group if it doesn't exist.
+	PlacementGroup string
 }
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package session
+
+// lockFile is a no-op on platforms without flock; only the in-process
+// mutex protects the store there.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
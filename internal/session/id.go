@@ -1,22 +1,60 @@
 package session
 
 import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
 	"regexp"
 )
 
 // idPattern validates human-readable session names.
-// Names must be 2-15 lowercase letters only.
-var idPattern = regexp.MustCompile(`^[a-z]{2,15}$`)
+// Names must be 2-15 lowercase letters, optionally followed by a numeric
+// suffix such as "-2" when the name pool is exhausted.
+var idPattern = regexp.MustCompile(`^[a-z]{2,15}(-[1-9][0-9]*)?$`)
 
 // GenerateID creates a new unique session ID by selecting a random human name.
 // The usedNames parameter should contain all currently active session names
-// to avoid collisions.
+// to avoid collisions. Once every name in the pool is taken, a numeric
+// suffix is appended instead of failing.
 func GenerateID(usedNames []string) (string, error) {
-	return GetRandomName(usedNames)
+	name, err := GetRandomName(usedNames)
+	if !errors.Is(err, ErrNoAvailableNames) {
+		return name, err
+	}
+	return suffixedName(usedNames)
+}
+
+// suffixedName returns a name from the pool with the lowest suffix, starting
+// at "-2", that isn't in usedNames. Names are tried from a random starting
+// point so suffixed sessions aren't all named after the first pool entry.
+func suffixedName(usedNames []string) (string, error) {
+	usedSet := make(map[string]bool, len(usedNames))
+	for _, name := range usedNames {
+		usedSet[NormalizeName(name)] = true
+	}
+
+	start, err := rand.Int(rand.Reader, big.NewInt(int64(len(namePool))))
+	if err != nil {
+		return "", err
+	}
+
+	// Each suffix adds len(namePool) candidates, so this ends after at most
+	// len(usedNames)/len(namePool)+1 suffixes
+	for suffix := 2; ; suffix++ {
+		for i := range namePool {
+			base := namePool[(int(start.Int64())+i)%len(namePool)]
+			name := fmt.Sprintf("%s-%d", base, suffix)
+			if !usedSet[name] {
+				return name, nil
+			}
+		}
+	}
 }
 
 // ValidateID checks if a session ID has the correct format.
-// Valid IDs are 2-15 lowercase letters (human first names).
+// Valid IDs are 2-15 lowercase letters (human first names), optionally
+// followed by a numeric suffix like "-2".
 func ValidateID(id string) bool {
 	return idPattern.MatchString(NormalizeName(id))
 }
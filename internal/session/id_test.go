@@ -87,6 +87,8 @@ func TestValidateID_GivenValidHumanNames_ThenReturnsTrue(t *testing.T) {
 		"sofia",
 		"christopher", // 11 chars
 		"ab",          // minimum 2 chars
+		"alice-2",     // numeric suffix
+		"bob-12",
 	}
 
 	for _, id := range validIDs {
@@ -130,6 +132,9 @@ func TestValidateID_GivenInvalidIDs_ThenReturnsFalse(t *testing.T) {
 		"sandctl-abc12345", // old format
 		"alice!",           // special character
 		"123",              // all numbers
+		"alice-0",          // zero suffix
+		"alice-",           // empty suffix
+		"alice-2-3",        // two suffixes
 	}
 
 	for _, id := range invalidIDs {
@@ -140,3 +145,37 @@ func TestValidateID_GivenInvalidIDs_ThenReturnsFalse(t *testing.T) {
 		})
 	}
 }
+
+// TestGenerateID_GivenAllNamesUsed_ThenAppendsNumericSuffix tests the exhaustion fallback.
+func TestGenerateID_GivenAllNamesUsed_ThenAppendsNumericSuffix(t *testing.T) {
+	usedNames := append([]string{}, namePool...)
+
+	id, err := GenerateID(usedNames)
+	if err != nil {
+		t.Fatalf("GenerateID() error = %v", err)
+	}
+	if !regexp.MustCompile(`^[a-z]{2,15}-2$`).MatchString(id) {
+		t.Errorf("GenerateID() = %q, want a pool name with suffix -2", id)
+	}
+	if !ValidateID(id) {
+		t.Errorf("generated ID failed validation: %s", id)
+	}
+
+	// Use up every -2 name too, forcing the next suffix
+	for _, name := range namePool {
+		usedNames = append(usedNames, name+"-2")
+	}
+
+	id, err = GenerateID(usedNames)
+	if err != nil {
+		t.Fatalf("GenerateID() error = %v", err)
+	}
+	if !regexp.MustCompile(`^[a-z]{2,15}-3$`).MatchString(id) {
+		t.Errorf("GenerateID() = %q, want a pool name with suffix -3", id)
+	}
+	for _, used := range usedNames {
+		if id == used {
+			t.Fatalf("GenerateID() returned used name %q", id)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	// Register the pure-Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the sessions table. The full session is stored as JSON
+// in data; id and status are duplicated into columns for lookups.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	seq    INTEGER PRIMARY KEY AUTOINCREMENT,
+	id     TEXT NOT NULL UNIQUE,
+	status TEXT NOT NULL,
+	data   TEXT NOT NULL
+)`
+
+// SQLiteStore stores sessions in a SQLite database. Unlike FileStore, each
+// mutation only touches the affected row, and SQLite's own locking keeps
+// concurrent processes consistent.
+type SQLiteStore struct {
+	path string
+
+	once    sync.Once
+	db      *sql.DB
+	openErr error
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// NewSQLiteStore creates a SQLite session store at the given path.
+// The database is created on first use.
+func NewSQLiteStore(path string) *SQLiteStore {
+	if path == "" {
+		path = filepath.Join(filepath.Dir(DefaultStorePath()), "sessions.db")
+	}
+	return &SQLiteStore{path: path}
+}
+
+// open opens the database and creates the schema on first call.
+func (s *SQLiteStore) open() (*sql.DB, error) {
+	s.once.Do(func() {
+		if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+			s.openErr = fmt.Errorf("failed to create sessions directory: %w", err)
+			return
+		}
+
+		db, err := sql.Open("sqlite", sqliteDSN(s.path))
+		if err != nil {
+			s.openErr = fmt.Errorf("failed to open sessions database: %w", err)
+			return
+		}
+		if _, err := db.Exec(sqliteSchema); err != nil {
+			db.Close()
+			s.openErr = fmt.Errorf("failed to initialize sessions database: %w", err)
+			return
+		}
+		if err := os.Chmod(s.path, 0600); err != nil {
+			db.Close()
+			s.openErr = fmt.Errorf("failed to set sessions database permissions: %w", err)
+			return
+		}
+		s.db = db
+	})
+	return s.db, s.openErr
+}
+
+// sqliteDSN returns the URI filename for the database at path. The path is
+// escaped so characters such as '?' or '#' in it are not read as the query.
+// Transactions begin IMMEDIATE, so a read-then-write in Update or Archive takes
+// the write lock up front instead of failing to upgrade it under contention.
+func sqliteDSN(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") && filepath.IsAbs(filepath.FromSlash(path)) {
+		// Windows drive paths need a leading slash in a file URI.
+		path = "/" + path
+	}
+	u := url.URL{
+		Scheme:   "file",
+		Path:     path,
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate",
+	}
+	return u.String()
+}
+
+// Close closes the underlying database.
+func (s *SQLiteStore) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Add appends a new session to the store.
+func (s *SQLiteStore) Add(session Session) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	session.ID = NormalizeName(session.ID)
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	result, err := db.Exec(
+		`INSERT INTO sessions (id, status, data) VALUES (?, ?, ?) ON CONFLICT(id) DO NOTHING`,
+		session.ID, string(session.Status), string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add session: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("session with name '%s' already exists", session.ID)
+	}
+	return nil
+}
+
+// Update modifies an existing session's status.
+func (s *SQLiteStore) Update(id string, status Status) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	session, err := scanSession(tx.QueryRow(`SELECT data FROM sessions WHERE id = ?`, NormalizeName(id)), id)
+	if err != nil {
+		return err
+	}
+	session.Status = status
+
+	if err := updateRow(tx, *session); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateSession replaces an existing session with updated data.
+func (s *SQLiteStore) UpdateSession(session Session) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return updateRow(db, session)
+}
+
+// Remove deletes a session from the store.
+func (s *SQLiteStore) Remove(id string) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`DELETE FROM sessions WHERE id = ?`, NormalizeName(id))
+	if err != nil {
+		return fmt.Errorf("failed to remove session: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{ID: id}
+	}
+	return nil
+}
+
+// List returns all sessions in the store that aren't archived, oldest first.
+func (s *SQLiteStore) List() ([]Session, error) {
+	return s.query(`SELECT data FROM sessions WHERE json_extract(data, '$.archived_at') IS NULL ORDER BY seq`)
+}
+
+// ListActive returns only active sessions (provisioning or running).
+func (s *SQLiteStore) ListActive() ([]Session, error) {
+	return s.query(`SELECT data FROM sessions WHERE status IN (?, ?) AND json_extract(data, '$.archived_at') IS NULL ORDER BY seq`,
+		string(StatusProvisioning), string(StatusRunning))
+}
+
+// ListArchived returns only archived sessions, oldest first.
+func (s *SQLiteStore) ListArchived() ([]Session, error) {
+	return s.query(`SELECT data FROM sessions WHERE json_extract(data, '$.archived_at') IS NOT NULL ORDER BY seq`)
+}
+
+// Archive marks a stopped or failed session as archived.
+func (s *SQLiteStore) Archive(id string) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to archive session: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	session, err := scanSession(tx.QueryRow(`SELECT data FROM sessions WHERE id = ?`, NormalizeName(id)), id)
+	if err != nil {
+		return err
+	}
+	if err := archive(session); err != nil {
+		return err
+	}
+
+	if err := updateRow(tx, *session); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns a single session by ID.
+func (s *SQLiteStore) Get(id string) (*Session, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return scanSession(db.QueryRow(`SELECT data FROM sessions WHERE id = ?`, NormalizeName(id)), id)
+}
+
+// GetUsedNames returns a list of all session IDs (names) currently in the store.
+func (s *SQLiteStore) GetUsedNames() ([]string, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT id FROM sessions ORDER BY seq`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// query runs a SELECT returning session data rows.
+func (s *SQLiteStore) query(query string, args ...any) ([]Session, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, fmt.Errorf("failed to parse session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// scanSession decodes a single session row, mapping a missing row to NotFoundError.
+func scanSession(row *sql.Row, id string) (*Session, error) {
+	var data string
+	if err := row.Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &NotFoundError{ID: id}
+		}
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return &session, nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// updateRow replaces the stored data for an existing session.
+func updateRow(e execer, session Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	result, err := e.Exec(`UPDATE sessions SET status = ?, data = ? WHERE id = ?`,
+		string(session.Status), string(data), NormalizeName(session.ID))
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return &NotFoundError{ID: session.ID}
+	}
+	return nil
+}
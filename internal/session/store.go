@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/sandctl/sandctl/internal/datadir"
 )
 
 // NormalizeName converts a name to lowercase and trims whitespace.
@@ -16,41 +19,114 @@ func NormalizeName(name string) string {
 }
 
 // Store manages local session storage.
-type Store struct {
+type Store interface {
+	// Add appends a new session. Returns an error if the name is taken.
+	Add(session Session) error
+
+	// Get returns a single session by ID, or a *NotFoundError.
+	Get(id string) (*Session, error)
+
+	// Update modifies an existing session's status.
+	Update(id string, status Status) error
+
+	// UpdateSession replaces an existing session with updated data.
+	UpdateSession(session Session) error
+
+	// Remove deletes a session from the store.
+	Remove(id string) error
+
+	// List returns all sessions in the store that aren't archived.
+	List() ([]Session, error)
+
+	// ListActive returns only active sessions (provisioning or running).
+	ListActive() ([]Session, error)
+
+	// Archive marks a stopped or failed session as archived, keeping its
+	// record out of List. Returns a *NotFoundError if it doesn't exist.
+	Archive(id string) error
+
+	// ListArchived returns only archived sessions.
+	ListArchived() ([]Session, error)
+
+	// GetUsedNames returns the IDs of all sessions in the store.
+	GetUsedNames() ([]string, error)
+}
+
+// Store backends selectable with OpenStore.
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// OpenStore returns the store for the named backend, rooted in dir.
+// An empty backend selects the JSON file store; an empty dir uses the data
+// directory.
+func OpenStore(backend, dir string) (Store, error) {
+	if dir == "" {
+		dir = filepath.Dir(DefaultStorePath())
+	}
+
+	switch strings.ToLower(backend) {
+	case "", BackendJSON:
+		return NewStore(filepath.Join(dir, "sessions.json")), nil
+	case BackendSQLite:
+		return NewSQLiteStore(filepath.Join(dir, "sessions.db")), nil
+	default:
+		return nil, fmt.Errorf("unknown session store backend: %s (valid: %s, %s)", backend, BackendJSON, BackendSQLite)
+	}
+}
+
+// FileStore stores sessions in a single JSON file. Writes hold an flock on
+// a sidecar lock file so concurrent sandctl processes don't lose updates.
+type FileStore struct {
 	path string
 	mu   sync.RWMutex
 }
 
+var _ Store = (*FileStore)(nil)
+
 // storeData represents the JSON structure of the sessions file.
 type storeData struct {
 	Sessions []Session `json:"sessions"`
 }
 
-// DefaultStorePath returns the default sessions file path.
+// DefaultStorePath returns the default sessions file path (sessions.json in
+// the data directory).
 func DefaultStorePath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ".sandctl/sessions.json"
-	}
-	return filepath.Join(home, ".sandctl", "sessions.json")
+	return datadir.Path("sessions.json")
 }
 
-// NewStore creates a new session store at the given path.
-func NewStore(path string) *Store {
+// NewStore creates a new JSON file session store at the given path.
+func NewStore(path string) *FileStore {
 	if path == "" {
 		path = DefaultStorePath()
 	}
-	return &Store{path: path}
+	return &FileStore{path: path}
 }
 
 // ensureDir creates the parent directory if it doesn't exist.
-func (s *Store) ensureDir() error {
+func (s *FileStore) ensureDir() error {
 	dir := filepath.Dir(s.path)
 	return os.MkdirAll(dir, 0700)
 }
 
+// lock serializes read-modify-write cycles across processes with an advisory
+// lock on a sidecar file. The sessions file itself can't be locked because
+// save replaces it by rename.
+func (s *FileStore) lock() (func(), error) {
+	if err := s.ensureDir(); err != nil {
+		return nil, fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock sessions file: %w", err)
+	}
+	return unlock, nil
+}
+
 // load reads the sessions file and returns the data.
-func (s *Store) load() (*storeData, error) {
+func (s *FileStore) load() (*storeData, error) {
 	data, err := os.ReadFile(s.path)
 	if os.IsNotExist(err) {
 		return &storeData{Sessions: []Session{}}, nil
@@ -67,8 +143,10 @@ func (s *Store) load() (*storeData, error) {
 	return &store, nil
 }
 
-// save writes the sessions data to disk.
-func (s *Store) save(data *storeData) error {
+// save writes the sessions data to disk atomically.
+// Data is written to a temp file in the same directory, synced, then renamed
+// over the sessions file so an interrupted write never truncates it.
+func (s *FileStore) save(data *storeData) error {
 	if err := s.ensureDir(); err != nil {
 		return fmt.Errorf("failed to create sessions directory: %w", err)
 	}
@@ -78,18 +156,59 @@ func (s *Store) save(data *storeData) error {
 		return fmt.Errorf("failed to marshal sessions: %w", err)
 	}
 
-	if err := os.WriteFile(s.path, jsonData, 0600); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".sessions.tmp.*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// Ensure cleanup on any error
+	defer func() {
+		if tmpPath != "" {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set sessions file permissions: %w", err)
+	}
+
+	if _, err := tmp.Write(jsonData); err != nil {
+		tmp.Close()
 		return fmt.Errorf("failed to write sessions file: %w", err)
 	}
 
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync sessions file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write sessions file: %w", err)
+	}
+
+	// Clear tmpPath so deferred cleanup doesn't remove the final file
+	tmpPath = ""
+
 	return nil
 }
 
 // Add appends a new session to the store.
-func (s *Store) Add(session Session) error {
+func (s *FileStore) Add(session Session) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	data, err := s.load()
 	if err != nil {
 		return err
@@ -110,10 +229,16 @@ func (s *Store) Add(session Session) error {
 }
 
 // Update modifies an existing session's status.
-func (s *Store) Update(id string, status Status) error {
+func (s *FileStore) Update(id string, status Status) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	data, err := s.load()
 	if err != nil {
 		return err
@@ -139,10 +264,16 @@ func (s *Store) Update(id string, status Status) error {
 }
 
 // UpdateSession replaces an existing session with updated data.
-func (s *Store) UpdateSession(session Session) error {
+func (s *FileStore) UpdateSession(session Session) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	data, err := s.load()
 	if err != nil {
 		return err
@@ -168,10 +299,16 @@ func (s *Store) UpdateSession(session Session) error {
 }
 
 // Remove deletes a session from the store.
-func (s *Store) Remove(id string) error {
+func (s *FileStore) Remove(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	data, err := s.load()
 	if err != nil {
 		return err
@@ -198,8 +335,18 @@ func (s *Store) Remove(id string) error {
 	return s.save(data)
 }
 
-// List returns all sessions in the store.
-func (s *Store) List() ([]Session, error) {
+// List returns all sessions in the store that aren't archived.
+func (s *FileStore) List() ([]Session, error) {
+	return s.listWhere(func(session Session) bool { return !session.IsArchived() })
+}
+
+// ListArchived returns only archived sessions.
+func (s *FileStore) ListArchived() ([]Session, error) {
+	return s.listWhere(func(session Session) bool { return session.IsArchived() })
+}
+
+// listWhere returns the sessions for which keep returns true.
+func (s *FileStore) listWhere(keep func(Session) bool) ([]Session, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -208,11 +355,61 @@ func (s *Store) List() ([]Session, error) {
 		return nil, err
 	}
 
-	return data.Sessions, nil
+	sessions := make([]Session, 0, len(data.Sessions))
+	for _, session := range data.Sessions {
+		if keep(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// Archive marks a stopped or failed session as archived.
+func (s *FileStore) Archive(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	// Normalize input for case-insensitive lookup
+	normalizedID := NormalizeName(id)
+
+	for i := range data.Sessions {
+		if NormalizeName(data.Sessions[i].ID) == normalizedID {
+			if err := archive(&data.Sessions[i]); err != nil {
+				return err
+			}
+			return s.save(data)
+		}
+	}
+
+	return &NotFoundError{ID: id}
+}
+
+// archive sets session's archive time, if it is in a state that can be archived.
+func archive(session *Session) error {
+	if session.IsArchived() {
+		return nil
+	}
+	if !session.Status.IsTerminal() {
+		return fmt.Errorf("session '%s' is %s; only stopped or failed sessions can be archived", session.ID, session.Status)
+	}
+	now := time.Now().UTC()
+	session.ArchivedAt = &now
+	return nil
 }
 
 // ListActive returns only active sessions (provisioning or running).
-func (s *Store) ListActive() ([]Session, error) {
+func (s *FileStore) ListActive() ([]Session, error) {
 	sessions, err := s.List()
 	if err != nil {
 		return nil, err
@@ -229,7 +426,7 @@ func (s *Store) ListActive() ([]Session, error) {
 }
 
 // Get returns a single session by ID.
-func (s *Store) Get(id string) (*Session, error) {
+func (s *FileStore) Get(id string) (*Session, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -252,7 +449,7 @@ func (s *Store) Get(id string) (*Session, error) {
 
 // GetUsedNames returns a list of all session IDs (names) currently in the store.
 // This is used to check for name availability when generating new session names.
-func (s *Store) GetUsedNames() ([]string, error) {
+func (s *FileStore) GetUsedNames() ([]string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
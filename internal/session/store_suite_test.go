@@ -0,0 +1,147 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// storeBackends lists the Store implementations the shared store tests run against.
+var storeBackends = []struct {
+	name string
+	open func(dir string) Store
+}{
+	{BackendJSON, func(dir string) Store { return NewStore(filepath.Join(dir, "sessions.json")) }},
+	{BackendSQLite, func(dir string) Store { return NewSQLiteStore(filepath.Join(dir, "sessions.db")) }},
+}
+
+// forEachStore runs fn as a subtest for every backend. Each call to open returns
+// a new store instance backed by the same location, so tests can reload from disk.
+func forEachStore(t *testing.T, fn func(t *testing.T, open func() Store)) {
+	t.Helper()
+
+	for _, backend := range storeBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fn(t, func() Store {
+				store := backend.open(dir)
+				if s, ok := store.(*SQLiteStore); ok {
+					t.Cleanup(func() { s.Close() })
+				}
+				return store
+			})
+		})
+	}
+}
+
+// TestOpenStore_GivenBackend_ThenReturnsMatchingStore tests backend selection.
+func TestOpenStore_GivenBackend_ThenReturnsMatchingStore(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		backend string
+		check   func(Store) bool
+	}{
+		{"", func(s Store) bool { _, ok := s.(*FileStore); return ok }},
+		{"json", func(s Store) bool { _, ok := s.(*FileStore); return ok }},
+		{"SQLite", func(s Store) bool { _, ok := s.(*SQLiteStore); return ok }},
+	}
+
+	for _, tt := range tests {
+		store, err := OpenStore(tt.backend, dir)
+		if err != nil {
+			t.Fatalf("OpenStore(%q) error = %v", tt.backend, err)
+		}
+		if !tt.check(store) {
+			t.Errorf("OpenStore(%q) returned %T", tt.backend, store)
+		}
+	}
+
+	if _, err := OpenStore("postgres", dir); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+// TestSQLiteStore_GivenPersistedSessions_ThenSurvivesReopen tests that data
+// outlives the store instance.
+func TestSQLiteStore_GivenPersistedSessions_ThenSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+
+	store := NewSQLiteStore(path)
+	if err := store.Add(Session{ID: "alice", Status: StatusRunning, Labels: map[string]string{"team": "web"}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := NewSQLiteStore(path)
+	defer reopened.Close()
+
+	got, err := reopened.Get("alice")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Labels["team"] != "web" {
+		t.Errorf("Labels = %v, want team=web", got.Labels)
+	}
+}
+
+// TestSQLiteStore_GivenSpecialCharactersInPath_ThenOpensExactPath tests that
+// the path is escaped rather than parsed as part of the URI.
+func TestSQLiteStore_GivenSpecialCharactersInPath_ThenOpensExactPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "odd?name#50%", "sessions.db")
+
+	store := NewSQLiteStore(path)
+	defer store.Close()
+
+	if err := store.Add(Session{ID: "alice", Status: StatusRunning}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected database at %s: %v", path, err)
+	}
+}
+
+// TestSQLiteStore_GivenTwoStoresUpdatingSameFile_ThenNoUpdateFails tests that
+// read-then-write transactions from separate connections wait for each other
+// instead of failing with SQLITE_BUSY.
+func TestSQLiteStore_GivenTwoStoresUpdatingSameFile_ThenNoUpdateFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	stores := []*SQLiteStore{NewSQLiteStore(path), NewSQLiteStore(path)}
+	for _, store := range stores {
+		defer store.Close()
+	}
+	if err := stores[0].Add(Session{ID: "alice", Status: StatusRunning}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	const perStore = 20
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(stores)*perStore)
+
+	for _, store := range stores {
+		wg.Add(1)
+		go func(store *SQLiteStore) {
+			defer wg.Done()
+			for j := 0; j < perStore; j++ {
+				status := StatusRunning
+				if j%2 == 0 {
+					status = StatusStopped
+				}
+				if err := store.Update("alice", status); err != nil {
+					errChan <- err
+				}
+			}
+		}(store)
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	for err := range errChan {
+		t.Errorf("Update() error = %v", err)
+	}
+}
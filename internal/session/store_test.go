@@ -1,11 +1,15 @@
 package session
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/sandctl/sandctl/internal/datadir"
 )
 
 // TestNewStore_GivenEmptyPath_ThenUsesDefault tests default path usage.
@@ -45,346 +49,540 @@ func TestDefaultStorePath_GivenHomeDir_ThenReturnsExpectedPath(t *testing.T) {
 	}
 }
 
-// TestStore_Add_GivenNewSession_ThenPersistsSession tests adding a session.
-func TestStore_Add_GivenNewSession_ThenPersistsSession(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
-
-	session := Session{
-		ID:        "sandctl-test1234",
-		Status:    StatusRunning,
-		CreatedAt: time.Now(),
-	}
+// TestOpenStore_GivenDataDirEnv_ThenRelocatesStore tests SANDCTL_DATA_DIR.
+func TestOpenStore_GivenDataDirEnv_ThenRelocatesStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(datadir.EnvVar, dir)
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
+	if got, want := DefaultStorePath(), filepath.Join(dir, "sessions.json"); got != want {
+		t.Errorf("DefaultStorePath() = %q, want %q", got, want)
 	}
 
-	// Verify session was persisted
-	sessions, err := store.List()
+	store, err := OpenStore("", "")
 	if err != nil {
-		t.Fatalf("List() error = %v", err)
+		t.Fatalf("OpenStore() error = %v", err)
 	}
-
-	if len(sessions) != 1 {
-		t.Errorf("expected 1 session, got %d", len(sessions))
+	if err := store.Add(Session{ID: "alice", Status: StatusRunning}); err != nil {
+		t.Fatalf("Add() error = %v", err)
 	}
-	if sessions[0].ID != session.ID {
-		t.Errorf("ID = %q, want %q", sessions[0].ID, session.ID)
+	if _, err := os.Stat(filepath.Join(dir, "sessions.json")); err != nil {
+		t.Errorf("sessions file not written to data dir: %v", err)
 	}
 }
 
+// TestStore_Add_GivenNewSession_ThenPersistsSession tests adding a session.
+func TestStore_Add_GivenNewSession_ThenPersistsSession(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		session := Session{
+			ID:        "sandctl-test1234",
+			Status:    StatusRunning,
+			CreatedAt: time.Now(),
+		}
+
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+
+		// Verify session was persisted
+		sessions, err := store.List()
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		if len(sessions) != 1 {
+			t.Errorf("expected 1 session, got %d", len(sessions))
+		}
+		if sessions[0].ID != session.ID {
+			t.Errorf("ID = %q, want %q", sessions[0].ID, session.ID)
+		}
+	})
+}
+
 // TestStore_Add_GivenDuplicateID_ThenReturnsError tests duplicate detection.
 func TestStore_Add_GivenDuplicateID_ThenReturnsError(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	session := Session{
-		ID:     "sandctl-test1234",
-		Status: StatusRunning,
-	}
+		session := Session{
+			ID:     "sandctl-test1234",
+			Status: StatusRunning,
+		}
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	// Try to add duplicate
-	err := store.Add(session)
+		// Try to add duplicate
+		err := store.Add(session)
 
-	if err == nil {
-		t.Error("expected error for duplicate ID")
-	}
+		if err == nil {
+			t.Error("expected error for duplicate ID")
+		}
+	})
 }
 
 // TestStore_Update_GivenExistingSession_ThenUpdatesStatus tests status update.
 func TestStore_Update_GivenExistingSession_ThenUpdatesStatus(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		session := Session{
+			ID:     "sandctl-test1234",
+			Status: StatusRunning,
+		}
+
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+
+		if err := store.Update(session.ID, StatusStopped); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		// Verify update
+		got, err := store.Get(session.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if got.Status != StatusStopped {
+			t.Errorf("Status = %q, want %q", got.Status, StatusStopped)
+		}
+	})
+}
+
+// TestStore_UpdateSession_GivenLabels_ThenPreservesLabels tests label round-trip.
+func TestStore_UpdateSession_GivenLabels_ThenPreservesLabels(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		session := Session{
+			ID:     "alice",
+			Status: StatusProvisioning,
+			Labels: map[string]string{"project": "ghost"},
+		}
+
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+
+		session.Status = StatusRunning
+		if err := store.UpdateSession(session); err != nil {
+			t.Fatalf("UpdateSession() error = %v", err)
+		}
+
+		// Reload from disk with a fresh store
+		got, err := open().Get(session.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if got.Labels["project"] != "ghost" {
+			t.Errorf("Labels = %v, want project=ghost", got.Labels)
+		}
+	})
+}
+
+// TestStore_Save_GivenInterruptedWrite_ThenOriginalUntouched tests that a partial
+// temp file left behind by a killed process doesn't affect the sessions file.
+func TestStore_Save_GivenInterruptedWrite_ThenOriginalUntouched(t *testing.T) {
 	tmpDir := t.TempDir()
 	storePath := filepath.Join(tmpDir, "sessions.json")
 	store := NewStore(storePath)
 
-	session := Session{
-		ID:     "sandctl-test1234",
-		Status: StatusRunning,
-	}
-
-	if err := store.Add(session); err != nil {
+	if err := store.Add(Session{ID: "alice", Status: StatusRunning}); err != nil {
 		t.Fatalf("Add() error = %v", err)
 	}
 
-	if err := store.Update(session.ID, StatusStopped); err != nil {
-		t.Fatalf("Update() error = %v", err)
+	// Simulate a write that was interrupted before the rename
+	partial := filepath.Join(tmpDir, ".sessions.tmp.partial")
+	if err := os.WriteFile(partial, []byte(`{"sessions": [`), 0600); err != nil {
+		t.Fatalf("failed to write partial file: %v", err)
 	}
 
-	// Verify update
-	got, err := store.Get(session.ID)
+	sessions, err := NewStore(storePath).List()
 	if err != nil {
-		t.Fatalf("Get() error = %v", err)
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "alice" {
+		t.Errorf("sessions = %v, want only alice", sessions)
 	}
 
-	if got.Status != StatusStopped {
-		t.Errorf("Status = %q, want %q", got.Status, StatusStopped)
+	// Saved file keeps secure permissions
+	info, err := os.Stat(storePath)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("permissions = %04o, want 0600", mode)
 	}
 }
 
 // TestStore_Update_GivenNonExistentID_ThenReturnsError tests update of missing session.
 func TestStore_Update_GivenNonExistentID_ThenReturnsError(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	err := store.Update("sandctl-notfound", StatusStopped)
+		err := store.Update("sandctl-notfound", StatusStopped)
 
-	if err == nil {
-		t.Error("expected error for non-existent ID")
-	}
+		if err == nil {
+			t.Error("expected error for non-existent ID")
+		}
 
-	snf, ok := err.(*NotFoundError)
-	if !ok {
-		t.Fatalf("expected NotFoundError, got %T: %v", err, err)
-	}
-	if snf.ID != "sandctl-notfound" {
-		t.Errorf("ID = %q, want %q", snf.ID, "sandctl-notfound")
-	}
+		snf, ok := err.(*NotFoundError)
+		if !ok {
+			t.Fatalf("expected NotFoundError, got %T: %v", err, err)
+		}
+		if snf.ID != "sandctl-notfound" {
+			t.Errorf("ID = %q, want %q", snf.ID, "sandctl-notfound")
+		}
+	})
 }
 
 // TestStore_Remove_GivenExistingSession_ThenRemovesSession tests session removal.
 func TestStore_Remove_GivenExistingSession_ThenRemovesSession(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	session := Session{
-		ID:     "sandctl-test1234",
-		Status: StatusRunning,
-	}
+		session := Session{
+			ID:     "sandctl-test1234",
+			Status: StatusRunning,
+		}
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	if err := store.Remove(session.ID); err != nil {
-		t.Fatalf("Remove() error = %v", err)
-	}
+		if err := store.Remove(session.ID); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
 
-	// Verify removal
-	sessions, err := store.List()
-	if err != nil {
-		t.Fatalf("List() error = %v", err)
-	}
+		// Verify removal
+		sessions, err := store.List()
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
 
-	if len(sessions) != 0 {
-		t.Errorf("expected 0 sessions, got %d", len(sessions))
-	}
+		if len(sessions) != 0 {
+			t.Errorf("expected 0 sessions, got %d", len(sessions))
+		}
+	})
 }
 
 // TestStore_Remove_GivenNonExistentID_ThenReturnsError tests removal of missing session.
 func TestStore_Remove_GivenNonExistentID_ThenReturnsError(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	err := store.Remove("sandctl-notfound")
+		err := store.Remove("sandctl-notfound")
 
-	if err == nil {
-		t.Error("expected error for non-existent ID")
-	}
+		if err == nil {
+			t.Error("expected error for non-existent ID")
+		}
 
-	_, ok := err.(*NotFoundError)
-	if !ok {
-		t.Fatalf("expected NotFoundError, got %T: %v", err, err)
-	}
+		_, ok := err.(*NotFoundError)
+		if !ok {
+			t.Fatalf("expected NotFoundError, got %T: %v", err, err)
+		}
+	})
 }
 
 // TestStore_List_GivenEmptyStore_ThenReturnsEmptySlice tests empty list.
 func TestStore_List_GivenEmptyStore_ThenReturnsEmptySlice(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	sessions, err := store.List()
+		sessions, err := store.List()
 
-	if err != nil {
-		t.Fatalf("List() error = %v", err)
-	}
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
 
-	if sessions == nil {
-		t.Error("expected non-nil slice")
-	}
+		if sessions == nil {
+			t.Error("expected non-nil slice")
+		}
 
-	if len(sessions) != 0 {
-		t.Errorf("expected 0 sessions, got %d", len(sessions))
-	}
+		if len(sessions) != 0 {
+			t.Errorf("expected 0 sessions, got %d", len(sessions))
+		}
+	})
 }
 
 // TestStore_List_GivenMultipleSessions_ThenReturnsAll tests listing multiple sessions.
 func TestStore_List_GivenMultipleSessions_ThenReturnsAll(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	sessions := []Session{
-		{ID: "sandctl-session1", Status: StatusRunning},
-		{ID: "sandctl-session2", Status: StatusStopped},
-		{ID: "sandctl-session3", Status: StatusFailed},
-	}
+		sessions := []Session{
+			{ID: "sandctl-session1", Status: StatusRunning},
+			{ID: "sandctl-session2", Status: StatusStopped},
+			{ID: "sandctl-session3", Status: StatusFailed},
+		}
 
-	for _, s := range sessions {
-		if err := store.Add(s); err != nil {
-			t.Fatalf("Add() error = %v", err)
+		for _, s := range sessions {
+			if err := store.Add(s); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
 		}
-	}
 
-	got, err := store.List()
-	if err != nil {
-		t.Fatalf("List() error = %v", err)
-	}
+		got, err := store.List()
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
 
-	if len(got) != 3 {
-		t.Errorf("expected 3 sessions, got %d", len(got))
-	}
+		if len(got) != 3 {
+			t.Errorf("expected 3 sessions, got %d", len(got))
+		}
+	})
 }
 
 // TestStore_ListActive_GivenMixedStatuses_ThenReturnsOnlyActive tests active filtering.
 func TestStore_ListActive_GivenMixedStatuses_ThenReturnsOnlyActive(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
-
-	sessions := []Session{
-		{ID: "sandctl-running1", Status: StatusRunning},
-		{ID: "sandctl-prov1234", Status: StatusProvisioning},
-		{ID: "sandctl-stopped1", Status: StatusStopped},
-		{ID: "sandctl-failed12", Status: StatusFailed},
-	}
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		sessions := []Session{
+			{ID: "sandctl-running1", Status: StatusRunning},
+			{ID: "sandctl-prov1234", Status: StatusProvisioning},
+			{ID: "sandctl-stopped1", Status: StatusStopped},
+			{ID: "sandctl-failed12", Status: StatusFailed},
+		}
 
-	for _, s := range sessions {
-		if err := store.Add(s); err != nil {
-			t.Fatalf("Add() error = %v", err)
+		for _, s := range sessions {
+			if err := store.Add(s); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
 		}
-	}
 
-	active, err := store.ListActive()
-	if err != nil {
-		t.Fatalf("ListActive() error = %v", err)
-	}
+		active, err := store.ListActive()
+		if err != nil {
+			t.Fatalf("ListActive() error = %v", err)
+		}
 
-	if len(active) != 2 {
-		t.Errorf("expected 2 active sessions, got %d", len(active))
-	}
+		if len(active) != 2 {
+			t.Errorf("expected 2 active sessions, got %d", len(active))
+		}
 
-	// Verify only running and provisioning sessions
-	for _, s := range active {
-		if !s.Status.IsActive() {
-			t.Errorf("expected active status, got %q", s.Status)
+		// Verify only running and provisioning sessions
+		for _, s := range active {
+			if !s.Status.IsActive() {
+				t.Errorf("expected active status, got %q", s.Status)
+			}
 		}
-	}
+	})
 }
 
-// TestStore_Get_GivenExistingID_ThenReturnsSession tests getting a session.
-func TestStore_Get_GivenExistingID_ThenReturnsSession(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+// TestStore_Archive_GivenStoppedSession_ThenMovesToArchivedList tests archiving.
+func TestStore_Archive_GivenStoppedSession_ThenMovesToArchivedList(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		for _, s := range []Session{
+			{ID: "alice", Status: StatusRunning},
+			{ID: "bob", Status: StatusStopped},
+		} {
+			if err := store.Add(s); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+		}
 
-	session := Session{
-		ID:     "sandctl-test1234",
-		Status: StatusRunning,
-	}
+		if err := store.Archive("Bob"); err != nil {
+			t.Fatalf("Archive() error = %v", err)
+		}
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		store = open()
+		listed, err := store.List()
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(listed) != 1 || listed[0].ID != "alice" {
+			t.Errorf("List() = %v, want only alice", listed)
+		}
 
-	got, err := store.Get(session.ID)
+		archived, err := store.ListArchived()
+		if err != nil {
+			t.Fatalf("ListArchived() error = %v", err)
+		}
+		if len(archived) != 1 || archived[0].ID != "bob" || archived[0].ArchivedAt == nil {
+			t.Errorf("ListArchived() = %v, want bob with an archive time", archived)
+		}
 
-	if err != nil {
-		t.Fatalf("Get() error = %v", err)
-	}
-	if got.ID != session.ID {
-		t.Errorf("ID = %q, want %q", got.ID, session.ID)
-	}
+		got, err := store.Get("bob")
+		if err != nil || !got.IsArchived() {
+			t.Errorf("Get(bob) = %v, %v; want archived session", got, err)
+		}
+	})
 }
 
-// TestStore_Get_GivenNonExistentID_ThenReturnsError tests getting missing session.
-func TestStore_Get_GivenNonExistentID_ThenReturnsError(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+// TestStore_Archive_GivenRunningOrMissingSession_ThenReturnsError tests archive preconditions.
+func TestStore_Archive_GivenRunningOrMissingSession_ThenReturnsError(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	_, err := store.Get("sandctl-notfound")
+		if err := store.Add(Session{ID: "alice", Status: StatusRunning}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	if err == nil {
-		t.Error("expected error for non-existent ID")
-	}
+		if err := store.Archive("alice"); err == nil {
+			t.Error("expected error archiving a running session")
+		}
 
-	_, ok := err.(*NotFoundError)
-	if !ok {
-		t.Fatalf("expected NotFoundError, got %T: %v", err, err)
-	}
+		var notFound *NotFoundError
+		if err := store.Archive("bob"); !errors.As(err, &notFound) {
+			t.Errorf("Archive(bob) error = %v, want NotFoundError", err)
+		}
+	})
 }
 
-// TestStore_ConcurrentOperations_GivenParallelAccess_ThenNoRaceConditions tests thread safety.
-func TestStore_ConcurrentOperations_GivenParallelAccess_ThenNoRaceConditions(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+// TestStore_Get_GivenExistingID_ThenReturnsSession tests getting a session.
+func TestStore_Get_GivenExistingID_ThenReturnsSession(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	// Add initial sessions
-	for i := 0; i < 5; i++ {
 		session := Session{
-			ID:     "sandctl-init" + string(rune('a'+i)) + "000",
+			ID:     "sandctl-test1234",
 			Status: StatusRunning,
 		}
+
 		if err := store.Add(session); err != nil {
 			t.Fatalf("Add() error = %v", err)
 		}
-	}
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, 100)
+		got, err := store.Get(session.ID)
 
-	// Concurrent reads
-	for i := 0; i < 10; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if _, err := store.List(); err != nil {
-				errChan <- err
-			}
-		}()
-	}
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got.ID != session.ID {
+			t.Errorf("ID = %q, want %q", got.ID, session.ID)
+		}
+	})
+}
 
-	// Concurrent writes
-	for i := 0; i < 10; i++ {
-		wg.Add(1)
-		go func(n int) {
-			defer wg.Done()
+// TestStore_Get_GivenNonExistentID_ThenReturnsError tests getting missing session.
+func TestStore_Get_GivenNonExistentID_ThenReturnsError(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		_, err := store.Get("sandctl-notfound")
+
+		if err == nil {
+			t.Error("expected error for non-existent ID")
+		}
+
+		_, ok := err.(*NotFoundError)
+		if !ok {
+			t.Fatalf("expected NotFoundError, got %T: %v", err, err)
+		}
+	})
+}
+
+// TestStore_ConcurrentOperations_GivenParallelAccess_ThenNoRaceConditions tests thread safety.
+func TestStore_ConcurrentOperations_GivenParallelAccess_ThenNoRaceConditions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
+
+		// Add initial sessions
+		for i := 0; i < 5; i++ {
 			session := Session{
-				ID:     "sandctl-conc" + string(rune('a'+n)) + "000",
+				ID:     "sandctl-init" + string(rune('a'+i)) + "000",
 				Status: StatusRunning,
 			}
-			// Duplicates are expected in concurrent adds, ignore errors
-			_ = store.Add(session)
-		}(i)
-	}
+			if err := store.Add(session); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+		}
+
+		var wg sync.WaitGroup
+		errChan := make(chan error, 100)
+
+		// Concurrent reads
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.List(); err != nil {
+					errChan <- err
+				}
+			}()
+		}
+
+		// Concurrent writes
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				session := Session{
+					ID:     "sandctl-conc" + string(rune('a'+n)) + "000",
+					Status: StatusRunning,
+				}
+				// Duplicates are expected in concurrent adds, ignore errors
+				_ = store.Add(session)
+			}(i)
+		}
+
+		// Concurrent updates
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				id := "sandctl-init" + string(rune('a'+n)) + "000"
+				_ = store.Update(id, StatusStopped)
+			}(i)
+		}
+
+		wg.Wait()
+		close(errChan)
+
+		for err := range errChan {
+			t.Errorf("concurrent operation error: %v", err)
+		}
+	})
+}
+
+// TestFileStore_GivenTwoStoresOnSameFile_ThenNoUpdatesLost tests that the file
+// lock serializes writers that don't share an in-process mutex, as separate
+// sandctl processes would.
+func TestFileStore_GivenTwoStoresOnSameFile_ThenNoUpdatesLost(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "sessions.json")
+	stores := []*FileStore{NewStore(storePath), NewStore(storePath)}
+
+	const perStore = 20
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(stores)*perStore)
 
-	// Concurrent updates
-	for i := 0; i < 5; i++ {
+	for i, store := range stores {
 		wg.Add(1)
-		go func(n int) {
+		go func(n int, store *FileStore) {
 			defer wg.Done()
-			id := "sandctl-init" + string(rune('a'+n)) + "000"
-			_ = store.Update(id, StatusStopped)
-		}(i)
+			for j := 0; j < perStore; j++ {
+				session := Session{
+					ID:     fmt.Sprintf("store%d-%d", n, j),
+					Status: StatusRunning,
+				}
+				if err := store.Add(session); err != nil {
+					errChan <- err
+				}
+			}
+		}(i, store)
 	}
 
 	wg.Wait()
 	close(errChan)
 
 	for err := range errChan {
-		t.Errorf("concurrent operation error: %v", err)
+		t.Errorf("Add() error = %v", err)
+	}
+
+	sessions, err := NewStore(storePath).List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != len(stores)*perStore {
+		t.Errorf("expected %d sessions, got %d", len(stores)*perStore, len(sessions))
 	}
 }
 
@@ -427,177 +625,177 @@ func TestNormalizeName_GivenVariousCases_ThenReturnsLowercase(t *testing.T) {
 
 // TestStore_GetUsedNames_GivenEmptyStore_ThenReturnsEmptySlice tests empty store.
 func TestStore_GetUsedNames_GivenEmptyStore_ThenReturnsEmptySlice(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	names, err := store.GetUsedNames()
+		names, err := store.GetUsedNames()
 
-	if err != nil {
-		t.Fatalf("GetUsedNames() error = %v", err)
-	}
+		if err != nil {
+			t.Fatalf("GetUsedNames() error = %v", err)
+		}
 
-	if len(names) != 0 {
-		t.Errorf("expected 0 names, got %d", len(names))
-	}
+		if len(names) != 0 {
+			t.Errorf("expected 0 names, got %d", len(names))
+		}
+	})
 }
 
 // TestStore_Get_GivenCaseInsensitiveID_ThenFindsSession tests case-insensitive lookup.
 func TestStore_Get_GivenCaseInsensitiveID_ThenFindsSession(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	session := Session{
-		ID:     "alice",
-		Status: StatusRunning,
-	}
+		session := Session{
+			ID:     "alice",
+			Status: StatusRunning,
+		}
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	// Test various case variants
-	variants := []string{"alice", "Alice", "ALICE", "AlIcE"}
-	for _, variant := range variants {
-		t.Run(variant, func(t *testing.T) {
-			got, err := store.Get(variant)
-			if err != nil {
-				t.Fatalf("Get(%q) error = %v", variant, err)
-			}
-			if got.ID != "alice" {
-				t.Errorf("Get(%q) returned ID %q, want %q", variant, got.ID, "alice")
-			}
-		})
-	}
+		// Test various case variants
+		variants := []string{"alice", "Alice", "ALICE", "AlIcE"}
+		for _, variant := range variants {
+			t.Run(variant, func(t *testing.T) {
+				got, err := store.Get(variant)
+				if err != nil {
+					t.Fatalf("Get(%q) error = %v", variant, err)
+				}
+				if got.ID != "alice" {
+					t.Errorf("Get(%q) returned ID %q, want %q", variant, got.ID, "alice")
+				}
+			})
+		}
+	})
 }
 
 // TestStore_Update_GivenCaseInsensitiveID_ThenUpdatesSession tests case-insensitive update.
 func TestStore_Update_GivenCaseInsensitiveID_ThenUpdatesSession(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	session := Session{
-		ID:     "bob",
-		Status: StatusRunning,
-	}
+		session := Session{
+			ID:     "bob",
+			Status: StatusRunning,
+		}
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	// Update using different case
-	if err := store.Update("BOB", StatusStopped); err != nil {
-		t.Fatalf("Update() error = %v", err)
-	}
+		// Update using different case
+		if err := store.Update("BOB", StatusStopped); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
 
-	// Verify update
-	got, err := store.Get("bob")
-	if err != nil {
-		t.Fatalf("Get() error = %v", err)
-	}
-	if got.Status != StatusStopped {
-		t.Errorf("Status = %q, want %q", got.Status, StatusStopped)
-	}
+		// Verify update
+		got, err := store.Get("bob")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got.Status != StatusStopped {
+			t.Errorf("Status = %q, want %q", got.Status, StatusStopped)
+		}
+	})
 }
 
 // TestStore_Remove_GivenCaseInsensitiveID_ThenRemovesSession tests case-insensitive removal.
 func TestStore_Remove_GivenCaseInsensitiveID_ThenRemovesSession(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	session := Session{
-		ID:     "charlie",
-		Status: StatusRunning,
-	}
+		session := Session{
+			ID:     "charlie",
+			Status: StatusRunning,
+		}
 
-	if err := store.Add(session); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		if err := store.Add(session); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	// Remove using different case
-	if err := store.Remove("CHARLIE"); err != nil {
-		t.Fatalf("Remove() error = %v", err)
-	}
+		// Remove using different case
+		if err := store.Remove("CHARLIE"); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
 
-	// Verify removal
-	sessions, err := store.List()
-	if err != nil {
-		t.Fatalf("List() error = %v", err)
-	}
-	if len(sessions) != 0 {
-		t.Errorf("expected 0 sessions after removal, got %d", len(sessions))
-	}
+		// Verify removal
+		sessions, err := store.List()
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(sessions) != 0 {
+			t.Errorf("expected 0 sessions after removal, got %d", len(sessions))
+		}
+	})
 }
 
 // TestStore_Add_GivenCaseVariantDuplicate_ThenReturnsError tests case-insensitive duplicate detection.
 func TestStore_Add_GivenCaseVariantDuplicate_ThenReturnsError(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	session1 := Session{
-		ID:     "diana",
-		Status: StatusRunning,
-	}
+		session1 := Session{
+			ID:     "diana",
+			Status: StatusRunning,
+		}
 
-	if err := store.Add(session1); err != nil {
-		t.Fatalf("Add() error = %v", err)
-	}
+		if err := store.Add(session1); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
 
-	// Try to add with different case
-	session2 := Session{
-		ID:     "DIANA",
-		Status: StatusRunning,
-	}
+		// Try to add with different case
+		session2 := Session{
+			ID:     "DIANA",
+			Status: StatusRunning,
+		}
 
-	err := store.Add(session2)
-	if err == nil {
-		t.Error("expected error when adding case-variant duplicate")
-	}
+		err := store.Add(session2)
+		if err == nil {
+			t.Error("expected error when adding case-variant duplicate")
+		}
+	})
 }
 
 // TestStore_GetUsedNames_GivenSessions_ThenReturnsAllIDs tests returning IDs.
 func TestStore_GetUsedNames_GivenSessions_ThenReturnsAllIDs(t *testing.T) {
-	tmpDir := t.TempDir()
-	storePath := filepath.Join(tmpDir, "sessions.json")
-	store := NewStore(storePath)
+	forEachStore(t, func(t *testing.T, open func() Store) {
+		store := open()
 
-	sessions := []Session{
-		{ID: "alice", Status: StatusRunning},
-		{ID: "bob", Status: StatusStopped},
-		{ID: "charlie", Status: StatusFailed},
-	}
+		sessions := []Session{
+			{ID: "alice", Status: StatusRunning},
+			{ID: "bob", Status: StatusStopped},
+			{ID: "charlie", Status: StatusFailed},
+		}
 
-	for _, s := range sessions {
-		if err := store.Add(s); err != nil {
-			t.Fatalf("Add() error = %v", err)
+		for _, s := range sessions {
+			if err := store.Add(s); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
 		}
-	}
 
-	names, err := store.GetUsedNames()
+		names, err := store.GetUsedNames()
 
-	if err != nil {
-		t.Fatalf("GetUsedNames() error = %v", err)
-	}
+		if err != nil {
+			t.Fatalf("GetUsedNames() error = %v", err)
+		}
 
-	if len(names) != 3 {
-		t.Errorf("expected 3 names, got %d", len(names))
-	}
+		if len(names) != 3 {
+			t.Errorf("expected 3 names, got %d", len(names))
+		}
 
-	// Verify all session IDs are present
-	nameSet := make(map[string]bool)
-	for _, n := range names {
-		nameSet[n] = true
-	}
+		// Verify all session IDs are present
+		nameSet := make(map[string]bool)
+		for _, n := range names {
+			nameSet[n] = true
+		}
 
-	for _, s := range sessions {
-		if !nameSet[s.ID] {
-			t.Errorf("missing session ID %q in GetUsedNames result", s.ID)
+		for _, s := range sessions {
+			if !nameSet[s.ID] {
+				t.Errorf("missing session ID %q in GetUsedNames result", s.ID)
+			}
 		}
-	}
+	})
 }
 
 // TestStore_CreatesDirectoryIfNotExists tests directory creation.
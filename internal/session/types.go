@@ -32,6 +32,20 @@ func (s Status) String() string {
 	return string(s)
 }
 
+// Failure reasons recorded in Session.FailureReason when provisioning fails.
+const (
+	FailureVMCreate         = "vm_create_failed"
+	FailureWaitReady        = "wait_ready_failed"
+	FailureWaitReadyTimeout = "wait_ready_timeout"
+	FailureFirewall         = "firewall_failed"
+	FailureVolume           = "volume_attach_failed"
+	FailureSSHUnavailable   = "ssh_unavailable"
+	FailureCloudInit        = "cloud_init_failed"
+	FailureSetup            = "setup_failed"
+	FailureCancelled        = "cancelled"
+	FailureProvisioning     = "provisioning_failed" // Any other failure
+)
+
 // Duration wraps time.Duration for JSON marshaling.
 type Duration struct {
 	time.Duration
@@ -73,8 +87,69 @@ type Session struct {
 
 	// Provider fields (new for pluggable providers)
 	Provider   string `json:"provider,omitempty"`    // Provider name (e.g., "hetzner")
+	Profile    string `json:"profile,omitempty"`     // Config profile the session was created with
 	ProviderID string `json:"provider_id,omitempty"` // Provider-specific VM identifier
 	IPAddress  string `json:"ip_address,omitempty"`  // Public IPv4 address for SSH
+	IPv6       string `json:"ipv6,omitempty"`        // Public IPv6 address, if assigned
+	SSHUser    string `json:"ssh_user,omitempty"`    // SSH login user (empty means the default)
+	SSHPort    int    `json:"ssh_port,omitempty"`    // SSH port (zero means the default)
+
+	// Creation parameters, recorded so the session can be cloned with --from
+	Region     string `json:"region,omitempty"`
+	ServerType string `json:"server_type,omitempty"`
+	Image      string `json:"image,omitempty"`
+	Template   string `json:"template,omitempty"` // Normalized template name
+
+	// Volume fields, set when the session was created with --attach-volume or --volume
+	VolumeID     string `json:"volume_id,omitempty"`
+	VolumeSizeGB int    `json:"volume_size_gb,omitempty"`
+
+	// SSH key fields, set when the session was created with --ssh-key
+	SSHKeyFingerprint string `json:"ssh_key_fingerprint,omitempty"` // SHA256 fingerprint of the key
+	SSHKeyFile        string `json:"ssh_key_file,omitempty"`        // Private key path, if given as a file
+
+	// Firewall fields, set when ports were opened with --open-port
+	OpenPorts  []int  `json:"open_ports,omitempty"`
+	FirewallID string `json:"firewall_id,omitempty"`
+
+	// Labels are arbitrary user-defined key/value pairs for organizing sessions.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Notes is a free-form reminder of what the session is for.
+	Notes string `json:"notes,omitempty"`
+
+	// LastActiveAt is when the session was last used through exec or console,
+	// or last seen busy by the watchdog.
+	LastActiveAt *time.Time `json:"last_active_at,omitempty"`
+
+	// ProvisioningStartedAt and ProvisioningCompletedAt bracket the
+	// provisioning steps of 'sandctl new'. Completion is left unset if
+	// provisioning failed.
+	ProvisioningStartedAt   *time.Time `json:"provisioning_started_at,omitempty"`
+	ProvisioningCompletedAt *time.Time `json:"provisioning_completed_at,omitempty"`
+
+	// FailureReason says which provisioning step failed, as one of the
+	// Failure constants, when Status is failed.
+	FailureReason string `json:"failure_reason,omitempty"`
+
+	// ArchivedAt is when the session was archived. Archived sessions are
+	// kept for history but left out of List and provider syncs.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// IsArchived returns true if the session has been archived.
+func (s *Session) IsArchived() bool {
+	return s.ArchivedAt != nil
+}
+
+// ProvisioningDuration returns how long provisioning took, or nil if it
+// wasn't recorded or didn't complete.
+func (s *Session) ProvisioningDuration() *time.Duration {
+	if s.ProvisioningStartedAt == nil || s.ProvisioningCompletedAt == nil {
+		return nil
+	}
+	d := s.ProvisioningCompletedAt.Sub(*s.ProvisioningStartedAt)
+	return &d
 }
 
 // IsRunning returns true if the session is in running state.
@@ -100,11 +175,29 @@ func (s *Session) TimeoutRemaining() *time.Duration {
 	return &remaining
 }
 
+// SSHAddress returns the address to connect to. IPv4 is used unless preferIPv6
+// is set and an IPv6 address is known; either is used if it's the only one.
+func (s *Session) SSHAddress(preferIPv6 bool) string {
+	if s.IPv6 != "" && (preferIPv6 || s.IPAddress == "") {
+		return s.IPv6
+	}
+	return s.IPAddress
+}
+
 // Age returns how long the session has been running.
 func (s *Session) Age() time.Duration {
 	return time.Since(s.CreatedAt)
 }
 
+// IdleSince returns when the session was last known to be in use, falling
+// back to its creation time.
+func (s *Session) IdleSince() time.Time {
+	if s.LastActiveAt != nil {
+		return *s.LastActiveAt
+	}
+	return s.CreatedAt
+}
+
 // Validate checks that the session has valid field values.
 func (s *Session) Validate() error {
 	if s.ID == "" {
@@ -124,6 +217,17 @@ func (s *Session) Validate() error {
 	return nil
 }
 
+// MatchesLabels returns true if the session has every label in filter with the same value.
+// An empty filter matches all sessions.
+func (s *Session) MatchesLabels(filter map[string]string) bool {
+	for k, v := range filter {
+		if got, ok := s.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
 // HasProvider returns true if this is a new provider-based session.
 func (s *Session) HasProvider() bool {
 	return s.Provider != ""
@@ -277,3 +277,94 @@ func TestSession_Validate_GivenEmptyID_ThenReturnsError(t *testing.T) {
 		t.Error("expected error for empty ID")
 	}
 }
+
+// TestSession_MatchesLabels_GivenFilters_ThenMatchesAllEntries tests label filtering.
+func TestSession_MatchesLabels_GivenFilters_ThenMatchesAllEntries(t *testing.T) {
+	s := &Session{
+		ID:     "alice",
+		Labels: map[string]string{"project": "ghost", "env": "dev"},
+	}
+
+	tests := []struct {
+		name   string
+		filter map[string]string
+		want   bool
+	}{
+		{"empty filter", nil, true},
+		{"single match", map[string]string{"project": "ghost"}, true},
+		{"all match", map[string]string{"project": "ghost", "env": "dev"}, true},
+		{"value mismatch", map[string]string{"env": "prod"}, false},
+		{"missing key", map[string]string{"team": "core"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.MatchesLabels(tt.filter); got != tt.want {
+				t.Errorf("MatchesLabels(%v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSession_SSHAddress_GivenPreference_ThenSelectsAddress tests address selection.
+func TestSession_SSHAddress_GivenPreference_ThenSelectsAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		ipv4       string
+		ipv6       string
+		preferIPv6 bool
+		expected   string
+	}{
+		{"both, default", "203.0.113.10", "2001:db8::1", false, "203.0.113.10"},
+		{"both, prefer ipv6", "203.0.113.10", "2001:db8::1", true, "2001:db8::1"},
+		{"ipv4 only, prefer ipv6", "203.0.113.10", "", true, "203.0.113.10"},
+		{"ipv6 only", "", "2001:db8::1", false, "2001:db8::1"},
+		{"none", "", "", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{IPAddress: tt.ipv4, IPv6: tt.ipv6}
+			if got := s.SSHAddress(tt.preferIPv6); got != tt.expected {
+				t.Errorf("SSHAddress(%v) = %q, want %q", tt.preferIPv6, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSession_IdleSince_GivenActivity_ThenUsesLastActiveAt tests the idle reference time.
+func TestSession_IdleSince_GivenActivity_ThenUsesLastActiveAt(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := created.Add(3 * time.Hour)
+
+	s := &Session{CreatedAt: created}
+	if got := s.IdleSince(); !got.Equal(created) {
+		t.Errorf("IdleSince() without activity = %v, want %v", got, created)
+	}
+
+	s.LastActiveAt = &active
+	if got := s.IdleSince(); !got.Equal(active) {
+		t.Errorf("IdleSince() = %v, want %v", got, active)
+	}
+}
+
+// TestSession_ProvisioningDuration_GivenTimestamps_ThenReturnsElapsed tests provisioning timing.
+func TestSession_ProvisioningDuration_GivenTimestamps_ThenReturnsElapsed(t *testing.T) {
+	started := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	completed := started.Add(95 * time.Second)
+
+	s := &Session{}
+	if got := s.ProvisioningDuration(); got != nil {
+		t.Errorf("ProvisioningDuration() without timestamps = %v, want nil", *got)
+	}
+
+	s.ProvisioningStartedAt = &started
+	if got := s.ProvisioningDuration(); got != nil {
+		t.Errorf("ProvisioningDuration() without completion = %v, want nil", *got)
+	}
+
+	s.ProvisioningCompletedAt = &completed
+	if got := s.ProvisioningDuration(); got == nil || *got != 95*time.Second {
+		t.Errorf("ProvisioningDuration() = %v, want %v", got, 95*time.Second)
+	}
+}
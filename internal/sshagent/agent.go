@@ -25,6 +25,9 @@ var (
 
 	// ErrNoKeys is returned when the agent has no keys loaded.
 	ErrNoKeys = errors.New("SSH agent has no keys loaded. Run 'ssh-add' to add keys, or use --ssh-public-key for a file path")
+
+	// ErrKeyNotLoaded is returned when the configured key is not in any agent.
+	ErrKeyNotLoaded = errors.New("configured SSH key is no longer loaded in your agent; run ssh-add or re-run sandctl init")
 )
 
 // AgentKey represents a key from the SSH agent with display-friendly fields.
@@ -342,6 +345,49 @@ func GetSignerByFingerprint(fingerprint string) (ssh.Signer, error) {
 	return nil, ErrNoAgentFound
 }
 
+// CheckKeyLoaded returns ErrKeyNotLoaded if no available agent holds the key
+// with the given fingerprint. Like GetSignerByFingerprint, every discovered
+// agent is checked. If no agent can be reached, the connection error is
+// returned instead.
+func CheckKeyLoaded(fingerprint string) error {
+	sockets := Discovery()
+	if len(sockets) == 0 {
+		return ErrNoAgentFound
+	}
+
+	if !strings.HasPrefix(fingerprint, "SHA256:") {
+		fingerprint = "SHA256:" + fingerprint
+	}
+
+	var lastErr error
+	reached := false
+	for _, sock := range sockets {
+		a, err := NewFromSocket(sock)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		keys, err := a.ListKeys()
+		a.Close()
+		if err != nil && !errors.Is(err, ErrNoKeys) {
+			lastErr = err
+			continue
+		}
+
+		reached = true
+		for _, key := range keys {
+			if key.Fingerprint == fingerprint {
+				return nil
+			}
+		}
+	}
+
+	if !reached && lastErr != nil {
+		return lastErr
+	}
+	return ErrKeyNotLoaded
+}
+
 // KeyCount returns the number of keys available in the SSH agent.
 // Returns 0 if no agent is available or if an error occurs.
 func KeyCount() int {
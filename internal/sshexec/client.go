@@ -2,10 +2,14 @@
 package sshexec
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -16,6 +20,11 @@ const (
 	defaultSSHPort    = 22
 	defaultSSHUser    = "agent"
 	defaultSSHTimeout = 30 * time.Second
+
+	// sshd may reset connections for a short while after a VM boots, so
+	// connection failures are retried a few times with a doubling delay
+	defaultConnectAttempts = 3
+	defaultConnectDelay    = 500 * time.Millisecond
 )
 
 // Client wraps an SSH connection for command execution.
@@ -25,8 +34,19 @@ type Client struct {
 	user      string
 	signer    ssh.Signer
 	timeout   time.Duration
+	hostKey   ssh.HostKeyCallback
 	sshClient *ssh.Client
 	connected bool
+
+	useSSHConfig bool          // Apply matching ~/.ssh/config settings
+	jumps        []*ssh.Client // Connections to ProxyJump hosts
+
+	connectAttempts int           // Dial attempts for transient failures
+	connectDelay    time.Duration // Delay before the first retry
+
+	keepAliveInterval time.Duration // Time between keep-alives; zero disables them
+	stopKeepAlive     func()        // Stops keep-alives for the current connection
+	lost              atomic.Bool   // The connection stopped answering keep-alives
 }
 
 // ClientOption configures a Client.
@@ -39,7 +59,7 @@ func WithPort(port int) ClientOption {
 	}
 }
 
-// WithUser sets the SSH user (default: root).
+// WithUser sets the SSH user (default: agent).
 func WithUser(user string) ClientOption {
 	return func(c *Client) {
 		c.user = user
@@ -53,6 +73,42 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithConnectAttempts sets how many times Connect dials when the connection
+// fails with a transient network or handshake error (default: 3). Values
+// below 1 are treated as 1.
+func WithConnectAttempts(attempts int) ClientOption {
+	return func(c *Client) {
+		c.connectAttempts = attempts
+	}
+}
+
+// WithKeepAlive sets how often a keep-alive is sent on the connection
+// (default: 30s). A connection that misses several in a row is closed, so
+// commands and consoles on it fail with ErrConnectionLost instead of
+// hanging. Zero disables keep-alives.
+func WithKeepAlive(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.keepAliveInterval = interval
+	}
+}
+
+// WithHostKeyCallback sets the host key verification callback
+// (default: accept any host key).
+func WithHostKeyCallback(callback ssh.HostKeyCallback) ClientOption {
+	return func(c *Client) {
+		c.hostKey = callback
+	}
+}
+
+// WithSSHConfig applies the Port, ProxyJump, and ProxyCommand settings from
+// a matching ~/.ssh/config Host entry. An explicit WithPort takes precedence
+// over the configured Port.
+func WithSSHConfig() ClientOption {
+	return func(c *Client) {
+		c.useSSHConfig = true
+	}
+}
+
 // NewClient creates a new SSH client for the given host.
 // The privateKeyPath should point to the private key file (not the .pub file).
 // If the key is passphrase-protected, it will try to use ssh-agent.
@@ -77,6 +133,11 @@ func NewClient(host, privateKeyPath string, opts ...ClientOption) (*Client, erro
 		user:    defaultSSHUser,
 		signer:  signer,
 		timeout: defaultSSHTimeout,
+
+		connectAttempts: defaultConnectAttempts,
+		connectDelay:    defaultConnectDelay,
+
+		keepAliveInterval: defaultKeepAliveInterval,
 	}
 
 	for _, opt := range opts {
@@ -174,55 +235,28 @@ func findAllAgentSockets() []string {
 	return sockets
 }
 
-// getIdentityAgentFromConfig parses ~/.ssh/config for IdentityAgent directive.
+// getIdentityAgentFromConfig returns the IdentityAgent socket set in
+// ~/.ssh/config for all hosts, if it exists.
 func getIdentityAgentFromConfig() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
+	agent := sshConfigValues(readSSHConfig(), "*")["identityagent"]
+	if agent == "" {
 		return ""
 	}
 
-	configPath := home + "/.ssh/config"
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return ""
-	}
-
-	// Simple parsing - look for IdentityAgent in Host * block or global
-	lines := strings.Split(string(data), "\n")
-	inGlobalOrWildcard := true // Start assuming global context
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Check for Host directive
-		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			hostPattern := strings.TrimSpace(line[5:])
-			inGlobalOrWildcard = hostPattern == "*"
-			continue
-		}
-
-		// Look for IdentityAgent in global context or Host *
-		if inGlobalOrWildcard && strings.HasPrefix(strings.ToLower(line), "identityagent ") {
-			agent := strings.TrimSpace(line[14:])
-			// Remove quotes if present
-			agent = strings.Trim(agent, "\"'")
-			// Expand ~ to home directory
-			if strings.HasPrefix(agent, "~/") {
-				agent = home + agent[1:]
-			}
-			// Verify socket exists
-			if _, err := os.Stat(agent); err == nil {
-				return agent
-			}
+	// Expand ~ to home directory
+	if strings.HasPrefix(agent, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
 		}
+		agent = home + agent[1:]
 	}
 
-	return ""
+	// Verify socket exists
+	if _, err := os.Stat(agent); err != nil {
+		return ""
+	}
+	return agent
 }
 
 // getSignerFromFile parses a private key file directly.
@@ -248,6 +282,11 @@ func NewClientWithSigner(host string, signer ssh.Signer, opts ...ClientOption) *
 		user:    defaultSSHUser,
 		signer:  signer,
 		timeout: defaultSSHTimeout,
+
+		connectAttempts: defaultConnectAttempts,
+		connectDelay:    defaultConnectDelay,
+
+		keepAliveInterval: defaultKeepAliveInterval,
 	}
 
 	for _, opt := range opts {
@@ -263,32 +302,79 @@ func (c *Client) Connect() error {
 		return nil
 	}
 
+	hostKeyCallback := c.hostKey
+	if hostKeyCallback == nil {
+		hostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // callers opt in to verification via WithHostKeyCallback
+	}
+
 	config := &ssh.ClientConfig{
 		User: c.user,
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(c.signer),
 		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // VMs are ephemeral, host keys unknown
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         c.timeout,
 	}
 
-	addr := fmt.Sprintf("%s:%d", c.host, c.port)
-	client, err := ssh.Dial("tcp", addr, config)
-	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	port := c.port
+	var hostCfg sshConfigHost
+	if c.useSSHConfig {
+		hostCfg = lookupSSHConfig(c.host)
+		if hostCfg.Port != 0 && port == defaultSSHPort {
+			port = hostCfg.Port
+		}
 	}
 
-	c.sshClient = client
-	c.connected = true
-	return nil
+	attempts := max(c.connectAttempts, 1)
+	delay := c.connectDelay
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var client *ssh.Client
+		client, err = c.dial(hostCfg, port, config)
+		if err == nil {
+			c.sshClient = client
+			c.connected = true
+			c.startKeepAlive()
+			return nil
+		}
+		if !isTransientConnectError(err) || attempt == attempts {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	if attempts > 1 && isTransientConnectError(err) {
+		return fmt.Errorf("failed to connect to %s after %d attempts: %w", dialAddress(c.host, port), attempts, err)
+	}
+	return fmt.Errorf("failed to connect to %s: %w", dialAddress(c.host, port), err)
+}
+
+// isTransientConnectError reports whether a dial error is a network or
+// handshake failure worth retrying. Authentication and host key errors are
+// not, since they fail the same way every time.
+func isTransientConnectError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// dialAddress joins host and port for dialing, bracketing IPv6 literals.
+func dialAddress(host string, port int) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
 
 // Close closes the SSH connection.
 func (c *Client) Close() error {
+	if c.stopKeepAlive != nil {
+		c.stopKeepAlive()
+		c.stopKeepAlive = nil
+	}
 	if c.sshClient != nil {
 		err := c.sshClient.Close()
 		c.sshClient = nil
 		c.connected = false
+		c.closeJumps()
 		return err
 	}
 	return nil
@@ -307,7 +393,15 @@ func (c *Client) getSession() (*ssh.Session, error) {
 
 	session, err := c.sshClient.NewSession()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create SSH session: %w", err)
+		// The connection may have dropped since it was opened; redial once
+		c.Close()
+		if err := c.Connect(); err != nil {
+			return nil, err
+		}
+		session, err = c.sshClient.NewSession()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SSH session: %w", err)
+		}
 	}
 
 	return session, nil
@@ -316,7 +410,7 @@ func (c *Client) getSession() (*ssh.Session, error) {
 // CheckConnection tests if SSH is accepting connections.
 // This is useful for polling until a VM is ready.
 func CheckConnection(host string, port int, timeout time.Duration) bool {
-	addr := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+	addr := dialAddress(host, port)
 	conn, err := net.DialTimeout("tcp", addr, timeout)
 	if err != nil {
 		return false
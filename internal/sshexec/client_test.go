@@ -0,0 +1,134 @@
+package sshexec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// TestDialAddress_GivenHosts_ThenBracketsIPv6 tests dial address formatting.
+func TestDialAddress_GivenHosts_ThenBracketsIPv6(t *testing.T) {
+	tests := []struct {
+		host     string
+		port     int
+		expected string
+	}{
+		{"203.0.113.10", 22, "203.0.113.10:22"},
+		{"example.com", 2222, "example.com:2222"},
+		{"2001:db8::1", 22, "[2001:db8::1]:22"},
+		{"[2001:db8::1]", 22, "[2001:db8::1]:22"},
+		{"::1", 2222, "[::1]:2222"},
+	}
+
+	for _, tt := range tests {
+		if got := dialAddress(tt.host, tt.port); got != tt.expected {
+			t.Errorf("dialAddress(%q, %d) = %q, want %q", tt.host, tt.port, got, tt.expected)
+		}
+	}
+}
+
+// startResettingProxy forwards connections to target after dropping the first
+// reset of them, like sshd that is still starting. Returns its port and a
+// function reporting how many connections it accepted.
+func startResettingProxy(t *testing.T, target string, reset int) (int, func() int) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if int(accepted.Add(1)) <= reset {
+				conn.Close()
+				continue
+			}
+			go func() {
+				defer conn.Close()
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				go func() { _, _ = io.Copy(upstream, conn) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+
+	_, portStr, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	return port, func() int { return int(accepted.Load()) }
+}
+
+// TestConnect_GivenResetConnections_ThenRetries tests retrying transient connection failures.
+func TestConnect_GivenResetConnections_ThenRetries(t *testing.T) {
+	server := startTestServer(t, func(ch ssh.Channel, command string) {
+		exitWith(ch, 0)
+	})
+	port, accepted := startResettingProxy(t, dialAddress(server.host, server.port), 2)
+
+	client := NewClientWithSigner(server.host, server.signer, WithPort(port), WithTimeout(5*time.Second))
+	client.connectDelay = time.Millisecond
+	t.Cleanup(func() { client.Close() })
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if got := accepted(); got != 3 {
+		t.Errorf("accepted %d connections, want 3", got)
+	}
+}
+
+// TestConnect_GivenPersistentResets_ThenReportsAttempts tests the error after the last attempt.
+func TestConnect_GivenPersistentResets_ThenReportsAttempts(t *testing.T) {
+	server := startTestServer(t, func(ch ssh.Channel, command string) {
+		exitWith(ch, 0)
+	})
+	port, accepted := startResettingProxy(t, dialAddress(server.host, server.port), 10)
+
+	client := NewClientWithSigner(server.host, server.signer, WithPort(port), WithTimeout(5*time.Second), WithConnectAttempts(2))
+	client.connectDelay = time.Millisecond
+
+	err := client.Connect()
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("Connect() error = %v, want attempts reported", err)
+	}
+	if got := accepted(); got != 2 {
+		t.Errorf("accepted %d connections, want 2", got)
+	}
+}
+
+// TestIsTransientConnectError_GivenErrors_ThenClassifies tests which dial errors are retried.
+func TestIsTransientConnectError_GivenErrors_ThenClassifies(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("ssh: handshake failed: %w", io.EOF), true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey], no supported methods remain"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isTransientConnectError(tt.err); got != tt.want {
+			t.Errorf("isTransientConnectError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sshexec
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -21,9 +22,12 @@ type ConsoleOptions struct {
 	Stderr io.Writer
 	// Shell is the shell to run (default: bash).
 	Shell string
+	// Command is run with a PTY instead of a login shell when set.
+	Command string
 }
 
 // Console opens an interactive terminal session.
+// If opts.Command is set, it runs that command with a PTY instead of a login shell.
 func (c *Client) Console(opts ConsoleOptions) error {
 	session, err := c.getSession()
 	if err != nil {
@@ -50,19 +54,79 @@ func (c *Client) Console(opts ConsoleOptions) error {
 	session.Stdout = opts.Stdout
 	session.Stderr = opts.Stderr
 
-	// Get terminal dimensions
 	fd := int(os.Stdin.Fd())
 	if !term.IsTerminal(fd) {
 		return fmt.Errorf("stdin is not a terminal")
 	}
 
-	width, height, err := term.GetSize(fd)
+	restore, err := requestPTY(session, fd)
 	if err != nil {
-		// Use reasonable defaults if we can't get size
-		width, height = 80, 24
+		return err
+	}
+	defer restore()
+
+	// Run the requested command, or start a login shell
+	if opts.Command != "" {
+		if err := session.Start(opts.Command); err != nil {
+			return fmt.Errorf("failed to start command: %w", err)
+		}
+	} else if err := session.Shell(); err != nil {
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+
+	// Wait for session to complete
+	return c.lostError(session.Wait())
+}
+
+// ExecPTY runs command with a pseudo-terminal, for tools that behave
+// differently without one, like progress bars, prompts, and full-screen
+// programs. Unlike Console, stdin doesn't have to be a terminal: if it is, the
+// PTY matches its size and it is put in raw mode until the command ends.
+// A non-zero exit status is returned as an *ssh.ExitError. If ctx is done
+// before the command finishes, the SSH session is closed and ctx.Err() is
+// returned.
+func (c *Client) ExecPTY(ctx context.Context, command string, stdin io.Reader, stdout io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	session, err := c.getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	// The PTY merges the command's stderr into stdout
+	session.Stdin = stdin
+	session.Stdout = stdout
+
+	fd := -1
+	if f, ok := stdin.(*os.File); ok {
+		fd = int(f.Fd())
+	}
+	restore, err := requestPTY(session, fd)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	return c.lostError(runContext(ctx, session, command))
+}
+
+// requestPTY requests a pseudo-terminal for session. If fd is a terminal, the
+// PTY matches its size and follows resizes, and the terminal is put in raw
+// mode; otherwise a default-sized PTY is requested. The returned function
+// restores the local terminal.
+func requestPTY(session *ssh.Session, fd int) (func(), error) {
+	isTerminal := term.IsTerminal(fd)
+
+	width, height := 80, 24
+	if isTerminal {
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
 	}
 
-	// Request pseudo-terminal
 	modes := ssh.TerminalModes{
 		ssh.ECHO:          1,     // Enable echo
 		ssh.TTY_OP_ISPEED: 14400, // Input speed
@@ -74,37 +138,55 @@ func (c *Client) Console(opts ConsoleOptions) error {
 		termType = "xterm-256color"
 	}
 
-	if ptyErr := session.RequestPty(termType, height, width, modes); ptyErr != nil {
-		return fmt.Errorf("failed to request PTY: %w", ptyErr)
+	if err := session.RequestPty(termType, height, width, modes); err != nil {
+		return nil, fmt.Errorf("failed to request PTY: %w", err)
+	}
+
+	if !isTerminal {
+		return func() {}, nil
 	}
 
 	// Set terminal to raw mode
-	oldState, rawErr := term.MakeRaw(fd)
-	if rawErr != nil {
-		return fmt.Errorf("failed to set raw mode: %w", rawErr)
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set raw mode: %w", err)
 	}
-	defer func() {
+
+	// Keep the remote PTY in sync with the local terminal size
+	stopResize := watchWindowSize(fd, session, width, height)
+
+	return func() {
+		stopResize()
 		_ = term.Restore(fd, oldState)
-	}()
+	}, nil
+}
 
-	// Handle window resize
+// watchWindowSize forwards local terminal resizes (SIGWINCH) to the remote
+// session as window-change requests. The returned function stops watching
+// and must be called before the session is closed.
+func watchWindowSize(fd int, session *ssh.Session, width, height int) func() {
 	sigwinch := make(chan os.Signal, 1)
 	signal.Notify(sigwinch, syscall.SIGWINCH)
+
+	done := make(chan struct{})
 	go func() {
-		for range sigwinch {
-			w, h, err := term.GetSize(fd)
-			if err == nil {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigwinch:
+				w, h, err := term.GetSize(fd)
+				if err != nil || (w == width && h == height) {
+					continue
+				}
+				width, height = w, h
 				_ = session.WindowChange(h, w)
 			}
 		}
 	}()
-	defer signal.Stop(sigwinch)
 
-	// Start shell
-	if err := session.Shell(); err != nil {
-		return fmt.Errorf("failed to start shell: %w", err)
+	return func() {
+		signal.Stop(sigwinch)
+		close(done)
 	}
-
-	// Wait for session to complete
-	return session.Wait()
 }
@@ -2,19 +2,34 @@ package sshexec
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // ExecResult contains the output from an executed command.
 type ExecResult struct {
-	Stdout   string
-	Stderr   string
-	ExitCode int
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
 }
 
-// Exec runs a command and returns the combined output.
+// Exec runs a command and returns its stdout. Stderr is only included in
+// the error if the command fails.
 func (c *Client) Exec(command string) (string, error) {
+	return c.ExecContext(context.Background(), command)
+}
+
+// ExecContext runs a command and returns its output. If ctx is done before
+// the command finishes, the SSH session is closed and ctx.Err() is returned.
+func (c *Client) ExecContext(ctx context.Context, command string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	session, err := c.getSession()
 	if err != nil {
 		return "", err
@@ -25,7 +40,10 @@ func (c *Client) Exec(command string) (string, error) {
 	session.Stdout = &stdout
 	session.Stderr = &stderr
 
-	if err := session.Run(command); err != nil {
+	if err := runContext(ctx, session, command); err != nil {
+		if ctx.Err() != nil {
+			return stdout.String(), err
+		}
 		// Include stderr in error message for debugging
 		if stderr.Len() > 0 {
 			return stdout.String(), fmt.Errorf("command failed: %w\nstderr: %s", err, stderr.String())
@@ -38,6 +56,19 @@ func (c *Client) Exec(command string) (string, error) {
 
 // ExecWithResult runs a command and returns detailed results.
 func (c *Client) ExecWithResult(command string) (*ExecResult, error) {
+	return c.ExecWithResultContext(context.Background(), command)
+}
+
+// ExecWithResultContext runs a command and returns its stdout and stderr
+// separately along with its exit code. A non-zero exit status is reported in
+// the result rather than as an error. If ctx is done before the command
+// finishes, the SSH session is closed and the output so far is returned with
+// ctx.Err().
+func (c *Client) ExecWithResultContext(ctx context.Context, command string) (*ExecResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	session, err := c.getSession()
 	if err != nil {
 		return nil, err
@@ -48,25 +79,36 @@ func (c *Client) ExecWithResult(command string) (*ExecResult, error) {
 	session.Stdout = &stdout
 	session.Stderr = &stderr
 
-	exitCode := 0
-	if err := session.Run(command); err != nil {
-		// Try to get exit code from error
-		if exitErr, ok := err.(*ExitError); ok {
-			exitCode = exitErr.ExitCode
-		} else {
-			return nil, fmt.Errorf("command failed: %w", err)
+	err = runContext(ctx, session, command)
+	result := &ExecResult{Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		var exitErr *ssh.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return result, err
+		case errors.As(err, &exitErr):
+			result.ExitCode = exitErr.ExitStatus()
+		default:
+			return nil, fmt.Errorf("command failed: %w", c.lostError(err))
 		}
 	}
-
-	return &ExecResult{
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		ExitCode: exitCode,
-	}, nil
+	return result, nil
 }
 
 // ExecWithStreams runs a command with custom I/O streams.
 func (c *Client) ExecWithStreams(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return c.ExecWithStreamsContext(context.Background(), command, stdin, stdout, stderr)
+}
+
+// ExecWithStreamsContext runs a command with custom I/O streams. If stdin is
+// non-nil it is forwarded to the command until EOF, which then closes the
+// command's stdin. If ctx is done before the command finishes, the SSH
+// session is closed and ctx.Err() is returned.
+func (c *Client) ExecWithStreamsContext(ctx context.Context, command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	session, err := c.getSession()
 	if err != nil {
 		return err
@@ -77,7 +119,22 @@ func (c *Client) ExecWithStreams(command string, stdin io.Reader, stdout, stderr
 	session.Stdout = stdout
 	session.Stderr = stderr
 
-	return session.Run(command)
+	return c.lostError(runContext(ctx, session, command))
+}
+
+// runContext runs command on session, closing the session if ctx is done
+// first. Closing the session makes the remote sshd hang up on the command.
+func runContext(ctx context.Context, session *ssh.Session, command string) error {
+	stop := context.AfterFunc(ctx, func() {
+		session.Close()
+	})
+
+	err := session.Run(command)
+	if !stop() {
+		// The session was closed because ctx is done
+		return ctx.Err()
+	}
+	return err
 }
 
 // ExitError represents a command that exited with a non-zero status.
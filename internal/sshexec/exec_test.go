@@ -0,0 +1,231 @@
+package sshexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestServer runs an SSH server on localhost that accepts any client key
+// and answers each exec request with handle. Commands run with a PTY are
+// passed to handle prefixed with "pty: ". Returns a client for it.
+func startTestServer(t *testing.T, handle func(ch ssh.Channel, command string)) *Client {
+	t.Helper()
+	return startTestServerWithSFTP(t, handle, false)
+}
+
+// startTestServerWithSFTP is like startTestServer, additionally serving the
+// sftp subsystem against the local filesystem if withSFTP is set.
+func startTestServerWithSFTP(t *testing.T, handle func(ch ssh.Channel, command string), withSFTP bool) *Client {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+
+	serverCfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	serverCfg.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestConn(conn, serverCfg, handle, withSFTP)
+		}
+	}()
+
+	_, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	clientSigner, err := ssh.NewSignerFromKey(clientPriv)
+	if err != nil {
+		t.Fatalf("failed to create client signer: %v", err)
+	}
+
+	host, portStr, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	client := NewClientWithSigner(host, clientSigner, WithPort(port), WithTimeout(5*time.Second), WithConnectAttempts(1))
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// serveTestConn handles the session channels of a single test connection.
+func serveTestConn(conn net.Conn, cfg *ssh.ServerConfig, handle func(ch ssh.Channel, command string), withSFTP bool) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newCh := range chans {
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			var pty bool
+			for req := range chReqs {
+				var payload struct{ Command string }
+				_ = ssh.Unmarshal(req.Payload, &payload)
+
+				switch {
+				case req.Type == "pty-req":
+					pty = true
+					req.Reply(true, nil)
+				case req.Type == "exec":
+					req.Reply(true, nil)
+					if pty {
+						payload.Command = "pty: " + payload.Command
+					}
+					go handle(ch, payload.Command)
+				case req.Type == "subsystem" && payload.Command == "sftp" && withSFTP:
+					req.Reply(true, nil)
+					go serveTestSFTP(ch)
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+// exitWith sends an exit status and closes the channel.
+func exitWith(ch ssh.Channel, code uint32) {
+	_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{code}))
+	ch.Close()
+}
+
+// TestExecContext_GivenCompletingCommand_ThenReturnsOutput tests normal execution.
+func TestExecContext_GivenCompletingCommand_ThenReturnsOutput(t *testing.T) {
+	client := startTestServer(t, func(ch ssh.Channel, command string) {
+		_, _ = ch.Write([]byte("ran: " + command))
+		exitWith(ch, 0)
+	})
+
+	output, err := client.ExecContext(context.Background(), "echo hi")
+	if err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	if output != "ran: echo hi" {
+		t.Errorf("ExecContext() = %q, want %q", output, "ran: echo hi")
+	}
+}
+
+// TestExecContext_GivenHungCommand_ThenReturnsContextError tests cancellation.
+func TestExecContext_GivenHungCommand_ThenReturnsContextError(t *testing.T) {
+	client := startTestServer(t, func(ch ssh.Channel, command string) {
+		// Never exits
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.ExecContext(ctx, "sleep infinity")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ExecContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ExecContext() took %v after the deadline", elapsed)
+	}
+}
+
+// TestExecWithResultContext_GivenFailingCommand_ThenSeparatesStreamsAndExitCode tests structured results.
+func TestExecWithResultContext_GivenFailingCommand_ThenSeparatesStreamsAndExitCode(t *testing.T) {
+	client := startTestServer(t, func(ch ssh.Channel, command string) {
+		_, _ = ch.Write([]byte("out\n"))
+		_, _ = ch.Stderr().Write([]byte("err\n"))
+		exitWith(ch, 3)
+	})
+
+	result, err := client.ExecWithResultContext(context.Background(), "false")
+	if err != nil {
+		t.Fatalf("ExecWithResultContext() error = %v", err)
+	}
+	want := ExecResult{Stdout: "out\n", Stderr: "err\n", ExitCode: 3}
+	if *result != want {
+		t.Errorf("ExecWithResultContext() = %+v, want %+v", *result, want)
+	}
+}
+
+// TestExecWithStreamsContext_GivenCanceledContext_ThenDoesNotConnect tests the early check.
+func TestExecWithStreamsContext_GivenCanceledContext_ThenDoesNotConnect(t *testing.T) {
+	// Nothing listens on this client's address
+	client := NewClientWithSigner("127.0.0.1", nil, WithPort(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := client.ExecWithStreamsContext(ctx, "true", nil, nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExecWithStreamsContext() error = %v, want %v", err, context.Canceled)
+	}
+	if client.IsConnected() {
+		t.Error("client should not connect with a canceled context")
+	}
+}
+
+// TestExecWithStreamsContext_GivenStdin_ThenForwardsUntilEOF tests stdin forwarding.
+func TestExecWithStreamsContext_GivenStdin_ThenForwardsUntilEOF(t *testing.T) {
+	client := startTestServer(t, func(ch ssh.Channel, command string) {
+		// Like cat: only finishes once the client closes stdin
+		_, _ = io.Copy(ch, ch)
+		exitWith(ch, 0)
+	})
+
+	var stdout bytes.Buffer
+	err := client.ExecWithStreamsContext(context.Background(), "cat", strings.NewReader("hi\nthere\n"), &stdout, io.Discard)
+	if err != nil {
+		t.Fatalf("ExecWithStreamsContext() error = %v", err)
+	}
+	if stdout.String() != "hi\nthere\n" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "hi\nthere\n")
+	}
+}
+
+// TestExecPTY_GivenCommand_ThenRequestsPTYAndReturnsExitStatus tests one-shot PTY commands.
+func TestExecPTY_GivenCommand_ThenRequestsPTYAndReturnsExitStatus(t *testing.T) {
+	client := startTestServer(t, func(ch ssh.Channel, command string) {
+		_, _ = ch.Write([]byte("ran: " + command))
+		exitWith(ch, 3)
+	})
+
+	var stdout bytes.Buffer
+	err := client.ExecPTY(context.Background(), "htop", strings.NewReader(""), &stdout)
+
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 {
+		t.Fatalf("ExecPTY() error = %v, want exit status 3", err)
+	}
+	if stdout.String() != "ran: pty: htop" {
+		t.Errorf("stdout = %q, want %q", stdout.String(), "ran: pty: htop")
+	}
+}
@@ -0,0 +1,109 @@
+package sshexec
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultKeepAliveInterval is how often an idle connection is probed.
+	defaultKeepAliveInterval = 30 * time.Second
+
+	// keepAliveMaxMissed is how many keep-alives in a row may go unanswered
+	// before the connection is considered dead.
+	keepAliveMaxMissed = 3
+
+	// keepAliveRequest is the global request OpenSSH clients send. Servers
+	// reply to unknown requests with a failure, which still proves the
+	// connection is alive.
+	keepAliveRequest = "keepalive@openssh.com"
+)
+
+// ErrConnectionLost is returned when the server stops answering keep-alives.
+var ErrConnectionLost = errors.New("connection lost")
+
+// requestSender sends global requests on an SSH connection.
+type requestSender interface {
+	SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error)
+}
+
+// keepAlive sends a keep-alive request on conn every interval. If maxMissed
+// requests in a row fail or get no reply within the interval, onLost is
+// called and no more keep-alives are sent. The returned function stops the
+// keep-alives; it may be called more than once.
+func keepAlive(conn requestSender, interval time.Duration, maxMissed int, onLost func()) func() {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		missed := 0
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			replied := make(chan error, 1)
+			go func() {
+				_, _, err := conn.SendRequest(keepAliveRequest, true, nil)
+				replied <- err
+			}()
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case err := <-replied:
+				timer.Stop()
+				if err == nil {
+					missed = 0
+					continue
+				}
+				missed++
+			case <-timer.C:
+				missed++
+			}
+
+			if missed >= maxMissed {
+				onLost()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// startKeepAlive probes the current connection in the background. A dead
+// connection is closed, so commands and consoles on it return instead of
+// hanging, and lostError reports why.
+func (c *Client) startKeepAlive() {
+	if c.keepAliveInterval <= 0 {
+		return
+	}
+
+	c.lost.Store(false)
+	sshClient := c.sshClient
+	c.stopKeepAlive = keepAlive(sshClient, c.keepAliveInterval, keepAliveMaxMissed, func() {
+		c.lost.Store(true)
+		sshClient.Close()
+	})
+}
+
+// lostError returns an ErrConnectionLost error in place of err if the
+// connection was closed because it stopped answering keep-alives.
+func (c *Client) lostError(err error) error {
+	if err == nil || !c.lost.Load() {
+		return err
+	}
+	return fmt.Errorf("%w: %s stopped responding to %d keep-alives", ErrConnectionLost, c.host, keepAliveMaxMissed)
+}
@@ -0,0 +1,78 @@
+package sshexec
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeSender answers keep-alive requests with reply, or never if hang is set.
+type fakeSender struct {
+	hang  bool
+	reply error
+}
+
+func (f *fakeSender) SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error) {
+	if f.hang {
+		select {}
+	}
+	return false, nil, f.reply
+}
+
+// TestKeepAlive_GivenUnresponsiveServer_ThenReportsLost tests dead connection detection.
+func TestKeepAlive_GivenUnresponsiveServer_ThenReportsLost(t *testing.T) {
+	tests := []struct {
+		name   string
+		sender *fakeSender
+	}{
+		{"no reply", &fakeSender{hang: true}},
+		{"send fails", &fakeSender{reply: errors.New("connection closed")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lost := make(chan struct{})
+			stop := keepAlive(tt.sender, 5*time.Millisecond, 3, func() { close(lost) })
+			defer stop()
+
+			select {
+			case <-lost:
+			case <-time.After(5 * time.Second):
+				t.Fatal("keepAlive() did not report the connection lost")
+			}
+		})
+	}
+}
+
+// TestKeepAlive_GivenReplies_ThenKeepsConnection tests that answered keep-alives are not lost.
+func TestKeepAlive_GivenReplies_ThenKeepsConnection(t *testing.T) {
+	lost := make(chan struct{})
+	stop := keepAlive(&fakeSender{}, time.Millisecond, 2, func() { close(lost) })
+
+	select {
+	case <-lost:
+		t.Fatal("keepAlive() reported a live connection as lost")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stop()
+	stop()
+}
+
+// TestLostError_GivenLostConnection_ThenWrapsErrConnectionLost tests the reported error.
+func TestLostError_GivenLostConnection_ThenWrapsErrConnectionLost(t *testing.T) {
+	c := NewClientWithSigner("203.0.113.10", nil)
+	waitErr := errors.New("wait: remote command exited without exit status")
+
+	if err := c.lostError(waitErr); err != waitErr {
+		t.Errorf("lostError() = %v, want the original error", err)
+	}
+
+	c.lost.Store(true)
+	if err := c.lostError(waitErr); !errors.Is(err, ErrConnectionLost) {
+		t.Errorf("lostError() = %v, want %v", err, ErrConnectionLost)
+	}
+	if err := c.lostError(nil); err != nil {
+		t.Errorf("lostError(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,175 @@
+package sshexec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/sandctl/sandctl/internal/datadir"
+)
+
+// knownHostsMu serializes reads and writes of known_hosts files within the process.
+var knownHostsMu sync.Mutex
+
+// DefaultKnownHostsPath returns the default known_hosts path (known_hosts in
+// the data directory).
+func DefaultKnownHostsPath() string {
+	return datadir.Path("known_hosts")
+}
+
+// HostKeyMismatchError is returned when a host presents a different key than
+// the one recorded on first connect.
+type HostKeyMismatchError struct {
+	Host     string
+	Got      string
+	Expected []string
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf(
+		"host key mismatch for %s: got %s, expected %s. If the VM was recreated, run 'sandctl forget %s'",
+		e.Host, e.Got, strings.Join(e.Expected, ", "), e.Host,
+	)
+}
+
+// TOFUHostKeyCallback returns a host key callback that implements trust on
+// first use. Unknown hosts have their key recorded in the known_hosts file at
+// path; known hosts must present the recorded key.
+func TOFUHostKeyCallback(path string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		knownHostsMu.Lock()
+		defer knownHostsMu.Unlock()
+
+		if _, err := os.Stat(path); err == nil {
+			check, err := knownhosts.New(path)
+			if err != nil {
+				return fmt.Errorf("failed to read known hosts: %w", err)
+			}
+
+			err = check(hostname, remote, key)
+			if err == nil {
+				return nil
+			}
+
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) {
+				return err
+			}
+			if len(keyErr.Want) > 0 {
+				expected := make([]string, len(keyErr.Want))
+				for i, want := range keyErr.Want {
+					expected[i] = ssh.FingerprintSHA256(want.Key)
+				}
+				return &HostKeyMismatchError{
+					Host:     hostOnly(hostname),
+					Got:      ssh.FingerprintSHA256(key),
+					Expected: expected,
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat known hosts: %w", err)
+		}
+
+		// Unknown host: trust and record the key
+		return appendKnownHost(path, hostname, key)
+	}
+}
+
+// appendKnownHost records a host key in the known_hosts file.
+func appendKnownHost(path, hostname string, key ssh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create known hosts directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open known hosts: %w", err)
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return fmt.Errorf("failed to write known hosts: %w", err)
+	}
+	return nil
+}
+
+// ForgetHost removes all known_hosts entries for host (an IP or hostname,
+// optionally with a port). Returns true if any entries were removed.
+func ForgetHost(path, host string) (bool, error) {
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	var kept []string
+	removed := false
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) > 0 && matchesHost(fields[0], host) {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to parse known hosts: %w", err)
+	}
+
+	if !removed {
+		return false, nil
+	}
+
+	content := strings.Join(kept, "\n")
+	if len(kept) > 0 {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return false, fmt.Errorf("failed to write known hosts: %w", err)
+	}
+	return true, nil
+}
+
+// matchesHost reports whether a comma-separated known_hosts host field contains host.
+// A host without a port matches entries for any port.
+func matchesHost(field, host string) bool {
+	_, _, err := net.SplitHostPort(host)
+	hasPort := err == nil
+	target := knownhosts.Normalize(host)
+
+	for _, h := range strings.Split(field, ",") {
+		if h == target {
+			return true
+		}
+		if !hasPort && strings.HasPrefix(h, "[") && hostOnly(h) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOnly strips the port from a host:port address.
+func hostOnly(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,123 @@
+package sshexec
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("failed to create public key: %v", err)
+	}
+	return key
+}
+
+// TestTOFUHostKeyCallback_GivenUnknownHost_ThenRecordsAndAccepts tests first-use trust.
+func TestTOFUHostKeyCallback_GivenUnknownHost_ThenRecordsAndAccepts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	callback := TOFUHostKeyCallback(path)
+	key := newTestHostKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("203.0.113.10"), Port: 22}
+
+	if err := callback("203.0.113.10:22", remote, key); err != nil {
+		t.Fatalf("first connect error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("known_hosts not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "203.0.113.10 ") {
+		t.Errorf("known_hosts = %q, want entry for 203.0.113.10", string(data))
+	}
+
+	// Same key is accepted on subsequent connects
+	if err := callback("203.0.113.10:22", remote, key); err != nil {
+		t.Errorf("second connect error = %v", err)
+	}
+}
+
+// TestTOFUHostKeyCallback_GivenChangedKey_ThenReturnsMismatchError tests key mismatch detection.
+func TestTOFUHostKeyCallback_GivenChangedKey_ThenReturnsMismatchError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	callback := TOFUHostKeyCallback(path)
+	remote := &net.TCPAddr{IP: net.ParseIP("203.0.113.10"), Port: 22}
+
+	if err := callback("203.0.113.10:22", remote, newTestHostKey(t)); err != nil {
+		t.Fatalf("first connect error = %v", err)
+	}
+
+	err := callback("203.0.113.10:22", remote, newTestHostKey(t))
+	var mismatch *HostKeyMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("error = %v, want HostKeyMismatchError", err)
+	}
+	if mismatch.Host != "203.0.113.10" {
+		t.Errorf("Host = %q, want %q", mismatch.Host, "203.0.113.10")
+	}
+	if !strings.Contains(err.Error(), "sandctl forget 203.0.113.10") {
+		t.Errorf("error = %q, want forget hint", err.Error())
+	}
+}
+
+// TestForgetHost_GivenStoredHost_ThenRemovesOnlyThatHost tests forgetting entries.
+func TestForgetHost_GivenStoredHost_ThenRemovesOnlyThatHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	callback := TOFUHostKeyCallback(path)
+
+	hosts := []string{"203.0.113.10:22", "203.0.113.10:2222", "203.0.113.11:22"}
+	for _, host := range hosts {
+		addr, _ := net.ResolveTCPAddr("tcp", host)
+		if err := callback(host, addr, newTestHostKey(t)); err != nil {
+			t.Fatalf("connect %s error = %v", host, err)
+		}
+	}
+
+	removed, err := ForgetHost(path, "203.0.113.10")
+	if err != nil {
+		t.Fatalf("ForgetHost() error = %v", err)
+	}
+	if !removed {
+		t.Error("expected entries to be removed")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "203.0.113.10") {
+		t.Errorf("known_hosts still contains forgotten host: %q", string(data))
+	}
+	if !strings.Contains(string(data), "203.0.113.11") {
+		t.Errorf("known_hosts lost unrelated host: %q", string(data))
+	}
+
+	// Forgetting again is a no-op
+	removed, err = ForgetHost(path, "203.0.113.10")
+	if err != nil || removed {
+		t.Errorf("ForgetHost() again = %v, %v; want false, nil", removed, err)
+	}
+}
+
+// TestForgetHost_GivenMissingFile_ThenReturnsFalse tests missing known_hosts.
+func TestForgetHost_GivenMissingFile_ThenReturnsFalse(t *testing.T) {
+	removed, err := ForgetHost(filepath.Join(t.TempDir(), "missing"), "203.0.113.10")
+	if err != nil || removed {
+		t.Errorf("ForgetHost() = %v, %v; want false, nil", removed, err)
+	}
+}
@@ -0,0 +1,189 @@
+package sshexec
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// dial connects to addr, going through the ProxyCommand or ProxyJump hosts
+// configured for the target in ~/.ssh/config when WithSSHConfig is set.
+func (c *Client) dial(hostCfg sshConfigHost, port int, config *ssh.ClientConfig) (*ssh.Client, error) {
+	addr := dialAddress(c.host, port)
+
+	switch {
+	case hostCfg.ProxyCommand != "":
+		command := expandProxyTokens(hostCfg.ProxyCommand, c.host, port, config.User)
+		conn, err := dialProxyCommand(command)
+		if err != nil {
+			return nil, err
+		}
+		return newClientConn(conn, addr, config)
+
+	case hostCfg.ProxyJump != "":
+		var via *ssh.Client
+		for _, hop := range strings.Split(hostCfg.ProxyJump, ",") {
+			hopAddr, hopConfig := jumpHostConfig(hop, config)
+			next, err := dialVia(via, hopAddr, hopConfig)
+			if err != nil {
+				c.closeJumps()
+				return nil, fmt.Errorf("failed to connect to jump host %s: %w", hopAddr, err)
+			}
+			c.jumps = append(c.jumps, next)
+			via = next
+		}
+		client, err := dialVia(via, addr, config)
+		if err != nil {
+			c.closeJumps()
+			return nil, err
+		}
+		return client, nil
+
+	default:
+		return ssh.Dial("tcp", addr, config)
+	}
+}
+
+// closeJumps closes the connections to jump hosts, innermost first.
+func (c *Client) closeJumps() {
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		c.jumps[i].Close()
+	}
+	c.jumps = nil
+}
+
+// dialVia opens an SSH connection to addr, tunneled through via if it's set.
+func dialVia(via *ssh.Client, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if via == nil {
+		return ssh.Dial("tcp", addr, config)
+	}
+	conn, err := via.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return newClientConn(conn, addr, config)
+}
+
+// newClientConn starts an SSH client over an established connection.
+func newClientConn(conn net.Conn, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// jumpHostConfig returns the address and client config for a ProxyJump hop
+// of the form [user@]host[:port]. The hop's own ~/.ssh/config entry supplies
+// its HostName, User, and Port; authentication reuses the target's key and
+// host key checking.
+func jumpHostConfig(hop string, target *ssh.ClientConfig) (string, *ssh.ClientConfig) {
+	hop = strings.TrimSpace(hop)
+
+	var user string
+	if at := strings.LastIndex(hop, "@"); at >= 0 {
+		user, hop = hop[:at], hop[at+1:]
+	}
+
+	host, port := hop, 0
+	if h, p, err := net.SplitHostPort(hop); err == nil {
+		host = h
+		port, _ = strconv.Atoi(p)
+	}
+
+	hopCfg := lookupSSHConfig(host)
+	if hopCfg.HostName != "" {
+		host = hopCfg.HostName
+	}
+	if port == 0 {
+		port = hopCfg.Port
+	}
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	if user == "" {
+		user = hopCfg.User
+	}
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	return dialAddress(host, port), &ssh.ClientConfig{
+		User:            user,
+		Auth:            target.Auth,
+		HostKeyCallback: target.HostKeyCallback,
+		Timeout:         target.Timeout,
+	}
+}
+
+// expandProxyTokens replaces the %h, %p, %r, and %% tokens in a ProxyCommand.
+func expandProxyTokens(command, host string, port int, user string) string {
+	return strings.NewReplacer(
+		"%%", "%",
+		"%h", host,
+		"%p", strconv.Itoa(port),
+		"%r", user,
+	).Replace(command)
+}
+
+// dialProxyCommand starts command with sh and returns a connection that
+// reads from its stdout and writes to its stdin.
+func dialProxyCommand(command string) (net.Conn, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ProxyCommand: %w", err)
+	}
+
+	return &proxyCommandConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+// proxyCommandConn is a net.Conn over a ProxyCommand's stdin and stdout.
+type proxyCommandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (p *proxyCommandConn) Read(b []byte) (int, error)  { return p.stdout.Read(b) }
+func (p *proxyCommandConn) Write(b []byte) (int, error) { return p.stdin.Write(b) }
+
+// Close closes the pipes and stops the command.
+func (p *proxyCommandConn) Close() error {
+	p.stdin.Close()
+	p.stdout.Close()
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+	_ = p.cmd.Wait()
+	return nil
+}
+
+func (p *proxyCommandConn) LocalAddr() net.Addr                { return proxyAddr{} }
+func (p *proxyCommandConn) RemoteAddr() net.Addr               { return proxyAddr{} }
+func (p *proxyCommandConn) SetDeadline(t time.Time) error      { return nil }
+func (p *proxyCommandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (p *proxyCommandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// proxyAddr is the placeholder address of a ProxyCommand connection.
+type proxyAddr struct{}
+
+func (proxyAddr) Network() string { return "proxy" }
+func (proxyAddr) String() string  { return "proxy-command" }
@@ -0,0 +1,47 @@
+package sshexec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envNamePattern matches valid POSIX environment variable names.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ShellQuote quotes a string for safe use as a single word in a POSIX shell.
+// The value is wrapped in single quotes, with embedded single quotes escaped.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ValidEnvName returns true if name is a valid environment variable name.
+func ValidEnvName(name string) bool {
+	return envNamePattern.MatchString(name)
+}
+
+// ExportEnv builds a shell statement exporting the given variables.
+// Keys are sorted for deterministic output and values are shell-quoted.
+// Returns an empty string if env is empty.
+func ExportEnv(env map[string]string) (string, error) {
+	if len(env) == 0 {
+		return "", nil
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		if !ValidEnvName(k) {
+			return "", fmt.Errorf("invalid environment variable name: %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+ShellQuote(env[k]))
+	}
+
+	return "export " + strings.Join(parts, " ") + ";", nil
+}
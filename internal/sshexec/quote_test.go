@@ -0,0 +1,70 @@
+package sshexec
+
+import (
+	"testing"
+)
+
+// TestShellQuote_GivenValues_ThenQuotesSafely tests shell quoting.
+func TestShellQuote_GivenValues_ThenQuotesSafely(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"simple", "'simple'"},
+		{"", "''"},
+		{"with space", "'with space'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf /)", "'$(rm -rf /)'"},
+		{`"double"`, `'"double"'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ShellQuote(tt.input); got != tt.expected {
+				t.Errorf("ShellQuote(%q) = %s, want %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExportEnv_GivenVariables_ThenBuildsSortedExport tests export statement building.
+func TestExportEnv_GivenVariables_ThenBuildsSortedExport(t *testing.T) {
+	env := map[string]string{
+		"ZED":   "last",
+		"ALPHA": "it's here",
+	}
+
+	got, err := ExportEnv(env)
+	if err != nil {
+		t.Fatalf("ExportEnv() error = %v", err)
+	}
+
+	expected := `export ALPHA='it'\''s here' ZED='last';`
+	if got != expected {
+		t.Errorf("ExportEnv() = %s, want %s", got, expected)
+	}
+}
+
+// TestExportEnv_GivenEmpty_ThenReturnsEmptyString tests empty env.
+func TestExportEnv_GivenEmpty_ThenReturnsEmptyString(t *testing.T) {
+	got, err := ExportEnv(nil)
+	if err != nil {
+		t.Fatalf("ExportEnv() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("ExportEnv(nil) = %q, want empty", got)
+	}
+}
+
+// TestExportEnv_GivenInvalidName_ThenReturnsError tests name validation.
+func TestExportEnv_GivenInvalidName_ThenReturnsError(t *testing.T) {
+	invalid := []string{"1ABC", "A-B", "A B", "A;rm", ""}
+
+	for _, name := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ExportEnv(map[string]string{name: "v"}); err == nil {
+				t.Errorf("expected error for variable name %q", name)
+			}
+		})
+	}
+}
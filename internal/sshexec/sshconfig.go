@@ -0,0 +1,131 @@
+package sshexec
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sshConfigHost holds the ~/.ssh/config settings applied to a connection.
+type sshConfigHost struct {
+	HostName     string
+	User         string
+	Port         int
+	ProxyJump    string
+	ProxyCommand string
+}
+
+// lookupSSHConfig returns the ~/.ssh/config settings that apply to host.
+func lookupSSHConfig(host string) sshConfigHost {
+	values := sshConfigValues(readSSHConfig(), host)
+
+	cfg := sshConfigHost{
+		HostName:     values["hostname"],
+		User:         values["user"],
+		ProxyJump:    values["proxyjump"],
+		ProxyCommand: values["proxycommand"],
+	}
+	if port, err := strconv.Atoi(values["port"]); err == nil {
+		cfg.Port = port
+	}
+
+	// "none" disables a proxy set by a broader Host block
+	if strings.EqualFold(cfg.ProxyJump, "none") {
+		cfg.ProxyJump = ""
+	}
+	if strings.EqualFold(cfg.ProxyCommand, "none") {
+		cfg.ProxyCommand = ""
+	}
+	return cfg
+}
+
+// readSSHConfig returns the contents of ~/.ssh/config, or nil if it can't be read.
+func readSSHConfig() []byte {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// sshConfigValues returns the settings in an ssh_config file that apply to
+// host, keyed by lowercase keyword. As with ssh, the first value found for a
+// keyword wins, and ProxyJump and ProxyCommand exclude each other. Match
+// blocks and Include directives are not supported and are skipped.
+func sshConfigValues(data []byte, host string) map[string]string {
+	values := make(map[string]string)
+	matching := true // Settings before the first Host line apply to every host
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		// Skip comments and empty lines
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value := splitSSHConfigLine(line)
+		switch keyword {
+		case "host":
+			matching = matchHostPatterns(strings.Fields(value), host)
+			continue
+		case "match":
+			matching = false
+			continue
+		}
+
+		if !matching || value == "" {
+			continue
+		}
+		if _, ok := values[keyword]; ok {
+			continue
+		}
+		if keyword == "proxyjump" || keyword == "proxycommand" {
+			if values["proxyjump"] != "" || values["proxycommand"] != "" {
+				continue
+			}
+		}
+		values[keyword] = strings.Trim(value, "\"'")
+	}
+
+	return values
+}
+
+// splitSSHConfigLine splits a line into its lowercase keyword and value.
+// Both "Keyword value" and "Keyword=value" forms are accepted.
+func splitSSHConfigLine(line string) (string, string) {
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), ""
+	}
+	keyword := strings.ToLower(line[:end])
+	value := strings.TrimLeft(line[end:], " \t")
+	value = strings.TrimPrefix(value, "=")
+	return keyword, strings.TrimSpace(value)
+}
+
+// matchHostPatterns reports whether host matches a Host line's patterns.
+// A matching negated pattern ("!pattern") rules the host out.
+func matchHostPatterns(patterns []string, host string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(host))
+		if err != nil || !ok {
+			continue
+		}
+		if negated {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
@@ -0,0 +1,94 @@
+package sshexec
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const testSSHConfig = `# Global settings
+ServerAliveInterval 30
+
+Host bastion
+    HostName bastion.example.com
+    User jump
+    Port 2200
+
+Host 10.0.* !10.0.0.1
+    ProxyJump bastion
+    ProxyCommand nc %h %p
+
+Host 203.0.113.*
+    Port=2222
+    ProxyCommand "ssh -W %h:%p gateway"
+
+Match host 203.0.113.5
+    Port 9999
+
+Host *
+    IdentityAgent ~/agent.sock
+    Port 22
+`
+
+// TestSSHConfigValues_GivenHost_ThenAppliesMatchingBlocks tests ssh_config lookup.
+func TestSSHConfigValues_GivenHost_ThenAppliesMatchingBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		keyword string
+		want    string
+	}{
+		{"global before host blocks", "10.0.1.5", "serveraliveinterval", "30"},
+		{"proxy jump from pattern", "10.0.1.5", "proxyjump", "bastion"},
+		{"proxy command excluded by earlier jump", "10.0.1.5", "proxycommand", ""},
+		{"negated pattern", "10.0.0.1", "proxyjump", ""},
+		{"first value wins", "203.0.113.5", "port", "2222"},
+		{"equals syntax and quotes", "203.0.113.5", "proxycommand", "ssh -W %h:%p gateway"},
+		{"wildcard fallback", "198.51.100.1", "port", "22"},
+		{"case-insensitive host", "BASTION", "hostname", "bastion.example.com"},
+		{"identity agent", "*", "identityagent", "~/agent.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := sshConfigValues([]byte(testSSHConfig), tt.host)
+			if got := values[tt.keyword]; got != tt.want {
+				t.Errorf("sshConfigValues(%q)[%q] = %q, want %q", tt.host, tt.keyword, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExpandProxyTokens_GivenTokens_ThenSubstitutes tests ProxyCommand expansion.
+func TestExpandProxyTokens_GivenTokens_ThenSubstitutes(t *testing.T) {
+	got := expandProxyTokens("ssh -W %h:%p %r@gw 100%%", "10.0.1.5", 22, "agent")
+	want := "ssh -W 10.0.1.5:22 agent@gw 100%"
+	if got != want {
+		t.Errorf("expandProxyTokens() = %q, want %q", got, want)
+	}
+}
+
+// TestJumpHostConfig_GivenHopSpec_ThenParsesUserHostAndPort tests ProxyJump hop parsing.
+func TestJumpHostConfig_GivenHopSpec_ThenParsesUserHostAndPort(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USER", "local")
+
+	target := &ssh.ClientConfig{User: "agent"}
+
+	tests := []struct {
+		hop      string
+		wantAddr string
+		wantUser string
+	}{
+		{"ops@gw.example.com:2200", "gw.example.com:2200", "ops"},
+		{"gw.example.com", "gw.example.com:22", "local"},
+		{" [2001:db8::1]:2200", "[2001:db8::1]:2200", "local"},
+	}
+
+	for _, tt := range tests {
+		addr, cfg := jumpHostConfig(tt.hop, target)
+		if addr != tt.wantAddr || cfg.User != tt.wantUser {
+			t.Errorf("jumpHostConfig(%q) = %s as %s, want %s as %s", tt.hop, addr, cfg.User, tt.wantAddr, tt.wantUser)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package sshexec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/sftp"
+)
+
+// transferChunkSize is the size of each write during an SFTP transfer, and so
+// how often progress is reported.
+const transferChunkSize = 32 * 1024
+
+// TransferOption configures a file transfer.
+type TransferOption func(*transferOptions)
+
+type transferOptions struct {
+	progress io.Writer
+}
+
+// WithTransferProgress copies each chunk to w after it has been written to
+// the VM, so w sees the transfer's progress in bytes.
+func WithTransferProgress(w io.Writer) TransferOption {
+	return func(o *transferOptions) {
+		o.progress = w
+	}
+}
+
+// TransferFile writes content to remotePath on the VM with the given mode.
+// The file is streamed over SFTP in chunks. If the server has no SFTP
+// subsystem, the content is piped base64-encoded to a shell command instead.
+func (c *Client) TransferFile(content []byte, remotePath string, mode os.FileMode, opts ...TransferOption) error {
+	var o transferOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := c.Connect(); err != nil {
+		return err
+	}
+
+	sftpClient, err := sftp.NewClient(c.sshClient)
+	if err != nil {
+		// No SFTP subsystem on the server
+		return c.transferFileShell(content, remotePath, mode, o.progress)
+	}
+	defer sftpClient.Close()
+
+	if err := transferFileSFTP(sftpClient, content, remotePath, mode, o.progress); err != nil {
+		return fmt.Errorf("failed to transfer %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// transferFileSFTP writes content to remotePath in chunks, reporting each one
+// to progress if it is non-nil.
+func transferFileSFTP(client *sftp.Client, content []byte, remotePath string, mode os.FileMode, progress io.Writer) error {
+	f, err := client.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Set the mode before writing so the content is never readable with
+	// looser permissions than requested
+	if err := f.Chmod(mode.Perm()); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(content); start += transferChunkSize {
+		chunk := content[start:min(start+transferChunkSize, len(content))]
+		if _, err := f.Write(chunk); err != nil {
+			return err
+		}
+		if progress != nil {
+			_, _ = progress.Write(chunk)
+		}
+	}
+
+	return f.Close()
+}
+
+// transferFileShell writes content to remotePath by streaming it
+// base64-encoded over stdin to a shell command, so it may contain arbitrary
+// bytes and isn't limited by the remote command line length.
+func (c *Client) transferFileShell(content []byte, remotePath string, mode os.FileMode, progress io.Writer) error {
+	var src io.Reader = bytes.NewReader(content)
+	if progress != nil {
+		src = io.TeeReader(src, progress)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		enc := base64.NewEncoder(base64.StdEncoding, pw)
+		_, err := io.Copy(enc, src)
+		if err == nil {
+			err = enc.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	path := ShellQuote(remotePath)
+	command := fmt.Sprintf("base64 -d > %s && chmod %o %s", path, mode.Perm(), path)
+
+	var stderr bytes.Buffer
+	if err := c.ExecWithStreams(command, pr, io.Discard, &stderr); err != nil {
+		pr.Close()
+		if stderr.Len() > 0 {
+			return fmt.Errorf("failed to transfer %s: %w\nstderr: %s", remotePath, err, stderr.String())
+		}
+		return fmt.Errorf("failed to transfer %s: %w", remotePath, err)
+	}
+	return nil
+}
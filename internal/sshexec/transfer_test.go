@@ -0,0 +1,87 @@
+package sshexec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// serveTestSFTP serves the sftp subsystem on ch against the local filesystem.
+func serveTestSFTP(ch ssh.Channel) {
+	defer ch.Close()
+	server, err := sftp.NewServer(ch)
+	if err != nil {
+		return
+	}
+	_ = server.Serve()
+}
+
+// TestTransferFile_GivenSFTPServer_ThenWritesFileWithProgress tests the SFTP path.
+func TestTransferFile_GivenSFTPServer_ThenWritesFileWithProgress(t *testing.T) {
+	client := startTestServerWithSFTP(t, func(ch ssh.Channel, command string) {
+		t.Errorf("unexpected exec request: %q", command)
+		exitWith(ch, 1)
+	}, true)
+
+	content := bytes.Repeat([]byte("sandctl\n"), 3*transferChunkSize/8+5)
+	remotePath := filepath.Join(t.TempDir(), "upload.sh")
+
+	var progress bytes.Buffer
+	if err := client.TransferFile(content, remotePath, 0700, WithTransferProgress(&progress)); err != nil {
+		t.Fatalf("TransferFile() error = %v", err)
+	}
+
+	got, err := os.ReadFile(remotePath)
+	if err != nil {
+		t.Fatalf("failed to read transferred file: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("transferred %d bytes, want %d", len(got), len(content))
+	}
+	if progress.Len() != len(content) {
+		t.Errorf("progress saw %d bytes, want %d", progress.Len(), len(content))
+	}
+
+	info, err := os.Stat(remotePath)
+	if err != nil {
+		t.Fatalf("failed to stat transferred file: %v", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("mode = %o, want %o", info.Mode().Perm(), 0700)
+	}
+}
+
+// TestTransferFile_GivenNoSFTP_ThenFallsBackToShell tests the base64 fallback.
+func TestTransferFile_GivenNoSFTP_ThenFallsBackToShell(t *testing.T) {
+	var command string
+	var received []byte
+	client := startTestServer(t, func(ch ssh.Channel, cmd string) {
+		command = cmd
+		received, _ = io.ReadAll(base64.NewDecoder(base64.StdEncoding, ch))
+		exitWith(ch, 0)
+	})
+
+	content := []byte("[user]\n\tname = Alice\n")
+
+	var progress bytes.Buffer
+	if err := client.TransferFile(content, "/home/agent/.gitconfig", 0644, WithTransferProgress(&progress)); err != nil {
+		t.Fatalf("TransferFile() error = %v", err)
+	}
+
+	if !strings.HasPrefix(command, "base64 -d > '/home/agent/.gitconfig'") || !strings.Contains(command, "chmod 644") {
+		t.Errorf("unexpected command: %q", command)
+	}
+	if !bytes.Equal(received, content) {
+		t.Errorf("received %q, want %q", received, content)
+	}
+	if progress.Len() != len(content) {
+		t.Errorf("progress saw %d bytes, want %d", progress.Len(), len(content))
+	}
+}
@@ -2,12 +2,17 @@ package templateconfig
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/sandctl/sandctl/internal/datadir"
+	"github.com/sandctl/sandctl/internal/envfile"
 )
 
 // Store manages template configuration storage.
@@ -16,13 +21,10 @@ type Store struct {
 	mu       sync.RWMutex
 }
 
-// DefaultTemplatesPath returns the default templates directory.
+// DefaultTemplatesPath returns the default templates directory (templates in
+// the data directory).
 func DefaultTemplatesPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ".sandctl/templates"
-	}
-	return filepath.Join(home, ".sandctl", "templates")
+	return datadir.Path("templates")
 }
 
 // NewStore creates a new template configuration store.
@@ -60,10 +62,13 @@ func (s *Store) Add(name string) (*TemplateConfig, error) {
 		return nil, fmt.Errorf("template name is required")
 	}
 
-	// Check if config already exists
+	// Names are compared after normalization, so "Foo/Bar" and "foo/bar"
+	// collide instead of sharing a directory
 	configPath := s.configPath(normalizedName)
-	if _, err := os.Stat(configPath); err == nil {
-		return nil, &AlreadyExistsError{Template: name}
+	if data, err := os.ReadFile(configPath); err == nil {
+		existing := TemplateConfig{OriginalName: name}
+		_ = yaml.Unmarshal(data, &existing)
+		return nil, &AlreadyExistsError{Template: name, Existing: existing.OriginalName}
 	}
 
 	// Create config
@@ -188,6 +193,15 @@ func (s *Store) Exists(name string) bool {
 	return err == nil
 }
 
+// HasInitScript checks if a template has an init script on disk.
+func (s *Store) HasInitScript(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := os.Stat(s.scriptPath(NormalizeName(name)))
+	return err == nil
+}
+
 // GetInitScript returns the content of a template's init script.
 func (s *Store) GetInitScript(name string) (string, error) {
 	s.mu.RLock()
@@ -222,6 +236,25 @@ func (s *Store) GetInitScriptPath(name string) (string, error) {
 	return scriptPath, nil
 }
 
+// ResolveEnv returns the variables to export before cfg's init script runs:
+// those read from its env_file, overridden by its env entries.
+func (s *Store) ResolveEnv(cfg *TemplateConfig) (map[string]string, error) {
+	env := make(map[string]string)
+	if cfg.EnvFile != "" {
+		path := cfg.EnvFile
+		if !strings.HasPrefix(path, "~/") && !filepath.IsAbs(path) {
+			path = filepath.Join(s.templateDir(cfg.Template), path)
+		}
+		fromFile, err := envfile.Read(path)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(env, fromFile)
+	}
+	maps.Copy(env, cfg.Env)
+	return env, nil
+}
+
 // NotFoundError is returned when a template doesn't exist.
 type NotFoundError struct {
 	Template string
@@ -232,10 +265,16 @@ func (e *NotFoundError) Error() string {
 }
 
 // AlreadyExistsError is returned when trying to create a template that already exists.
+// Existing is the name the template was created with, which may differ from
+// Template in case or punctuation.
 type AlreadyExistsError struct {
 	Template string
+	Existing string
 }
 
 func (e *AlreadyExistsError) Error() string {
+	if e.Existing != "" && e.Existing != e.Template {
+		return fmt.Sprintf("template '%s' conflicts with existing template '%s'", e.Template, e.Existing)
+	}
 	return fmt.Sprintf("template '%s' already exists", e.Template)
 }
@@ -0,0 +1,109 @@
+package templateconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestStore returns a store rooted in a temporary directory.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return &Store{basePath: t.TempDir()}
+}
+
+// TestNormalizeName_GivenNames_ThenNormalizesConsistently tests name normalization.
+func TestNormalizeName_GivenNames_ThenNormalizesConsistently(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ghost", "ghost"},
+		{"My API", "my-api"},
+		{"React/Vue", "react-vue"},
+		{"my--name", "my-name"},
+		{"  Foo/Bar  ", "foo-bar"},
+		{"foo/bar", "foo-bar"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestAdd_GivenNameDifferingInCase_ThenReturnsAlreadyExists tests case-insensitive collisions.
+func TestAdd_GivenNameDifferingInCase_ThenReturnsAlreadyExists(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := store.Add("Foo/Bar"); err != nil {
+		t.Fatalf("Add(Foo/Bar) error = %v", err)
+	}
+
+	_, err := store.Add("foo/bar")
+	var existsErr *AlreadyExistsError
+	if !errors.As(err, &existsErr) {
+		t.Fatalf("Add(foo/bar) error = %v, want AlreadyExistsError", err)
+	}
+	if existsErr.Existing != "Foo/Bar" {
+		t.Errorf("Existing = %q, want %q", existsErr.Existing, "Foo/Bar")
+	}
+
+	entries, err := os.ReadDir(store.basePath)
+	if err != nil {
+		t.Fatalf("failed to read templates directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("templates directory has %d entries, want 1", len(entries))
+	}
+}
+
+// TestGet_GivenDifferentCase_ThenFindsTemplate tests case-insensitive lookup.
+func TestGet_GivenDifferentCase_ThenFindsTemplate(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := store.Add("Foo/Bar"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	config, err := store.Get("FOO/BAR")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if config.OriginalName != "Foo/Bar" {
+		t.Errorf("OriginalName = %q, want %q", config.OriginalName, "Foo/Bar")
+	}
+	if !store.Exists(" foo/bar ") {
+		t.Error("Exists() = false, want true")
+	}
+}
+
+// TestResolveEnv_GivenRelativeEnvFile_ThenReadsFromTemplateDir tests env_file resolution.
+func TestResolveEnv_GivenRelativeEnvFile_ThenReadsFromTemplateDir(t *testing.T) {
+	store := newTestStore(t)
+
+	cfg, err := store.Add("Ghost")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(store.templateDir(cfg.Template), ".env"), []byte("DB=mysql\nPORT=2368\n"), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	cfg.EnvFile = ".env"
+	cfg.Env = map[string]string{"PORT": "8080"}
+
+	env, err := store.ResolveEnv(cfg)
+	if err != nil {
+		t.Fatalf("ResolveEnv() error = %v", err)
+	}
+	if env["DB"] != "mysql" || env["PORT"] != "8080" {
+		t.Errorf("ResolveEnv() = %v, want DB from the file and PORT from env", env)
+	}
+
+	cfg.EnvFile = "missing.env"
+	if _, err := store.ResolveEnv(cfg); err == nil {
+		t.Error("ResolveEnv() with a missing env_file should fail")
+	}
+}
@@ -11,6 +11,9 @@ const InitScriptTemplate = `#!/bin/bash
 #   SANDCTL_TEMPLATE_NAME       - Original template name
 #   SANDCTL_TEMPLATE_NORMALIZED - Normalized template name (lowercase)
 #
+# Additional variables can be set under 'env:' in this template's config.yaml,
+# or read from a .env file named by 'env_file:'.
+#
 # Examples:
 #   apt-get update && apt-get install -y nodejs npm
 #   git clone https://github.com/your/repo.git /home/agent/project
@@ -48,6 +48,14 @@ type TemplateConfig struct {
 
 	// Timeout is the custom timeout for init script execution (default: 10 minutes).
 	Timeout Duration `yaml:"timeout,omitempty"`
+
+	// Env holds extra environment variables exported before the init script runs.
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// EnvFile is a local .env file whose variables are also exported; Env
+	// entries take precedence. A relative path is relative to the template
+	// directory.
+	EnvFile string `yaml:"env_file,omitempty"`
 }
 
 // GetTimeout returns the timeout duration, using default if not set.
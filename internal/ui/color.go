@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape codes for colored status output.
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// colorEnabled controls ANSI colors, the spinner animation, and Unicode
+// status marks. When disabled, output is plain ASCII.
+var colorEnabled = true
+
+// SetColor enables or disables colored output.
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+}
+
+// ColorEnabled reports whether colored output is enabled.
+func ColorEnabled() bool {
+	return colorEnabled
+}
+
+// ShouldUseColor reports whether colored output suits out. It returns false
+// if noColor is set, the NO_COLOR environment variable is non-empty, or out
+// is not a terminal.
+func ShouldUseColor(out *os.File, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(out.Fd()))
+}
+
+// SuccessMark returns the mark printed before a successful step.
+func SuccessMark() string {
+	if !colorEnabled {
+		return "[ok]"
+	}
+	return colorize(ansiGreen, "✓")
+}
+
+// FailMark returns the mark printed before a failed step.
+func FailMark() string {
+	if !colorEnabled {
+		return "[fail]"
+	}
+	return colorize(ansiRed, "✗")
+}
+
+// colorize wraps s in the given color when color is enabled.
+func colorize(color, s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return color + s + ansiReset
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// disableColor turns color off for the duration of a test.
+func disableColor(t *testing.T) {
+	t.Helper()
+	SetColor(false)
+	t.Cleanup(func() { SetColor(true) })
+}
+
+// TestPrintFunctions_GivenColorDisabled_ThenWritesPlainASCII tests NO_COLOR output.
+func TestPrintFunctions_GivenColorDisabled_ThenWritesPlainASCII(t *testing.T) {
+	disableColor(t)
+
+	var buf bytes.Buffer
+	PrintSuccess(&buf, "created %s", "alice")
+	PrintError(&buf, "failed")
+	PrintWarning(&buf, "careful")
+	PrintInfo(&buf, "note")
+
+	want := "[ok] created alice\nError: failed\nWarning: careful\nnote\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+// TestRunSteps_GivenColorDisabled_ThenWritesNoEscapeCodes tests plain step progress.
+func TestRunSteps_GivenColorDisabled_ThenWritesNoEscapeCodes(t *testing.T) {
+	disableColor(t)
+
+	var buf bytes.Buffer
+	err := RunSteps(&buf, []ProgressStep{
+		{Message: "Provisioning VM", Action: func() error { return nil }},
+		{Message: "Waiting for setup", Action: func() error { return errors.New("timeout") }},
+	})
+	if err == nil {
+		t.Fatal("expected error from failing step")
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "\033") {
+		t.Errorf("output contains ANSI escape codes: %q", output)
+	}
+	want := "[ok] Provisioning VM\n[fail] Waiting for setup\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
+
+// TestPrintError_GivenColorEnabled_ThenColorsPrefix tests colored output.
+func TestPrintError_GivenColorEnabled_ThenColorsPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	PrintError(&buf, "failed")
+
+	if !strings.Contains(buf.String(), ansiRed+"Error:"+ansiReset) {
+		t.Errorf("output = %q, want red Error: prefix", buf.String())
+	}
+}
+
+// TestShouldUseColor_GivenNoColor_ThenReturnsFalse tests the NO_COLOR and flag checks.
+func TestShouldUseColor_GivenNoColor_ThenReturnsFalse(t *testing.T) {
+	if ShouldUseColor(os.Stdout, true) {
+		t.Error("ShouldUseColor(noColor=true) = true, want false")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if ShouldUseColor(os.Stdout, false) {
+		t.Error("ShouldUseColor() with NO_COLOR set = true, want false")
+	}
+}
+
+// TestShouldUseColor_GivenNonTerminal_ThenReturnsFalse tests TTY detection.
+func TestShouldUseColor_GivenNonTerminal_ThenReturnsFalse(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	defer f.Close()
+
+	if ShouldUseColor(f, false) {
+		t.Error("ShouldUseColor(file) = true, want false")
+	}
+}
@@ -54,6 +54,17 @@ func FormatError(writer io.Writer, err error) int {
 		return ExitConfigError
 	}
 
+	var unknownField *config.UnknownFieldError
+	if errors.As(err, &unknownField) {
+		PrintError(writer, "Invalid configuration: unknown key %q on line %d", unknownField.Field, unknownField.Line)
+		fmt.Fprintln(writer)
+		if unknownField.Suggestion != "" {
+			fmt.Fprintf(writer, "Did you mean %q?\n", unknownField.Suggestion)
+		}
+		fmt.Fprintf(writer, "Fix or remove the key in %s\n", unknownField.Path)
+		return ExitConfigError
+	}
+
 	var sessionNotFound *session.NotFoundError
 	if errors.As(err, &sessionNotFound) {
 		PrintError(writer, "session '%s' not found", sessionNotFound.ID)
@@ -93,6 +93,31 @@ func TestFormatError_GivenValidationError_ThenReturnsConfigError(t *testing.T) {
 	}
 }
 
+// TestFormatError_GivenUnknownFieldError_ThenSuggestsKey tests unknown config keys.
+func TestFormatError_GivenUnknownFieldError_ThenSuggestsKey(t *testing.T) {
+	var buf bytes.Buffer
+	err := &config.UnknownFieldError{
+		Path:       "/home/user/.sandctl/config",
+		Line:       1,
+		Field:      "defalt_provider",
+		Suggestion: "default_provider",
+	}
+
+	code := FormatError(&buf, err)
+
+	if code != ExitConfigError {
+		t.Errorf("exit code = %d, want %d", code, ExitConfigError)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "defalt_provider") {
+		t.Error("output should mention the unknown key")
+	}
+	if !strings.Contains(output, `Did you mean "default_provider"?`) {
+		t.Errorf("output should suggest the known key, got: %q", output)
+	}
+}
+
 // TestFormatError_GivenNotFoundError_ThenReturnsSessionNotFound tests session not found.
 func TestFormatError_GivenNotFoundError_ThenReturnsSessionNotFound(t *testing.T) {
 	var buf bytes.Buffer
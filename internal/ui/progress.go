@@ -2,9 +2,11 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -28,10 +30,13 @@ func NewSpinner(writer io.Writer) *Spinner {
 	}
 }
 
-// Start begins the spinner with the given message.
+// Start begins the spinner with the given message. The spinner is not
+// animated when color is disabled.
 func (s *Spinner) Start(message string) {
 	s.spinner.Suffix = " " + message
-	s.spinner.Start()
+	if colorEnabled {
+		s.spinner.Start()
+	}
 }
 
 // Update changes the spinner message.
@@ -42,13 +47,13 @@ func (s *Spinner) Update(message string) {
 // Success stops the spinner and shows a success message.
 func (s *Spinner) Success(message string) {
 	s.spinner.Stop()
-	fmt.Fprintf(s.writer, "✓ %s\n", message)
+	fmt.Fprintf(s.writer, "%s %s\n", SuccessMark(), message)
 }
 
 // Fail stops the spinner and shows a failure message.
 func (s *Spinner) Fail(message string) {
 	s.spinner.Stop()
-	fmt.Fprintf(s.writer, "✗ %s\n", message)
+	fmt.Fprintf(s.writer, "%s %s\n", FailMark(), message)
 }
 
 // Stop stops the spinner without a message.
@@ -60,6 +65,36 @@ func (s *Spinner) Stop() {
 type ProgressStep struct {
 	Message string
 	Action  func() error
+
+	// ActionWithStatus is run instead of Action when set. It can call
+	// setStatus to show the step's current status next to its message.
+	ActionWithStatus func(setStatus func(status string)) error
+}
+
+// run runs the step's action, showing status updates on spin.
+func (s ProgressStep) run(spin *Spinner) error {
+	if s.ActionWithStatus == nil {
+		return s.Action()
+	}
+	return s.ActionWithStatus(func(status string) {
+		spin.Update(fmt.Sprintf("%s (%s)...", s.Message, status))
+	})
+}
+
+// skipError is returned by a step's action that found nothing to do.
+type skipError struct {
+	message string
+}
+
+func (e *skipError) Error() string {
+	return e.message
+}
+
+// SkipStep returns an error that, returned from a step's action, ends the
+// step successfully with message shown in place of the step's own, such as
+// "OpenCode already configured".
+func SkipStep(message string) error {
+	return &skipError{message: message}
 }
 
 // RunSteps executes a series of steps with progress indication.
@@ -68,7 +103,12 @@ func RunSteps(writer io.Writer, steps []ProgressStep) error {
 
 	for _, step := range steps {
 		spin.Start(step.Message + "...")
-		if err := step.Action(); err != nil {
+		if err := step.run(spin); err != nil {
+			var skip *skipError
+			if errors.As(err, &skip) {
+				spin.Success(skip.message)
+				continue
+			}
 			spin.Fail(step.Message)
 			return err
 		}
@@ -78,19 +118,96 @@ func RunSteps(writer io.Writer, steps []ProgressStep) error {
 	return nil
 }
 
+// progressBarWidth is the number of cells in a progress bar.
+const progressBarWidth = 30
+
+// progressRedrawInterval limits how often a progress bar is redrawn.
+const progressRedrawInterval = 100 * time.Millisecond
+
+// ProgressBar shows how many of a known total of bytes have been processed.
+// It implements io.Writer so it can be handed to a transfer as its progress
+// writer; only the number of bytes written matters.
+type ProgressBar struct {
+	writer   io.Writer
+	label    string
+	total    int64
+	done     int64
+	lastDraw time.Time
+}
+
+// NewProgressBar creates a progress bar for total bytes.
+func NewProgressBar(writer io.Writer, label string, total int64) *ProgressBar {
+	if writer == nil {
+		writer = os.Stdout
+	}
+	return &ProgressBar{
+		writer: writer,
+		label:  label,
+		total:  total,
+	}
+}
+
+// Write records len(p) more bytes as done and redraws the bar.
+func (b *ProgressBar) Write(p []byte) (int, error) {
+	b.done += int64(len(p))
+	if time.Since(b.lastDraw) >= progressRedrawInterval || b.done >= b.total {
+		b.draw()
+	}
+	return len(p), nil
+}
+
+// Finish draws the bar a final time and ends its line.
+func (b *ProgressBar) Finish() {
+	b.draw()
+	fmt.Fprintln(b.writer)
+}
+
+func (b *ProgressBar) draw() {
+	b.lastDraw = time.Now()
+
+	fraction := 1.0
+	if b.total > 0 {
+		fraction = min(float64(b.done)/float64(b.total), 1)
+	}
+	filled := int(fraction * progressBarWidth)
+
+	fmt.Fprintf(b.writer, "\r%s [%s%s] %3.0f%% %s/%s",
+		b.label,
+		strings.Repeat("=", filled),
+		strings.Repeat(" ", progressBarWidth-filled),
+		fraction*100,
+		FormatBytes(b.done),
+		FormatBytes(b.total),
+	)
+}
+
+// FormatBytes formats a byte count with a binary unit, such as "1.5 MiB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // PrintSuccess prints a success message.
 func PrintSuccess(writer io.Writer, format string, args ...interface{}) {
-	fmt.Fprintf(writer, "✓ "+format+"\n", args...)
+	fmt.Fprintf(writer, SuccessMark()+" "+format+"\n", args...)
 }
 
 // PrintError prints an error message.
 func PrintError(writer io.Writer, format string, args ...interface{}) {
-	fmt.Fprintf(writer, "Error: "+format+"\n", args...)
+	fmt.Fprintf(writer, colorize(ansiRed, "Error:")+" "+format+"\n", args...)
 }
 
 // PrintWarning prints a warning message.
 func PrintWarning(writer io.Writer, format string, args ...interface{}) {
-	fmt.Fprintf(writer, "Warning: "+format+"\n", args...)
+	fmt.Fprintf(writer, colorize(ansiYellow, "Warning:")+" "+format+"\n", args...)
 }
 
 // PrintInfo prints an info message.
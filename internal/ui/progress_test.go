@@ -321,3 +321,89 @@ func TestProgressStep_GivenValues_ThenHasExpectedFields(t *testing.T) {
 		t.Error("Action should have been called")
 	}
 }
+
+// TestProgressBar_GivenCompleteWrites_ThenShowsFullBar tests progress rendering.
+func TestProgressBar_GivenCompleteWrites_ThenShowsFullBar(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf, "Uploading", 2048)
+
+	n, err := bar.Write(make([]byte, 2048))
+	if err != nil || n != 2048 {
+		t.Fatalf("Write() = %d, %v; want 2048, nil", n, err)
+	}
+	bar.Finish()
+
+	output := buf.String()
+	if !strings.Contains(output, "Uploading ["+strings.Repeat("=", progressBarWidth)+"] 100%") {
+		t.Errorf("output should show a full bar, got: %q", output)
+	}
+	if !strings.Contains(output, "2.0 KiB/2.0 KiB") {
+		t.Errorf("output should show byte counts, got: %q", output)
+	}
+	if !strings.HasSuffix(output, "\n") {
+		t.Error("Finish should end the line")
+	}
+}
+
+// TestFormatBytes_GivenSizes_ThenUsesBinaryUnits tests byte formatting.
+func TestFormatBytes_GivenSizes_ThenUsesBinaryUnits(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestProgressStep_GivenActionWithStatus_ThenUpdatesSpinner tests status updates.
+func TestProgressStep_GivenActionWithStatus_ThenUpdatesSpinner(t *testing.T) {
+	var buf bytes.Buffer
+	spin := NewSpinner(&buf)
+	step := ProgressStep{
+		Message: "Waiting",
+		ActionWithStatus: func(setStatus func(string)) error {
+			setStatus("booting")
+			return nil
+		},
+	}
+
+	if err := step.run(spin); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if got, want := spin.spinner.Suffix, " Waiting (booting)..."; got != want {
+		t.Errorf("spinner message = %q, want %q", got, want)
+	}
+}
+
+// TestRunSteps_GivenSkippedStep_ThenReportsSkipMessage tests SkipStep.
+func TestRunSteps_GivenSkippedStep_ThenReportsSkipMessage(t *testing.T) {
+	var buf bytes.Buffer
+	ran := false
+
+	steps := []ProgressStep{
+		{Message: "Setting up tool", Action: func() error { return SkipStep("Tool already configured") }},
+		{Message: "Next step", Action: func() error { ran = true; return nil }},
+	}
+
+	if err := RunSteps(&buf, steps); err != nil {
+		t.Fatalf("RunSteps() error = %v", err)
+	}
+	if !ran {
+		t.Error("expected the step after a skipped one to run")
+	}
+	if output := buf.String(); !strings.Contains(output, "Tool already configured") || strings.Contains(output, "Setting up tool\n") {
+		t.Errorf("output = %q, want the skip message in place of the step's", output)
+	}
+}
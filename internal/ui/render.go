@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by Render.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// ValidateFormat returns an error if format is not a supported output format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format: %s (valid: table, json, yaml)", format)
+	}
+}
+
+// Render writes v to w in the given format. JSON and YAML are marshaled from
+// v, with YAML using the same field names as JSON; table output is delegated
+// to the command's own formatter.
+func Render(w io.Writer, format string, v any, table func(io.Writer) error) error {
+	switch format {
+	case FormatTable:
+		return table(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatYAML:
+		return renderYAML(w, v)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// renderYAML writes v as YAML. v is converted through JSON so that json tags
+// and field order carry over.
+func renderYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow and quoting styles that parsing JSON leaves on
+// nodes, so they are written as ordinary block YAML.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
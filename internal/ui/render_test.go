@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type renderItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Code  string `json:"code,omitempty"`
+}
+
+// TestRender_GivenFormat_ThenWritesMatchingOutput tests each output format.
+func TestRender_GivenFormat_ThenWritesMatchingOutput(t *testing.T) {
+	items := []renderItem{{Name: "alice", Count: 2, Code: "123"}}
+	table := func(w io.Writer) error {
+		_, err := io.WriteString(w, "TABLE\n")
+		return err
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatTable, "TABLE\n"},
+		{FormatJSON, "[\n  {\n    \"name\": \"alice\",\n    \"count\": 2,\n    \"code\": \"123\"\n  }\n]\n"},
+		{FormatYAML, "- name: alice\n  count: 2\n  code: \"123\"\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, tt.format, items, table); err != nil {
+			t.Fatalf("Render(%s) error = %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Render(%s) = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+// TestRender_GivenUnknownFormat_ThenReturnsError tests format validation.
+func TestRender_GivenUnknownFormat_ThenReturnsError(t *testing.T) {
+	err := Render(io.Discard, "xml", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("Render(xml) error = %v, want unknown format error", err)
+	}
+}